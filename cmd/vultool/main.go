@@ -1,16 +1,37 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"slices"
 	"strings"
+	"syscall"
+	"time"
 
+	"github.com/btcsuite/btcd/btcutil/psbt"
 	"github.com/spf13/cobra"
 
+	"github.com/rowbotony/vultool/internal/batch"
+	"github.com/rowbotony/vultool/internal/ceremony"
+	"github.com/rowbotony/vultool/internal/evm"
+	"github.com/rowbotony/vultool/internal/keysign"
+	"github.com/rowbotony/vultool/internal/message"
+	"github.com/rowbotony/vultool/internal/network"
 	"github.com/rowbotony/vultool/internal/recovery"
+	"github.com/rowbotony/vultool/internal/relay"
+	"github.com/rowbotony/vultool/internal/signer"
+	"github.com/rowbotony/vultool/internal/solana"
+	"github.com/rowbotony/vultool/internal/transport"
 	"github.com/rowbotony/vultool/internal/types"
 	"github.com/rowbotony/vultool/internal/util"
+	"github.com/rowbotony/vultool/internal/utxo"
 	"github.com/rowbotony/vultool/internal/vault"
 )
 
@@ -61,6 +82,251 @@ func showFirstRunMessage() {
 	}
 }
 
+// addTransportFlags adds the flags that run a ceremony across devices, one party each
+func addTransportFlags(cmd *cobra.Command) {
+	cmd.Flags().String("relay", "", "Relay URL; run only this device's party and reach the other devices through it")
+	cmd.Flags().String("session", "", "Relay session ID shared by every device (required with --relay)")
+	cmd.Flags().Bool("qr", false, "Run only this device's party and exchange messages as QR codes (air-gapped)")
+	cmd.Flags().String("qr-png", "", "With --qr, write each code as a PNG into this directory instead of the terminal")
+	cmd.Flags().String("encryption-key", "", "Shared 32-byte hex key that encrypts messages end to end (required with --relay or --qr)")
+}
+
+// remoteCeremony reports whether this device runs only its own party
+func remoteCeremony(cmd *cobra.Command) bool {
+	relayURL, _ := cmd.Flags().GetString("relay")
+	useQR, _ := cmd.Flags().GetBool("qr")
+	return relayURL != "" || useQR
+}
+
+// transportPeer returns this device's seat in a multi-device ceremony, or nil
+// when neither --relay nor --qr is set
+func transportPeer(cmd *cobra.Command, party string) (*ceremony.Peer, error) {
+	if !remoteCeremony(cmd) {
+		return nil, nil
+	}
+	relayURL, _ := cmd.Flags().GetString("relay")
+	sessionID, _ := cmd.Flags().GetString("session")
+	useQR, _ := cmd.Flags().GetBool("qr")
+	pngDir, _ := cmd.Flags().GetString("qr-png")
+	encryptionKey, _ := cmd.Flags().GetString("encryption-key")
+	if party == "" {
+		return nil, fmt.Errorf("--party is required with --relay or --qr")
+	}
+
+	if useQR {
+		if relayURL != "" {
+			return nil, fmt.Errorf("use either --relay or --qr")
+		}
+		qr, err := transport.NewQRTransport(encryptionKey, transport.QROptions{
+			Show:    showQRFrames(pngDir),
+			Scanned: os.Stdin,
+			Status:  os.Stderr,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &ceremony.Peer{Transport: qr, Party: party}, nil
+	}
+
+	client, err := transport.NewRelayClient(relayURL, sessionID, strings.TrimPrefix(encryptionKey, "0x"))
+	if err != nil {
+		return nil, err
+	}
+	return &ceremony.Peer{Transport: client, Party: party}, nil
+}
+
+// qrFrameInterval is how long each frame of an animated QR code is shown
+const qrFrameInterval = 500 * time.Millisecond
+
+// showQRFrames returns a display for QR transport frames: PNG files in pngDir
+// when it is set, otherwise an animated code on the terminal that loops until
+// the transport cancels ctx, once the other device has scanned every frame
+func showQRFrames(pngDir string) func(ctx context.Context, caption string, frames []string) error {
+	shown := 0
+	return func(ctx context.Context, caption string, frames []string) error {
+		shown++
+		fmt.Printf("\n📤 Message %d: %s, %d frame(s)\n", shown, caption, len(frames))
+		if pngDir == "" && len(frames) > 1 {
+			return transport.Animate(ctx, os.Stdout, frames, qrFrameInterval,
+				fmt.Sprintf("📤 Message %d: %s, Enter once every frame is scanned", shown, caption))
+		}
+		for i, frame := range frames {
+			if pngDir != "" {
+				png, err := transport.RenderPNG(frame, 512)
+				if err != nil {
+					return err
+				}
+				path := filepath.Join(pngDir, fmt.Sprintf("message%03d-frame%03dof%03d.png", shown, i+1, len(frames)))
+				// #nosec G306 - QR frames only carry encrypted message bodies
+				if err := os.WriteFile(path, png, 0o644); err != nil {
+					return fmt.Errorf("failed to write %s: %w", path, err)
+				}
+				fmt.Printf("  %s\n", path)
+				continue
+			}
+
+			code, err := transport.RenderASCII(frame)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%s  frame %d/%d\n", code, i+1, len(frames))
+		}
+		return nil
+	}
+}
+
+// addKeySourceFlags adds the flags that choose what an offline signing command signs with
+func addKeySourceFlags(cmd *cobra.Command, vaultFile, password *string) {
+	cmd.Flags().String("private-key", "", "Sign with this recovered private key (hex or WIF, as printed by 'recover') instead of share files")
+	cmd.Flags().StringVarP(vaultFile, "vault", "f", "", "Vault the recovered key belongs to (required with --private-key)")
+	cmd.Flags().StringVar(password, "password", "", "Password for encrypted vault files")
+}
+
+// keySource sets up the key an offline signing command signs with: a private
+// key from 'recover' checked against --vault, or a quorum of ≥t share files
+func keySource(cmd *cobra.Command, shareFiles []string, password string) (signer.Source, *vault.VaultInfo, error) {
+	quorum, privateKey, vaultInfo, err := keySourceInputs(cmd, shareFiles, password)
+	if err != nil {
+		return nil, nil, err
+	}
+	if quorum != nil {
+		return quorum, vaultInfo, nil
+	}
+	source, err := signer.NewPrivateKey(privateKey, vaultInfo)
+	if err != nil {
+		return nil, nil, err
+	}
+	return source, vaultInfo, nil
+}
+
+// eddsaKeySource is keySource for the vault's EdDSA key
+func eddsaKeySource(cmd *cobra.Command, shareFiles []string, password string) (signer.EdDSASource, *vault.VaultInfo, error) {
+	quorum, privateKey, vaultInfo, err := keySourceInputs(cmd, shareFiles, password)
+	if err != nil {
+		return nil, nil, err
+	}
+	if quorum != nil {
+		return quorum, vaultInfo, nil
+	}
+	source, err := signer.NewEdDSAKey(privateKey, vaultInfo)
+	if err != nil {
+		return nil, nil, err
+	}
+	return source, vaultInfo, nil
+}
+
+// keySourceInputs reads the key source flags: either a quorum of share files,
+// or the --private-key value and the --vault it must belong to
+func keySourceInputs(cmd *cobra.Command, shareFiles []string, password string) (*signer.Quorum, string, *vault.VaultInfo, error) {
+	privateKey, _ := cmd.Flags().GetString("private-key")
+	vaultPath, _ := cmd.Flags().GetString("vault")
+
+	if privateKey == "" {
+		if len(shareFiles) == 0 {
+			return nil, "", nil, fmt.Errorf("give ≥t share files or --private-key with --vault")
+		}
+		shares, vaultInfo, err := ceremony.LoadShares(shareFiles, password)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		quorum, err := signer.NewQuorum(shares)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		return quorum, "", vaultInfo, nil
+	}
+
+	if len(shareFiles) > 0 {
+		return nil, "", nil, fmt.Errorf("give either share files or --private-key, not both")
+	}
+	vaultInfo, err := recoveredKeyVault(vaultPath, password)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	return nil, privateKey, vaultInfo, nil
+}
+
+// recoveredKeyVault parses the --vault a recovered key is checked against
+func recoveredKeyVault(vaultPath, password string) (*vault.VaultInfo, error) {
+	if vaultPath == "" {
+		return nil, fmt.Errorf("--vault is required with a recovered key to check it belongs to the vault")
+	}
+	absPath, err := filepath.Abs(vaultPath)
+	if err != nil {
+		return nil, err
+	}
+	vaultInfo, err := vault.ParseVaultFileWithPassword(absPath, password)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing vault file: %w", err)
+	}
+	return vaultInfo, nil
+}
+
+// batchSigners sets up the keys of batch-sign: a quorum of share files signs
+// with both key types, recovered keys only sign the jobs of their own type
+func batchSigners(cmd *cobra.Command, shareFiles []string, password string) (batch.Signers, *vault.VaultInfo, error) {
+	eddsaKey, _ := cmd.Flags().GetString("eddsa-key")
+	privateKey, _ := cmd.Flags().GetString("private-key")
+	if eddsaKey == "" || privateKey != "" {
+		quorum, privateKey, vaultInfo, err := keySourceInputs(cmd, shareFiles, password)
+		if err != nil {
+			return batch.Signers{}, nil, err
+		}
+		if quorum != nil {
+			return batch.Signers{ECDSA: quorum, EdDSA: quorum}, vaultInfo, nil
+		}
+		ecdsaKey, err := signer.NewPrivateKey(privateKey, vaultInfo)
+		if err != nil {
+			return batch.Signers{}, nil, err
+		}
+		signers := batch.Signers{ECDSA: ecdsaKey}
+		if eddsaKey != "" {
+			if signers.EdDSA, err = signer.NewEdDSAKey(eddsaKey, vaultInfo); err != nil {
+				return batch.Signers{}, nil, err
+			}
+		}
+		return signers, vaultInfo, nil
+	}
+
+	if len(shareFiles) > 0 {
+		return batch.Signers{}, nil, fmt.Errorf("give either share files or --eddsa-key, not both")
+	}
+	vaultPath, _ := cmd.Flags().GetString("vault")
+	vaultInfo, err := recoveredKeyVault(vaultPath, password)
+	if err != nil {
+		return batch.Signers{}, nil, err
+	}
+	source, err := signer.NewEdDSAKey(eddsaKey, vaultInfo)
+	if err != nil {
+		return batch.Signers{}, nil, err
+	}
+	return batch.Signers{EdDSA: source}, vaultInfo, nil
+}
+
+// readPSBT reads a PSBT file in any encoding utxo.ParsePSBT accepts, or stdin for -
+func readPSBT(name string) (*psbt.Packet, error) {
+	var data []byte
+	var err error
+	if name == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PSBT: %w", err)
+	}
+	return utxo.ParsePSBT(data)
+}
+
+// supportedChainList lists the chains recovery.LookupChain accepts
+func supportedChainList() string {
+	var names []string
+	for _, chain := range recovery.GetSupportedChains() {
+		names = append(names, string(chain))
+	}
+	return strings.Join(names, ", ")
+}
+
 func main() {
 	// Show welcome message for first-time users
 	showFirstRunMessage()
@@ -78,6 +344,14 @@ func main() {
 		},
 	}
 
+	// --network applies to every command that encodes addresses or keys
+	rootCmd.PersistentFlags().String("network", string(network.Mainnet),
+		"Network to encode UTXO addresses, extended keys and WIF, and EVM chain IDs for: mainnet, testnet, regtest or signet")
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		name, _ := cmd.Flags().GetString("network")
+		return network.Set(name)
+	}
+
 	var (
 		vaultFile     string
 		exportFile    string
@@ -449,6 +723,9 @@ and all other supported chains directly from the vault's ECDSA and EdDSA public
 				if vaultInfo.IsEncrypted {
 					fmt.Printf("Encrypted: Yes\n")
 				}
+				if !network.IsMainnet() {
+					fmt.Printf("Network: %s\n", network.Current())
+				}
 				fmt.Println()
 
 				fmt.Println("Addresses:")
@@ -456,9 +733,15 @@ and all other supported chains directly from the vault's ECDSA and EdDSA public
 
 				for _, addr := range addresses {
 					fmt.Printf("%-15s %-6s %s\n", addr.Chain, addr.Ticker, addr.Address)
+					if addr.LegacyAddress != "" {
+						fmt.Printf("                      Legacy: %s\n", addr.LegacyAddress)
+					}
 					if addr.DerivePath != "" {
 						fmt.Printf("                      Path: %s\n", addr.DerivePath)
 					}
+					if addr.ChainID != "" {
+						fmt.Printf("                      Chain ID: %s\n", addr.ChainID)
+					}
 					fmt.Println("────────────────────────────────────────────────────────────")
 				}
 			}
@@ -483,6 +766,9 @@ and all other supported chains directly from the vault's ECDSA and EdDSA public
 		Short: "Combine ≥t shares to reconstruct private keys for WIF/hex export",
 		Long: `Combine threshold shares from multiple .vult files to reconstruct the original private key.
 Exports keys in various formats: WIF (Bitcoin), hex (Ethereum), base58 (Solana/THOR).
+Cosmos SDK chains (Cosmos Hub, Osmosis, Kujira, dYdX, Noble, Terra, MayaChain),
+TRON and XRP get the account's own secp256k1 key in hex, as Keplr,
+TronLink and XRPL wallets import it; XRP adds the 00-prefixed form xrpl.js takes.
 
 ⚠️  WARNING: This command reconstructs the actual private key material.
 Only use this for legitimate recovery purposes in a secure environment.`,
@@ -575,12 +861,26 @@ Only use this for legitimate recovery purposes in a secure environment.`,
 				for i, key := range recoveredKeys {
 					fmt.Printf("Key %d (%s):\n", i+1, key.Chain)
 					fmt.Printf("  Address:     %s\n", key.Address)
+					if key.LegacyAddress != "" {
+						fmt.Printf("  Legacy:      %s\n", key.LegacyAddress)
+					}
 					fmt.Printf("  Private Key: %s\n", key.PrivateKey)
+					if recovery.IsCosmosChain(key.Chain) && key.Chain != recovery.ChainThorChain {
+						fmt.Printf("  💡 Import the private key into Keplr or Leap as-is (hex, no 0x)\n")
+					}
+					if key.Chain == recovery.ChainTron {
+						fmt.Printf("  💡 Import the private key into TronLink as-is (hex, no 0x)\n")
+					}
+					if key.XRPLPrivateKey != "" {
+						fmt.Printf("  XRPL Key:    %s\n", key.XRPLPrivateKey)
+						fmt.Printf("  💡 XRPL wallets and xrpl.js take this secp256k1 key without a family seed\n")
+					}
 
 					// Display wallet-compatible formats for EdDSA chains
 					if key.SolanaSeedFormat != "" {
 						fmt.Printf("  Solana Seed Only (32-byte base64): %s\n", key.SolanaSeedFormat)
 						fmt.Printf("  ⚠️  Note: Most wallets need standard Ed25519 keypair, not TSS format\n")
+						fmt.Printf("  💡 Sign Solana transactions with this key using 'vultool sign-solana --private-key'\n")
 					}
 					if key.SolanaWalletFormat != "" {
 						fmt.Printf("  Solana TSS Format (64-byte base64): %s\n", key.SolanaWalletFormat)
@@ -610,7 +910,7 @@ Only use this for legitimate recovery purposes in a secure environment.`,
 	recoverCmd.Flags().Int("threshold", 0, "Minimum number of shares required for recovery (required)")
 	recoverCmd.Flags().StringVar(&password, "password", "", "Password for encrypted vault files")
 	recoverCmd.Flags().String("output", "", "Output file for recovery results (JSON format)")
-	recoverCmd.Flags().String("chain", "", "Filter results for specific blockchain (bitcoin, ethereum, solana, thorchain)")
+	recoverCmd.Flags().String("chain", "", "Filter results for specific blockchain (bitcoin, bitcoin-taproot, ethereum, solana, thorchain, cosmos, osmosis, ...)")
 	recoverCmd.Flags().Bool("json", false, "Output in JSON format")
 	if err := recoverCmd.MarkFlagRequired("threshold"); err != nil {
 		fmt.Printf("Error setting up recover CLI flags: %v\n", err)
@@ -620,13 +920,17 @@ Only use this for legitimate recovery purposes in a secure environment.`,
 	// derive: read-only HD key derivation
 	deriveCmd := &cobra.Command{
 		Use:   "derive",
-		Short: "Read-only HD key derivation for BTC/ETH/SOL using chain code",
+		Short: "Read-only HD address derivation at any path using the chain code",
 		Long: `Perform read-only hierarchical deterministic (HD) key derivation from a single vault share.
 Uses the vault's chain code to derive public keys and addresses without reconstructing private keys.
 
+Bitcoin paths under purpose 86 (or --chain bitcoin-taproot) give BIP86 Taproot (P2TR) addresses.
 This is safe for generating receiving addresses from any single vault share.`,
 		Example: `  # Derive Bitcoin address at standard path
-  vultool derive -f vault.vult --path "m/44'/0'/0'/0/0" --chain bitcoin
+  vultool derive -f vault.vult --path "m/84'/0'/0'/0/0" --chain bitcoin
+  
+  # Derive the third Taproot receiving address
+  vultool derive -f vault.vult --path "m/86'/0'/0'/0/2" --chain bitcoin-taproot
   
   # Derive Ethereum address with custom path
   vultool derive -f vault.vult --path "m/44'/60'/0'/0/5" --chain ethereum
@@ -659,19 +963,9 @@ This is safe for generating receiving addresses from any single vault share.`,
 				return
 			}
 
-			// Convert chain string to enum
-			var chain recovery.SupportedChain
-			switch strings.ToLower(chainStr) {
-			case "bitcoin", "btc":
-				chain = recovery.ChainBitcoin
-			case "ethereum", "eth":
-				chain = recovery.ChainEthereum
-			case "solana", "sol":
-				chain = recovery.ChainSolana
-			case "thorchain", "thor":
-				chain = recovery.ChainThorChain
-			default:
-				fmt.Printf("Unsupported chain: %s. Supported chains: bitcoin, ethereum, solana, thorchain\n", chainStr)
+			chain, err := recovery.LookupChain(chainStr)
+			if err != nil {
+				fmt.Printf("%v. Supported chains: %s\n", err, supportedChainList())
 				return
 			}
 
@@ -683,7 +977,6 @@ This is safe for generating receiving addresses from any single vault share.`,
 
 			fmt.Printf("🔄 Deriving %s address at path %s...\n", chain, derivePath)
 
-			// Call the derivation function (currently stubbed)
 			derivedKey, err := recovery.DeriveAddress(absPath, derivePath, chain, password)
 			if err != nil {
 				fmt.Printf("❌ Derivation failed: %v\n", err)
@@ -698,6 +991,9 @@ This is safe for generating receiving addresses from any single vault share.`,
 				fmt.Printf("✅ Derived %s address:\n\n", chain)
 				fmt.Printf("  Chain:       %s\n", derivedKey.Chain)
 				fmt.Printf("  Address:     %s\n", derivedKey.Address)
+				if derivedKey.LegacyAddress != "" {
+					fmt.Printf("  Legacy:      %s\n", derivedKey.LegacyAddress)
+				}
 				fmt.Printf("  Derive Path: %s\n", derivedKey.DerivePath)
 				if derivedKey.PrivateKey != "" {
 					fmt.Printf("  Private Key: %s\n", derivedKey.PrivateKey)
@@ -708,7 +1004,7 @@ This is safe for generating receiving addresses from any single vault share.`,
 	deriveCmd.Flags().StringVarP(&vaultFile, "vault", "f", "", "Path to the .vult vault file (required)")
 	deriveCmd.Flags().StringVar(&password, "password", "", "Password for encrypted vault files")
 	deriveCmd.Flags().String("path", "", "HD derivation path (e.g., m/44'/0'/0'/0/0) (required)")
-	deriveCmd.Flags().String("chain", "", "Target blockchain: bitcoin, ethereum, solana, thorchain (required)")
+	deriveCmd.Flags().String("chain", "", "Target blockchain, e.g. bitcoin, bitcoin-taproot, ethereum, solana, thorchain (required)")
 	deriveCmd.Flags().Bool("json", false, "Output in JSON format")
 	if err := deriveCmd.MarkFlagRequired("vault"); err != nil {
 		fmt.Printf("Error setting up derive CLI flags: %v\n", err)
@@ -723,6 +1019,195 @@ This is safe for generating receiving addresses from any single vault share.`,
 		os.Exit(1)
 	}
 
+	// export-xpub: watch-only account-level extended public keys
+	exportXpubCmd := &cobra.Command{
+		Use:   "export-xpub",
+		Short: "Export account-level xpub/ypub/zpub keys for watch-only wallets",
+		Long: `Export the vault's account-level extended public keys for Bitcoin, Litecoin and
+Dogecoin, one per address type, with SLIP-132 version bytes (xpub/ypub/zpub,
+Ltub/Mtub/zpub, dgub). A watch-only wallet that imports one sees every receive
+and change address of the account, not just index 0.
+
+Vultisig derives every index non-hardened from the vault's root key, so the
+key labelled m/84'/0'/0' is really derived along m/84/0/0. The exact path is
+shown next to the label, with the master and parent fingerprints; wallets that
+ask for the key origin need the exact path. The first receive address is shown
+to check the import against.`,
+		Example: `  # All account keys of account 0
+  vultool export-xpub -f vault.vult
+
+  # Bitcoin only, account 1, as JSON
+  vultool export-xpub -f vault.vult --chain btc --account 1 --json`,
+		Run: func(cmd *cobra.Command, args []string) {
+			chainNames, _ := cmd.Flags().GetStringSlice("chain")
+			account, _ := cmd.Flags().GetUint32("account")
+			useJSON, _ := cmd.Flags().GetBool("json")
+
+			chains := utxo.Chains()
+			if len(chainNames) > 0 {
+				chains = nil
+				for _, name := range chainNames {
+					chain, err := utxo.LookupChain(name)
+					if err != nil {
+						fmt.Printf("Error: %v\n", err)
+						return
+					}
+					chains = append(chains, chain)
+				}
+			}
+
+			absPath, err := filepath.Abs(vaultFile)
+			if err != nil {
+				fmt.Printf("Error getting absolute path: %v\n", err)
+				return
+			}
+			vaultInfo, err := vault.ParseVaultFileWithPassword(absPath, password)
+			if err != nil {
+				fmt.Printf("Error parsing vault file: %v\n", err)
+				return
+			}
+
+			var keys []utxo.AccountKey
+			for _, chain := range chains {
+				chainKeys, err := utxo.AccountKeys(vaultInfo, chain, account)
+				if err != nil {
+					fmt.Printf("Error deriving %s account keys: %v\n", chain.Name, err)
+					return
+				}
+				keys = append(keys, chainKeys...)
+			}
+
+			if useJSON {
+				if err := util.OutputResult(keys, "json", os.Stdout); err != nil {
+					fmt.Printf("Error outputting JSON: %v\n", err)
+				}
+				return
+			}
+			fmt.Printf("Vault: %s (master fingerprint %s)\n", vaultInfo.Name, keys[0].MasterFingerprint)
+			for _, key := range keys {
+				fmt.Printf("\n%s %s (%s)\n", key.Chain, key.ScriptType, key.Prefix)
+				fmt.Printf("  Path:               %s (exact: %s)\n", key.StandardPath, key.Path)
+				fmt.Printf("  Parent fingerprint: %s\n", key.ParentFingerprint)
+				fmt.Printf("  Fingerprint:        %s\n", key.Fingerprint)
+				fmt.Printf("  First address:      %s\n", key.FirstAddress)
+				fmt.Printf("  %s\n", key.ExtendedKey)
+			}
+		},
+	}
+	exportXpubCmd.Flags().StringVarP(&vaultFile, "vault", "f", "", "Path to the .vult vault file (required)")
+	exportXpubCmd.Flags().StringVar(&password, "password", "", "Password for encrypted vault files")
+	exportXpubCmd.Flags().StringSlice("chain", []string{}, "Chains to export: btc, ltc, doge (default: all)")
+	exportXpubCmd.Flags().Uint32("account", 0, "Account number")
+	exportXpubCmd.Flags().Bool("json", false, "Output in JSON format")
+	if err := exportXpubCmd.MarkFlagRequired("vault"); err != nil {
+		fmt.Printf("Error setting up export-xpub CLI flags: %v\n", err)
+		os.Exit(1)
+	}
+
+	// export-descriptors: output descriptors for watch-only wallets
+	exportDescriptorsCmd := &cobra.Command{
+		Use:   "export-descriptors",
+		Short: "Export output descriptors (wpkh, sh(wpkh), pkh, tr) for Bitcoin Core and Sparrow",
+		Long: `Export the vault's account-level output descriptors (BIP380-386) with checksums
+for Bitcoin and Litecoin: wpkh, sh(wpkh), pkh and tr, each with a receive (/0/*)
+and a change (/1/*) branch, to monitor the vault from your own node.
+
+The key origin is the path Vultisig really derives along, every index
+non-hardened from the vault root (e.g. [fingerprint/84/0/0]), not the
+hardened BIP44 label. tr descriptors are BIP86 key-path-only Taproot at
+m/86/coin/account. The first address of each branch is shown to check the
+import against.
+
+--import prints the JSON array Bitcoin Core's (or Litecoin Core's)
+importdescriptors takes for one --chain.`,
+		Example: `  vultool export-descriptors -f vault.vult --chain btc
+
+  # Watch the vault from Bitcoin Core
+  bitcoin-cli createwallet vault true true "" false true
+  bitcoin-cli -rpcwallet=vault importdescriptors "$(vultool export-descriptors -f vault.vult --chain btc --import)"`,
+		Run: func(cmd *cobra.Command, args []string) {
+			chainNames, _ := cmd.Flags().GetStringSlice("chain")
+			account, _ := cmd.Flags().GetUint32("account")
+			useJSON, _ := cmd.Flags().GetBool("json")
+			importFormat, _ := cmd.Flags().GetBool("import")
+			if importFormat && len(chainNames) != 1 {
+				fmt.Println("--import needs exactly one --chain: each node imports its own chain's descriptors.")
+				return
+			}
+
+			absPath, err := filepath.Abs(vaultFile)
+			if err != nil {
+				fmt.Printf("Error getting absolute path: %v\n", err)
+				return
+			}
+			vaultInfo, err := vault.ParseVaultFileWithPassword(absPath, password)
+			if err != nil {
+				fmt.Printf("Error parsing vault file: %v\n", err)
+				return
+			}
+
+			var descriptors []utxo.Descriptor
+			for _, name := range chainNames {
+				chain, err := utxo.LookupChain(name)
+				if err != nil {
+					fmt.Printf("Error: %v\n", err)
+					return
+				}
+				chainDescriptors, err := utxo.AccountDescriptors(vaultInfo, chain, account)
+				if err != nil {
+					fmt.Printf("Error: %v\n", err)
+					return
+				}
+				descriptors = append(descriptors, chainDescriptors...)
+			}
+
+			if importFormat {
+				type importRequest struct {
+					Desc      string `json:"desc"`
+					Active    bool   `json:"active"`
+					Internal  bool   `json:"internal"`
+					Range     []int  `json:"range"`
+					Timestamp string `json:"timestamp"`
+				}
+				var requests []importRequest
+				for _, d := range descriptors {
+					// "now" skips the rescan; rescan with rescanblockchain when the vault has history
+					requests = append(requests, importRequest{Desc: d.Descriptor, Active: true, Internal: d.Internal,
+						Range: []int{0, 999}, Timestamp: "now"})
+				}
+				if err := util.OutputResult(requests, "json", os.Stdout); err != nil {
+					fmt.Printf("Error outputting JSON: %v\n", err)
+				}
+				return
+			}
+			if useJSON {
+				if err := util.OutputResult(descriptors, "json", os.Stdout); err != nil {
+					fmt.Printf("Error outputting JSON: %v\n", err)
+				}
+				return
+			}
+			fmt.Printf("Vault: %s\n", vaultInfo.Name)
+			for _, d := range descriptors {
+				branch := "receive"
+				if d.Internal {
+					branch = "change"
+				}
+				fmt.Printf("\n%s %s %s (first address %s)\n", d.Chain, d.ScriptType, branch, d.FirstAddress)
+				fmt.Printf("  %s\n", d.Descriptor)
+			}
+		},
+	}
+	exportDescriptorsCmd.Flags().StringVarP(&vaultFile, "vault", "f", "", "Path to the .vult vault file (required)")
+	exportDescriptorsCmd.Flags().StringVar(&password, "password", "", "Password for encrypted vault files")
+	exportDescriptorsCmd.Flags().StringSlice("chain", []string{"btc", "ltc"}, "Chains to export: btc, ltc")
+	exportDescriptorsCmd.Flags().Uint32("account", 0, "Account number")
+	exportDescriptorsCmd.Flags().Bool("json", false, "Output in JSON format")
+	exportDescriptorsCmd.Flags().Bool("import", false, "Output the request array for Bitcoin Core's importdescriptors")
+	if err := exportDescriptorsCmd.MarkFlagRequired("vault"); err != nil {
+		fmt.Printf("Error setting up export-descriptors CLI flags: %v\n", err)
+		os.Exit(1)
+	}
+
 	// list-addresses-paths: enumerate addresses along common derivation paths
 	listAddressesPathsCmd := &cobra.Command{
 		Use:   "list-paths",
@@ -730,7 +1215,7 @@ This is safe for generating receiving addresses from any single vault share.`,
 		Long: `List common HD derivation paths and addresses for supported blockchains.
 Useful for discovering which addresses are associated with a vault.
 
-Shows predefined common paths covering different address types (Legacy, SegWit, etc.) for Bitcoin
+Shows predefined common paths covering different address types (Legacy, SegWit, Taproot, etc.) for Bitcoin
 and sequential addresses for Ethereum and other chains. The --count flag is not yet implemented.`,
 		Example: `  # List all common derivation paths for all chains
   vultool list-paths -f vault.vult
@@ -775,6 +1260,8 @@ and sequential addresses for Ethereum and other chains. The --count flag is not
 					switch strings.ToLower(chainFilter) {
 					case "bitcoin", "btc":
 						targetChain = types.ChainBitcoin
+					case "bitcoin-taproot", "taproot", "p2tr", "btc-taproot":
+						targetChain = types.ChainBitcoinTaproot
 					case "bitcoincash", "bch":
 						targetChain = types.ChainBitcoinCash
 					case "litecoin", "ltc":
@@ -807,6 +1294,24 @@ and sequential addresses for Ethereum and other chains. The --count flag is not
 						targetChain = types.ChainZksync
 					case "thorchain", "thor", "rune":
 						targetChain = types.ChainThorChain
+					case "mayachain", "maya", "cacao":
+						targetChain = types.ChainMayaChain
+					case "cosmos", "cosmoshub", "atom":
+						targetChain = types.ChainCosmos
+					case "osmosis", "osmo":
+						targetChain = types.ChainOsmosis
+					case "kujira", "kuji":
+						targetChain = types.ChainKujira
+					case "dydx":
+						targetChain = types.ChainDydx
+					case "noble":
+						targetChain = types.ChainNoble
+					case "terra", "luna":
+						targetChain = types.ChainTerra
+					case "tron", "trx":
+						targetChain = types.ChainTron
+					case "xrp", "ripple":
+						targetChain = types.ChainXRP
 					case "solana", "sol":
 						targetChain = types.ChainSolana
 					case "sui":
@@ -823,7 +1328,7 @@ and sequential addresses for Ethereum and other chains. The --count flag is not
 				} else {
 					// Generate for all supported chains
 					supportedChains := []types.SupportedChain{
-						types.ChainBitcoin, types.ChainEthereum, types.ChainSolana, types.ChainThorChain,
+						types.ChainBitcoin, types.ChainBitcoinTaproot, types.ChainEthereum, types.ChainSolana, types.ChainThorChain,
 					}
 					for _, chain := range supportedChains {
 						paths := types.GenerateSequentialPaths(chain, count)
@@ -843,6 +1348,8 @@ and sequential addresses for Ethereum and other chains. The --count flag is not
 				switch strings.ToLower(chainFilter) {
 				case "bitcoin", "btc":
 					targetChain = types.ChainBitcoin
+				case "bitcoin-taproot", "taproot", "p2tr", "btc-taproot":
+					targetChain = types.ChainBitcoinTaproot
 				case "bitcoincash", "bch":
 					targetChain = types.ChainBitcoinCash
 				case "litecoin", "ltc":
@@ -875,13 +1382,31 @@ and sequential addresses for Ethereum and other chains. The --count flag is not
 					targetChain = types.ChainZksync
 				case "thorchain", "thor", "rune":
 					targetChain = types.ChainThorChain
+				case "mayachain", "maya", "cacao":
+					targetChain = types.ChainMayaChain
+				case "cosmos", "cosmoshub", "atom":
+					targetChain = types.ChainCosmos
+				case "osmosis", "osmo":
+					targetChain = types.ChainOsmosis
+				case "kujira", "kuji":
+					targetChain = types.ChainKujira
+				case "dydx":
+					targetChain = types.ChainDydx
+				case "noble":
+					targetChain = types.ChainNoble
+				case "terra", "luna":
+					targetChain = types.ChainTerra
+				case "tron", "trx":
+					targetChain = types.ChainTron
+				case "xrp", "ripple":
+					targetChain = types.ChainXRP
 				case "solana", "sol":
 					targetChain = types.ChainSolana
 				case "sui":
 					targetChain = types.ChainSUI
 				default:
 					fmt.Printf("Unsupported chain: %s\n", chainFilter)
-					fmt.Printf("Supported chains: bitcoin, bitcoincash, litecoin, dogecoin, dash, zcash, ethereum, bsc, avalanche, polygon, cronoschain, arbitrum, optimism, base, blast, zksync, thorchain, solana, sui\n")
+					fmt.Printf("Supported chains: bitcoin, bitcoin-taproot, bitcoincash, litecoin, dogecoin, dash, zcash, ethereum, bsc, avalanche, polygon, cronoschain, arbitrum, optimism, base, blast, zksync, thorchain, mayachain, cosmos, osmosis, kujira, dydx, noble, terra, tron, xrp, solana, sui\n")
 					return
 				}
 
@@ -926,7 +1451,11 @@ and sequential addresses for Ethereum and other chains. The --count flag is not
 			}
 
 			// Derive addresses for all the specified paths
-			pathAddresses := vault.DerivePathAddresses(vaultInfo, allPaths, count)
+			pathAddresses, err := vault.DerivePathAddresses(vaultInfo, allPaths)
+			if err != nil {
+				fmt.Printf("Error deriving addresses: %v\n", err)
+				return
+			}
 
 			if len(pathAddresses) == 0 {
 				fmt.Println("No addresses could be derived from vault for the specified paths")
@@ -951,6 +1480,9 @@ and sequential addresses for Ethereum and other chains. The --count flag is not
 					}
 
 					fmt.Printf("   %-20s %s\n", addr.DerivePath, addr.Address)
+					if addr.LegacyAddress != "" {
+						fmt.Printf("   %-20s %s (legacy)\n", "", addr.LegacyAddress)
+					}
 				}
 			}
 		},
@@ -967,6 +1499,1729 @@ and sequential addresses for Ethereum and other chains. The --count flag is not
 		os.Exit(1)
 	}
 
+	// import-seed: dealer-split an existing seed or private key into vault shares
+	importSeedCmd := &cobra.Command{
+		Use:   "import-seed",
+		Short: "[EXPERIMENTAL] Split a BIP39 seed or raw private key into GG20 vault shares",
+		Long: `Split a BIP39 mnemonic or raw secp256k1/ed25519 private keys into t-of-n GG20
+vault shares using a trusted dealer, writing one .vult file per party.
+
+For mnemonics the ECDSA root is the BIP32 master key and chain code, so vault
+addresses match a wallet that derives non-hardened paths from the master key.
+The EdDSA key is taken from the SLIP-0010 path (default m/44'/501'/0'/0'), which
+matches the Solana address shown by Phantom and Solflare.
+
+⚠️  WARNING: The full private key exists on this machine during the import and
+the original seed remains valid. Anyone holding the seed can still spend funds.
+Sweep funds into a freshly generated vault as soon as possible.`,
+		Example: `  # Split a mnemonic into a 2-of-3 vault
+  vultool import-seed --experimental --mnemonic "word1 ... word12" \
+    --parties alice,bob,carol --name Treasury --output-dir ./shares
+
+  # Split a raw secp256k1 key (WIF or hex), generating a fresh EdDSA key
+  vultool import-seed --experimental --ecdsa-key <hex> --parties a,b --name Legacy`,
+		Run: func(cmd *cobra.Command, args []string) {
+			experimental, _ := cmd.Flags().GetBool("experimental")
+			mnemonic, _ := cmd.Flags().GetString("mnemonic")
+			passphrase, _ := cmd.Flags().GetString("passphrase")
+			ecdsaKey, _ := cmd.Flags().GetString("ecdsa-key")
+			eddsaKey, _ := cmd.Flags().GetString("eddsa-key")
+			eddsaPath, _ := cmd.Flags().GetString("eddsa-path")
+			chainCode, _ := cmd.Flags().GetString("chain-code")
+			parties, _ := cmd.Flags().GetStringSlice("parties")
+			threshold, _ := cmd.Flags().GetInt("threshold")
+			name, _ := cmd.Flags().GetString("name")
+			outputDir, _ := cmd.Flags().GetString("output-dir")
+			force, _ := cmd.Flags().GetBool("force")
+			useJSON, _ := cmd.Flags().GetBool("json")
+
+			if !experimental {
+				fmt.Println("import-seed is experimental and must be enabled with --experimental.")
+				fmt.Println("Dealer-based splitting exposes the full private key on this machine; read 'vultool import-seed --help' first.")
+				return
+			}
+
+			if mnemonic != "" && (ecdsaKey != "" || eddsaKey != "") {
+				fmt.Println("Use either --mnemonic or --ecdsa-key/--eddsa-key, not both.")
+				return
+			}
+			if mnemonic == "" && ecdsaKey == "" && eddsaKey == "" {
+				fmt.Println("One of --mnemonic, --ecdsa-key or --eddsa-key is required.")
+				return
+			}
+
+			signersNeeded, err := ceremony.SignersRequired(len(parties))
+			if err != nil {
+				fmt.Printf("Invalid parties: %v\n", err)
+				return
+			}
+			if threshold != 0 && threshold != signersNeeded {
+				fmt.Printf("Invalid threshold: Vultisig vaults with %d parties require %d signers (got %d)\n",
+					len(parties), signersNeeded, threshold)
+				return
+			}
+
+			var material *ceremony.KeyMaterial
+			if mnemonic != "" {
+				material, err = ceremony.KeyMaterialFromMnemonic(mnemonic, passphrase, eddsaPath)
+			} else {
+				material, err = ceremony.KeyMaterialFromRawKeys(ecdsaKey, eddsaKey, chainCode)
+			}
+			if err != nil {
+				fmt.Printf("❌ Invalid key material: %v\n", err)
+				return
+			}
+
+			if !useJSON {
+				fmt.Println("⚠️  EXPERIMENTAL: dealer-based import. The full private key is in memory on this machine.")
+				if mnemonic != "" {
+					fmt.Println("⚠️  ECDSA addresses match non-hardened derivation from the BIP32 master key;")
+					fmt.Println("   wallets using hardened account paths (m/44'/60'/0'/...) will show different addresses.")
+				}
+				if mnemonic == "" && ecdsaKey == "" {
+					fmt.Println("⚠️  No --ecdsa-key given: a fresh random ECDSA key was generated.")
+				}
+				if mnemonic == "" && eddsaKey == "" {
+					fmt.Println("⚠️  No --eddsa-key given: a fresh random EdDSA key was generated.")
+				}
+				fmt.Printf("🔄 Splitting keys into %d shares (%d-of-%d), generating pre-parameters (this can take minutes)...\n",
+					len(parties), signersNeeded, len(parties))
+			}
+
+			result, err := ceremony.ImportKeyMaterial(material, ceremony.ImportOptions{
+				Name:      name,
+				Parties:   parties,
+				OutputDir: outputDir,
+				Password:  password,
+				Force:     force,
+			})
+			if err != nil {
+				fmt.Printf("❌ Import failed: %v\n", err)
+				return
+			}
+
+			if useJSON {
+				if err := util.OutputResult(result, "json", os.Stdout); err != nil {
+					fmt.Printf("Error outputting JSON: %v\n", err)
+				}
+				return
+			}
+
+			fmt.Printf("✅ Wrote %d vault shares (%d-of-%d):\n", len(result.Files), result.SignersNeeded, len(result.Files))
+			for _, file := range result.Files {
+				fmt.Printf("  %s\n", file)
+			}
+			fmt.Println()
+			fmt.Printf("ECDSA Public Key: %s\n", result.PublicKeyECDSA)
+			fmt.Printf("EdDSA Public Key: %s\n", result.PublicKeyEDDSA)
+			fmt.Printf("Chain Code:       %s\n", result.HexChainCode)
+			fmt.Println()
+			fmt.Println("Vault addresses (verified against the source keys):")
+			for _, addr := range result.Addresses {
+				fmt.Printf("  %-12s %s\n", addr.Chain+":", addr.Address)
+			}
+			fmt.Println()
+			fmt.Println("⚠️  The original seed/key is still valid. Sweep funds into a freshly generated vault ASAP.")
+		},
+	}
+	importSeedCmd.Flags().Bool("experimental", false, "Acknowledge that seed import is experimental (required)")
+	importSeedCmd.Flags().String("mnemonic", "", "BIP39 mnemonic to import")
+	importSeedCmd.Flags().String("passphrase", "", "Optional BIP39 passphrase")
+	importSeedCmd.Flags().String("ecdsa-key", "", "Raw secp256k1 private key (hex or WIF)")
+	importSeedCmd.Flags().String("eddsa-key", "", "Raw ed25519 private key (32-byte seed or 64-byte keypair, hex)")
+	importSeedCmd.Flags().String("eddsa-path", ceremony.DefaultEdDSAPath, "SLIP-0010 path for the EdDSA key when importing a mnemonic")
+	importSeedCmd.Flags().String("chain-code", "", "Hex chain code for raw keys (default: derived from the ECDSA key)")
+	importSeedCmd.Flags().StringSlice("parties", []string{}, "Party IDs, one share per party (required)")
+	importSeedCmd.Flags().Int("threshold", 0, "Expected signers; must match the Vultisig threshold for the party count")
+	importSeedCmd.Flags().String("name", "", "Vault name (required)")
+	importSeedCmd.Flags().String("output-dir", ".", "Directory for the generated .vult files")
+	importSeedCmd.Flags().StringVar(&password, "password", "", "Password to encrypt the generated vault files")
+	importSeedCmd.Flags().Bool("force", false, "Overwrite existing vault files")
+	importSeedCmd.Flags().Bool("json", false, "Output in JSON format")
+	if err := importSeedCmd.MarkFlagRequired("parties"); err != nil {
+		fmt.Printf("Error setting up import-seed CLI flags: %v\n", err)
+		os.Exit(1)
+	}
+	if err := importSeedCmd.MarkFlagRequired("name"); err != nil {
+		fmt.Printf("Error setting up import-seed CLI flags: %v\n", err)
+		os.Exit(1)
+	}
+
+	// keygen: in-process GG20 DKG for all parties
+	keygenCmd := &cobra.Command{
+		Use:   "keygen",
+		Short: "Run a GG20 keygen for all parties locally and write one .vult per party",
+		Long: `Run a full distributed key generation (GG20, ECDSA + EdDSA) for N parties inside
+this process, using an in-memory message bus instead of the relay server.
+Each party's share is written to its own encrypted .vult file.
+
+With --relay, this process runs only the --party share and the other parties
+run the same command on their own machines, coordinating through the relay
+session. Every device must pass the same --parties, --chaincode, --session and
+--encryption-key; only the local share is written.
+
+The signing threshold follows the Vultisig rule for the party count
+(2-of-2, 2-of-3, 3-of-4, ...). ECDSA pre-parameter generation is slow and can
+take several minutes on small machines.`,
+		Example: `  # Create a 2-of-3 vault
+  vultool keygen --parties laptop,phone,backup --name Treasury --output-dir ./shares
+
+  # Use a fixed chain code (hex)
+  vultool keygen --parties a,b --name Test --chaincode <64 hex chars> --password secret
+
+  # Create a 2-of-2 vault across two machines (run on each, with its own --party)
+  vultool keygen --parties laptop,server --party laptop --name Treasury --chaincode <64 hex chars> \
+    --relay http://192.168.1.10:18080 --session treasury-1 --encryption-key <64 hex chars>`,
+		Run: func(cmd *cobra.Command, args []string) {
+			parties, _ := cmd.Flags().GetStringSlice("parties")
+			threshold, _ := cmd.Flags().GetInt("threshold")
+			name, _ := cmd.Flags().GetString("name")
+			chainCodeHex, _ := cmd.Flags().GetString("chaincode")
+			party, _ := cmd.Flags().GetString("party")
+			outputDir, _ := cmd.Flags().GetString("output-dir")
+			force, _ := cmd.Flags().GetBool("force")
+			useJSON, _ := cmd.Flags().GetBool("json")
+
+			peer, err := transportPeer(cmd, party)
+			if err != nil {
+				fmt.Printf("Invalid transport settings: %v\n", err)
+				return
+			}
+			if peer != nil && chainCodeHex == "" {
+				fmt.Println("--chaincode is required with --relay or --qr: every device must use the same one (e.g. from 'openssl rand -hex 32')")
+				return
+			}
+
+			signersNeeded, err := ceremony.SignersRequired(len(parties))
+			if err != nil {
+				fmt.Printf("Invalid parties: %v\n", err)
+				return
+			}
+			if threshold != 0 && threshold != signersNeeded {
+				fmt.Printf("Invalid threshold: Vultisig vaults with %d parties require %d signers (got %d)\n",
+					len(parties), signersNeeded, threshold)
+				return
+			}
+
+			var chainCode []byte
+			if chainCodeHex != "" {
+				chainCode, err = hex.DecodeString(strings.TrimPrefix(chainCodeHex, "0x"))
+				if err != nil || len(chainCode) != 32 {
+					fmt.Println("Invalid chain code: must be 32 bytes of hex")
+					return
+				}
+			}
+
+			if password == "" {
+				password, err = vault.PromptNewPassword()
+				if err != nil {
+					fmt.Printf("Error reading password: %v\n", err)
+					return
+				}
+			}
+
+			opts := ceremony.KeygenOptions{
+				Name:      name,
+				Parties:   parties,
+				ChainCode: chainCode,
+				OutputDir: outputDir,
+				Password:  password,
+				Force:     force,
+			}
+
+			var result *ceremony.VaultResult
+			if peer != nil {
+				if !useJSON {
+					fmt.Printf("🔄 Running %d-of-%d keygen as %s, waiting for %s...\n",
+						signersNeeded, len(parties), party, strings.Join(parties, ", "))
+				}
+				ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+				defer stop()
+				result, err = ceremony.KeygenRemote(ctx, *peer, opts)
+			} else {
+				if !useJSON {
+					fmt.Printf("🔄 Running %d-of-%d keygen for %s (generating pre-parameters, this can take minutes)...\n",
+						signersNeeded, len(parties), strings.Join(parties, ", "))
+				}
+				result, err = ceremony.Keygen(opts)
+			}
+			if err != nil {
+				fmt.Printf("❌ Keygen failed: %v\n", err)
+				return
+			}
+
+			if useJSON {
+				if err := util.OutputResult(result, "json", os.Stdout); err != nil {
+					fmt.Printf("Error outputting JSON: %v\n", err)
+				}
+				return
+			}
+
+			if peer != nil {
+				fmt.Printf("✅ Wrote the vault share of %s (%d-of-%d):\n", party, result.SignersNeeded, len(parties))
+			} else {
+				fmt.Printf("✅ Wrote %d vault shares (%d-of-%d):\n", len(result.Files), result.SignersNeeded, len(result.Files))
+			}
+			for _, file := range result.Files {
+				fmt.Printf("  %s\n", file)
+			}
+			fmt.Println()
+			fmt.Printf("ECDSA Public Key: %s\n", result.PublicKeyECDSA)
+			fmt.Printf("EdDSA Public Key: %s\n", result.PublicKeyEDDSA)
+			fmt.Printf("Chain Code:       %s\n", result.HexChainCode)
+			if peer == nil {
+				fmt.Println()
+				fmt.Println("⚠️  All shares were created on this machine. Move each share to its own device.")
+			}
+		},
+	}
+	keygenCmd.Flags().StringSlice("parties", []string{}, "Party IDs, one share per party (required)")
+	keygenCmd.Flags().Int("threshold", 0, "Expected signers; must match the Vultisig threshold for the party count")
+	keygenCmd.Flags().String("name", "", "Vault name (required)")
+	keygenCmd.Flags().String("chaincode", "", "Hex chain code (default: random)")
+	keygenCmd.Flags().String("output-dir", ".", "Directory for the generated .vult files")
+	keygenCmd.Flags().StringVar(&password, "password", "", "Password to encrypt the generated vault files (prompted if omitted)")
+	keygenCmd.Flags().Bool("force", false, "Overwrite existing vault files")
+	keygenCmd.Flags().Bool("json", false, "Output in JSON format")
+	keygenCmd.Flags().String("party", "", "Party ID this device runs (required with --relay or --qr)")
+	addTransportFlags(keygenCmd)
+	if err := keygenCmd.MarkFlagRequired("parties"); err != nil {
+		fmt.Printf("Error setting up keygen CLI flags: %v\n", err)
+		os.Exit(1)
+	}
+	if err := keygenCmd.MarkFlagRequired("name"); err != nil {
+		fmt.Printf("Error setting up keygen CLI flags: %v\n", err)
+		os.Exit(1)
+	}
+
+	// reshare: move a vault to a new committee with the same keys
+	reshareCmd := &cobra.Command{
+		Use:   "reshare [old share files...]",
+		Short: "Reshare a vault to a new party list, keeping its keys and addresses",
+		Long: `Run the GG20 resharing protocol from ≥t of the current shares to a new committee
+inside this process. Parties can be added, removed or replaced: the vault keeps
+its public keys and chain code, so every address stays the same, while every
+new party receives a freshly randomised share and the vault gets a new reshare
+prefix.
+
+Old and new shares are both verified to represent the vault key before the new
+files are written. Once the new shares are distributed, delete the old ones.
+
+With --relay, every device runs this command on its own machine with the same
+--old, --new, --session and --encryption-key. Devices of the old committee pass
+their share file; a device joining the vault passes none and names itself with
+--party. Each device in the new committee writes only its own new share.`,
+		Example: `  # Replace a lost device (carol) with a new one (dave)
+  vultool reshare alice.vult bob.vult --new alice,bob,dave --output-dir ./reshared
+
+  # Grow a 2-of-2 vault to 2-of-3
+  vultool reshare a.vult b.vult --new a,b,c --threshold 2 --password secret
+
+  # The same rotation across machines: alice and bob pass their share, dave joins
+  vultool reshare alice.vult --old alice,bob --new alice,bob,dave --relay http://192.168.1.10:18080 \
+    --session rotate-1 --encryption-key <64 hex chars>
+  vultool reshare --party dave --name Treasury --old alice,bob --new alice,bob,dave \
+    --relay http://192.168.1.10:18080 --session rotate-1 --encryption-key <64 hex chars>`,
+		Run: func(cmd *cobra.Command, args []string) {
+			remote := remoteCeremony(cmd)
+			oldParties, _ := cmd.Flags().GetStringSlice("old")
+			party, _ := cmd.Flags().GetString("party")
+			if !remote && len(args) == 0 {
+				fmt.Println("At least one vault file is required.")
+				return
+			}
+			if remote && (len(args) > 1 || len(oldParties) == 0) {
+				fmt.Println("With --relay or --qr, pass at most this device's share file and list the old committee with --old.")
+				return
+			}
+
+			newParties, _ := cmd.Flags().GetStringSlice("new")
+			threshold, _ := cmd.Flags().GetInt("threshold")
+			name, _ := cmd.Flags().GetString("name")
+			newPassword, _ := cmd.Flags().GetString("new-password")
+			outputDir, _ := cmd.Flags().GetString("output-dir")
+			force, _ := cmd.Flags().GetBool("force")
+			useJSON, _ := cmd.Flags().GetBool("json")
+
+			signersNeeded, err := ceremony.SignersRequired(len(newParties))
+			if err != nil {
+				fmt.Printf("Invalid parties: %v\n", err)
+				return
+			}
+			if threshold != 0 && threshold != signersNeeded {
+				fmt.Printf("Invalid threshold: Vultisig vaults with %d parties require %d signers (got %d)\n",
+					len(newParties), signersNeeded, threshold)
+				return
+			}
+
+			var oldShares []*ceremony.PartyShare
+			if len(args) > 0 {
+				var vaultInfo *vault.VaultInfo
+				oldShares, vaultInfo, err = ceremony.LoadShares(args, password)
+				if err != nil {
+					fmt.Printf("Error loading shares: %v\n", err)
+					return
+				}
+				if name == "" {
+					name = vaultInfo.Name
+				}
+				party = oldShares[0].PartyID
+			}
+			if name == "" {
+				fmt.Println("--name is required when joining a vault without a share file")
+				return
+			}
+
+			peer, err := transportPeer(cmd, party)
+			if err != nil {
+				fmt.Printf("Invalid transport settings: %v\n", err)
+				return
+			}
+			// A device leaving the vault writes nothing, so it needs no new password
+			leaving := peer != nil && !slices.Contains(newParties, party)
+
+			if newPassword == "" {
+				newPassword = password
+			}
+			if newPassword == "" && !leaving {
+				newPassword, err = vault.PromptNewPassword()
+				if err != nil {
+					fmt.Printf("Error reading password: %v\n", err)
+					return
+				}
+			}
+
+			opts := ceremony.ReshareOptions{
+				Name:       name,
+				NewParties: newParties,
+				OutputDir:  outputDir,
+				Password:   newPassword,
+				Force:      force,
+			}
+
+			var result *ceremony.VaultResult
+			if peer != nil {
+				if !useJSON {
+					fmt.Printf("🔄 Resharing %s to %d-of-%d as %s, waiting for the other devices...\n",
+						name, signersNeeded, len(newParties), party)
+				}
+				var share *ceremony.PartyShare
+				if len(oldShares) > 0 {
+					share = oldShares[0]
+				}
+				ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+				defer stop()
+				result, err = ceremony.ReshareRemote(ctx, *peer, share, oldParties, opts)
+			} else {
+				if !useJSON {
+					fmt.Printf("🔄 Resharing %s to %d-of-%d for %s (generating pre-parameters, this can take minutes)...\n",
+						name, signersNeeded, len(newParties), strings.Join(newParties, ", "))
+				}
+				result, err = ceremony.Reshare(oldShares, opts)
+			}
+			if err != nil {
+				fmt.Printf("❌ Reshare failed: %v\n", err)
+				return
+			}
+
+			if useJSON {
+				if err := util.OutputResult(result, "json", os.Stdout); err != nil {
+					fmt.Printf("Error outputting JSON: %v\n", err)
+				}
+				return
+			}
+
+			switch {
+			case leaving:
+				fmt.Printf("✅ %s handed over to %s and is no longer a party of the vault\n", party, strings.Join(newParties, ", "))
+			case peer != nil:
+				fmt.Printf("✅ Wrote the reshared vault share of %s (%d-of-%d):\n", party, result.SignersNeeded, len(newParties))
+			default:
+				fmt.Printf("✅ Wrote %d reshared vault shares (%d-of-%d):\n", len(result.Files), result.SignersNeeded, len(result.Files))
+			}
+			for _, file := range result.Files {
+				fmt.Printf("  %s\n", file)
+			}
+			fmt.Println()
+			fmt.Printf("ECDSA Public Key: %s (unchanged)\n", result.PublicKeyECDSA)
+			fmt.Printf("EdDSA Public Key: %s (unchanged)\n", result.PublicKeyEDDSA)
+			fmt.Printf("Chain Code:       %s (unchanged)\n", result.HexChainCode)
+			fmt.Println()
+			fmt.Println("⚠️  The old shares still control the vault. Delete them once the new shares are distributed.")
+		},
+	}
+	reshareCmd.Flags().StringSlice("new", []string{}, "New committee party IDs (required)")
+	reshareCmd.Flags().Int("threshold", 0, "Expected signers; must match the Vultisig threshold for the new party count")
+	reshareCmd.Flags().String("name", "", "Vault name for the new shares (default: current name)")
+	reshareCmd.Flags().String("output-dir", ".", "Directory for the reshared .vult files")
+	reshareCmd.Flags().StringVar(&password, "password", "", "Password for the old vault files")
+	reshareCmd.Flags().String("new-password", "", "Password to encrypt the new vault files (default: --password, prompted if both are empty)")
+	reshareCmd.Flags().Bool("force", false, "Overwrite existing vault files")
+	reshareCmd.Flags().Bool("json", false, "Output in JSON format")
+	reshareCmd.Flags().StringSlice("old", []string{}, "Old committee taking part (required with --relay or --qr)")
+	reshareCmd.Flags().String("party", "", "Party ID of a device joining without a share file (with --relay or --qr)")
+	addTransportFlags(reshareCmd)
+	if err := reshareCmd.MarkFlagRequired("new"); err != nil {
+		fmt.Printf("Error setting up reshare CLI flags: %v\n", err)
+		os.Exit(1)
+	}
+
+	// refresh: re-randomise shares for the same roster
+	refreshCmd := &cobra.Command{
+		Use:   "refresh [share files...]",
+		Short: "Proactively refresh every party's shares without changing keys or roster",
+		Long: `Run a same-roster GG20 resharing from ≥t of the current shares inside this process.
+Every party of the vault receives a freshly randomised share for the same
+signer set and threshold; public keys, chain code and addresses are unchanged.
+
+The refreshed shares lie on a new polynomial, so old shares and their backups
+can no longer be combined with them. After writing, the refreshed shares are
+verified to interpolate to the vault public keys.`,
+		Example: `  # Refresh a 2-of-3 vault using two of its shares
+  vultool refresh alice.vult bob.vult --output-dir ./refreshed
+
+  # Keep the vault encrypted with the same password
+  vultool refresh alice.vult bob.vult --password secret --json`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) == 0 {
+				fmt.Println("At least one vault file is required.")
+				return
+			}
+
+			newPassword, _ := cmd.Flags().GetString("new-password")
+			outputDir, _ := cmd.Flags().GetString("output-dir")
+			force, _ := cmd.Flags().GetBool("force")
+			useJSON, _ := cmd.Flags().GetBool("json")
+
+			shares, vaultInfo, err := ceremony.LoadShares(args, password)
+			if err != nil {
+				fmt.Printf("Error loading shares: %v\n", err)
+				return
+			}
+
+			if newPassword == "" {
+				newPassword = password
+			}
+			if newPassword == "" {
+				newPassword, err = vault.PromptNewPassword()
+				if err != nil {
+					fmt.Printf("Error reading password: %v\n", err)
+					return
+				}
+			}
+
+			if !useJSON {
+				fmt.Printf("🔄 Refreshing shares of %s (generating pre-parameters, this can take minutes)...\n", vaultInfo.Name)
+			}
+
+			result, err := ceremony.Refresh(shares, ceremony.RefreshOptions{
+				Name:      vaultInfo.Name,
+				OutputDir: outputDir,
+				Password:  newPassword,
+				Force:     force,
+			})
+			if err != nil {
+				fmt.Printf("❌ Refresh failed: %v\n", err)
+				return
+			}
+
+			if useJSON {
+				if err := util.OutputResult(result, "json", os.Stdout); err != nil {
+					fmt.Printf("Error outputting JSON: %v\n", err)
+				}
+				return
+			}
+
+			fmt.Printf("✅ Wrote %d refreshed vault shares (%d-of-%d), verified against the vault public keys:\n",
+				len(result.Files), result.SignersNeeded, len(result.Files))
+			for _, file := range result.Files {
+				fmt.Printf("  %s\n", file)
+			}
+			fmt.Println()
+			fmt.Printf("ECDSA Public Key: %s (unchanged)\n", result.PublicKeyECDSA)
+			fmt.Printf("EdDSA Public Key: %s (unchanged)\n", result.PublicKeyEDDSA)
+			fmt.Println()
+			fmt.Println("⚠️  Replace every device's share and backup with the refreshed one, then delete the old shares.")
+		},
+	}
+	refreshCmd.Flags().String("output-dir", ".", "Directory for the refreshed .vult files")
+	refreshCmd.Flags().StringVar(&password, "password", "", "Password for the current vault files")
+	refreshCmd.Flags().String("new-password", "", "Password to encrypt the refreshed files (default: --password, prompted if both are empty)")
+	refreshCmd.Flags().Bool("force", false, "Overwrite existing vault files")
+	refreshCmd.Flags().Bool("json", false, "Output in JSON format")
+
+	// sign: threshold signing between local shares
+	signCmd := &cobra.Command{
+		Use:   "sign [share files...]",
+		Short: "Threshold-sign a message hash from ≥t local shares without reconstructing the key",
+		Long: `Run the GG20 keysign protocol between the given share files inside this process.
+Each share only exchanges protocol messages with the others; unlike 'recover',
+the full private key never exists in memory.
+
+ECDSA signs a 32-byte hash with the key derived at --path (Vultisig semantics:
+non-hardened derivation from the vault root). EdDSA signs the given bytes as-is
+with the vault's root ed25519 key, as Solana and SUI transactions require.
+
+With --relay, each signer runs this command on its own machine with its own
+share file and the same --signers, --hash, --path, --session and
+--encryption-key; every signer prints the same signature.`,
+		Example: `  # Sign an Ethereum transaction hash with 2 of 3 shares
+  vultool sign share1.vult share2.vult --hash <32-byte hex> --path "m/44'/60'/0'/0/0"
+
+  # Sign a Solana message with EdDSA
+  vultool sign share1.vult share3.vult --key-type eddsa --hash <message hex> --json
+
+  # Sign from two machines, each holding one share
+  vultool sign laptop.vult --signers laptop,server --hash <32-byte hex> --path "m/44'/60'/0'/0/0" \
+    --relay http://192.168.1.10:18080 --session sign-1 --encryption-key <64 hex chars>`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) == 0 {
+				fmt.Println("At least one vault file is required.")
+				return
+			}
+
+			hashHex, _ := cmd.Flags().GetString("hash")
+			derivePath, _ := cmd.Flags().GetString("path")
+			keyTypeStr, _ := cmd.Flags().GetString("key-type")
+			signers, _ := cmd.Flags().GetStringSlice("signers")
+			useJSON, _ := cmd.Flags().GetBool("json")
+			remote := remoteCeremony(cmd)
+			if remote && (len(args) != 1 || len(signers) == 0) {
+				fmt.Println("With --relay or --qr, pass this device's share file only and list every signer with --signers.")
+				return
+			}
+
+			var keyType recovery.TssKeyType
+			switch strings.ToLower(keyTypeStr) {
+			case "ecdsa":
+				keyType = recovery.ECDSA
+				if derivePath == "" {
+					fmt.Println("--path is required for ECDSA signing (e.g. m/44'/60'/0'/0/0)")
+					return
+				}
+			case "eddsa":
+				keyType = recovery.EdDSA
+			default:
+				fmt.Printf("Unsupported key type: %s (use ecdsa or eddsa)\n", keyTypeStr)
+				return
+			}
+
+			message, err := hex.DecodeString(strings.TrimPrefix(hashHex, "0x"))
+			if err != nil {
+				fmt.Printf("Invalid --hash: %v\n", err)
+				return
+			}
+
+			shares, vaultInfo, err := ceremony.LoadShares(args, password)
+			if err != nil {
+				fmt.Printf("Error loading shares: %v\n", err)
+				return
+			}
+
+			peer, err := transportPeer(cmd, shares[0].PartyID)
+			if err != nil {
+				fmt.Printf("Invalid transport settings: %v\n", err)
+				return
+			}
+
+			var result *ceremony.SignatureResult
+			if peer != nil {
+				if !useJSON {
+					fmt.Printf("🔄 Signing as %s of vault %s (%s), waiting for %s...\n",
+						peer.Party, vaultInfo.Name, keyType, strings.Join(signers, ", "))
+				}
+				ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+				defer stop()
+				result, err = ceremony.SignRemote(ctx, *peer, shares[0], signers, keyType, message, derivePath)
+			} else {
+				if !useJSON {
+					fmt.Printf("🔄 Signing with %d shares of vault %s (%s)...\n", len(shares), vaultInfo.Name, keyType)
+				}
+				result, err = ceremony.Sign(shares, keyType, message, derivePath)
+			}
+			if err != nil {
+				fmt.Printf("❌ Signing failed: %v\n", err)
+				return
+			}
+
+			if useJSON {
+				if err := util.OutputResult(result, "json", os.Stdout); err != nil {
+					fmt.Printf("Error outputting JSON: %v\n", err)
+				}
+				return
+			}
+
+			fmt.Printf("✅ Signature verified against %s\n\n", result.PublicKey)
+			if result.DerivePath != "" {
+				fmt.Printf("Derive Path: %s\n", result.DerivePath)
+			}
+			fmt.Printf("Message:     %s\n", result.Message)
+			fmt.Printf("R:           %s\n", result.R)
+			fmt.Printf("S:           %s\n", result.S)
+			if result.V != nil {
+				fmt.Printf("V:           %d\n", *result.V)
+			}
+			fmt.Printf("Signature:   %s\n", result.Signature)
+			if result.DERSignature != "" {
+				fmt.Printf("DER:         %s\n", result.DERSignature)
+			}
+			fmt.Printf("Signers:     %s\n", strings.Join(result.Signers, ", "))
+		},
+	}
+	signCmd.Flags().String("hash", "", "Hex message to sign: a 32-byte hash for ECDSA, the raw message for EdDSA (required)")
+	signCmd.Flags().String("path", "", "HD derivation path for ECDSA (e.g., m/44'/60'/0'/0/0)")
+	signCmd.Flags().String("key-type", "ecdsa", "Signature scheme: ecdsa or eddsa")
+	signCmd.Flags().StringVar(&password, "password", "", "Password for encrypted vault files")
+	signCmd.Flags().Bool("json", false, "Output in JSON format")
+	signCmd.Flags().StringSlice("signers", []string{}, "Every signing party, this device included (required with --relay or --qr)")
+	addTransportFlags(signCmd)
+	if err := signCmd.MarkFlagRequired("hash"); err != nil {
+		fmt.Printf("Error setting up sign CLI flags: %v\n", err)
+		os.Exit(1)
+	}
+
+	// explain-keysign: decode a keysign request before co-signing it
+	explainKeysignCmd := &cobra.Command{
+		Use:   "explain-keysign [payload-file|-]",
+		Short: "Decode a Vultisig keysign QR/deeplink or KeysignMessage and explain it",
+		Long: `Decode the keysign request a Vultisig app shows as a QR code or deeplink, or a raw
+KeysignMessage protobuf (binary, hex or base64), and print what will be signed:
+chain, from/to, amount, token, memo, gas/fee, UTXOs, swap or approval details and,
+for EVM transfers, the exact message hash with its derivation path.
+
+With --vault, the from address and vault key are checked against the vault's
+own addresses so a co-signer can confirm the request spends from it.`,
+		Example: `  # Paste the text a QR scanner read from the initiating phone
+  vultool explain-keysign --data 'vultisig://vultisig.com?type=SignTransaction&jsonData=...'
+
+  # Check a saved payload against your vault
+  vultool explain-keysign keysign.bin -f my-vault.vult`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			data, _ := cmd.Flags().GetString("data")
+			useJSON, _ := cmd.Flags().GetBool("json")
+
+			var input []byte
+			var err error
+			switch {
+			case data != "":
+				input = []byte(data)
+			case len(args) == 0 || args[0] == "-":
+				input, err = io.ReadAll(os.Stdin)
+			default:
+				input, err = os.ReadFile(args[0])
+			}
+			if err != nil {
+				fmt.Printf("Error reading keysign payload: %v\n", err)
+				return
+			}
+
+			msg, err := keysign.Decode(input)
+			if err != nil {
+				fmt.Printf("Error decoding keysign payload: %v\n", err)
+				return
+			}
+			explanation, err := keysign.Explain(msg)
+			if err != nil {
+				fmt.Printf("Error explaining keysign payload: %v\n", err)
+				return
+			}
+
+			if vaultFile != "" {
+				absPath, err := filepath.Abs(vaultFile)
+				if err != nil {
+					fmt.Printf("Error getting absolute path: %v\n", err)
+					return
+				}
+				vaultInfo, err := vault.ParseVaultFileWithPassword(absPath, password)
+				if err != nil {
+					fmt.Printf("Error parsing vault file: %v\n", err)
+					return
+				}
+				explanation.Vault = keysign.CheckVault(explanation, vaultInfo)
+			}
+
+			if useJSON {
+				if err := util.OutputResult(explanation, "json", os.Stdout); err != nil {
+					fmt.Printf("Error outputting JSON: %v\n", err)
+				}
+				return
+			}
+
+			e := explanation
+			fmt.Println("🔎 Keysign request")
+			fmt.Printf("  Chain:  %s\n", e.Chain)
+			token := e.Token.Ticker
+			if e.Token.IsNative {
+				token += " (native)"
+			} else if e.Token.ContractAddress != "" {
+				token += " (" + e.Token.ContractAddress + ")"
+			}
+			fmt.Printf("  Token:  %s\n", token)
+			fmt.Printf("  From:   %s\n", e.From)
+			fmt.Printf("  To:     %s\n", e.To)
+			fmt.Printf("  Amount: %s (%s base units)\n", e.AmountFormatted, e.Amount)
+			if e.Memo != "" {
+				fmt.Printf("  Memo:   %s\n", e.Memo)
+			}
+			for _, section := range []struct {
+				title   string
+				details []keysign.Detail
+			}{{"Fees", e.Fees}, {"Swap", e.Swap}, {"Approval", e.Approval}} {
+				if len(section.details) == 0 {
+					continue
+				}
+				fmt.Printf("\n%s:\n", section.title)
+				for _, detail := range section.details {
+					fmt.Printf("  %-30s %s\n", detail.Label+":", detail.Value)
+				}
+			}
+			if len(e.UTXOs) > 0 {
+				fmt.Println("\nInputs:")
+				for _, utxo := range e.UTXOs {
+					fmt.Printf("  %s:%d  %d\n", utxo.Hash, utxo.Index, utxo.Amount)
+				}
+			}
+			fmt.Println("\nSession:")
+			fmt.Printf("  Initiator:   %s\n", e.LocalPartyID)
+			fmt.Printf("  Session ID:  %s\n", e.SessionID)
+			fmt.Printf("  Relay:       %v\n", e.UseVultisigRelay)
+			if e.VaultPublicKey != "" {
+				fmt.Printf("  Vault key:   %s\n", e.VaultPublicKey)
+			}
+
+			if len(e.Hashes) > 0 {
+				fmt.Println("\n✍️  Message hashes to be signed:")
+				for _, hash := range e.Hashes {
+					fmt.Printf("  %s\n    %s %s (%s)\n", hash.Description, hash.KeyType, hash.Hash, hash.DerivePath)
+				}
+			}
+			for _, note := range e.Notes {
+				fmt.Printf("\nℹ️  %s\n", note)
+			}
+
+			if check := e.Vault; check != nil {
+				fmt.Printf("\nVault %s:\n", check.Name)
+				switch {
+				case check.ChainNotSupported:
+					fmt.Printf("  ⚠️  vultool does not derive %s addresses; the sender could not be checked\n", e.Chain)
+				case check.FromMatches:
+					fmt.Printf("  ✅ From address is the vault's %s address\n", e.Chain)
+				default:
+					fmt.Printf("  ❌ From address is NOT the vault's %s address (%s)\n", e.Chain, check.Address)
+				}
+				switch {
+				case check.PublicKeyMissing:
+					fmt.Println("  ⚠️  Request names no vault public key; it could not be checked")
+				case check.PublicKeyMatches:
+					fmt.Println("  ✅ Vault public key matches")
+				default:
+					fmt.Println("  ❌ Request names a different vault public key")
+				}
+			}
+		},
+	}
+	explainKeysignCmd.Flags().String("data", "", "Keysign deeplink/QR text, hex or base64 given inline")
+	explainKeysignCmd.Flags().StringVarP(&vaultFile, "vault", "f", "", "Vault file to check the sender against")
+	explainKeysignCmd.Flags().StringVar(&password, "password", "", "Password for an encrypted vault file")
+	explainKeysignCmd.Flags().Bool("json", false, "Output in JSON format")
+
+	// sign-evm: offline EVM transaction signing from a JSON template
+	signEVMCmd := &cobra.Command{
+		Use:   "sign-evm --tx <template.json> [share files...]",
+		Short: "Sign an EVM transaction (EIP-1559 or legacy) offline from a JSON template",
+		Long: `Build an EVM transaction from a JSON template, sign its RLP signing hash and
+print the raw signed transaction and its hash, ready to broadcast from any
+networked machine.
+
+The template holds chainId, nonce, to, value, data and gas, plus either
+maxFeePerGas and maxPriorityFeePerGas (EIP-1559) or gasPrice (legacy, EIP-155).
+Quantities may be numbers, decimal strings or 0x hex.
+
+Sign with ≥t share files (threshold keysign, the key is never assembled) or with
+the Ethereum private key printed by 'recover' and the vault it came from. Either
+way the signature must recover to the vault's Ethereum address (m/44'/60'/0'/0/0),
+which every EVM chain shares.`,
+		Example: `  # tx.json: {"chainId":1,"nonce":4,"to":"0x...","value":"1000000000000000000",
+  #           "gas":21000,"maxFeePerGas":"30000000000","maxPriorityFeePerGas":"1000000000"}
+  vultool sign-evm --tx tx.json share1.vult share2.vult
+
+  # Sweep with a key recovered earlier
+  vultool sign-evm --tx tx.json --private-key <hex> -f share1.vult --json`,
+		Run: func(cmd *cobra.Command, args []string) {
+			txFile, _ := cmd.Flags().GetString("tx")
+			useJSON, _ := cmd.Flags().GetBool("json")
+
+			data, err := os.ReadFile(txFile)
+			if err != nil {
+				fmt.Printf("Error reading transaction template: %v\n", err)
+				return
+			}
+			template, err := evm.ParseTemplate(data)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+
+			source, vaultInfo, err := keySource(cmd, args, password)
+			if err != nil {
+				fmt.Printf("Error setting up the signing key: %v\n", err)
+				return
+			}
+			from, err := evm.VaultAddress(vaultInfo)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+
+			if !useJSON {
+				fmt.Printf("🔄 Signing for %s (vault %s) with %s...\n", from.Hex(), vaultInfo.Name, source.Describe())
+			}
+			signed, err := evm.Sign(template, source, from)
+			if err != nil {
+				fmt.Printf("❌ Signing failed: %v\n", err)
+				return
+			}
+
+			if useJSON {
+				if err := util.OutputResult(signed, "json", os.Stdout); err != nil {
+					fmt.Printf("Error outputting JSON: %v\n", err)
+				}
+				return
+			}
+			fmt.Println("✅ Transaction signed")
+			fmt.Printf("Type:         %s (chain ID %s, nonce %d)\n", signed.Type, signed.ChainID, signed.Nonce)
+			fmt.Printf("From:         %s\n", signed.From)
+			if signed.To != "" {
+				fmt.Printf("To:           %s\n", signed.To)
+			} else {
+				fmt.Println("To:           (contract creation)")
+			}
+			fmt.Printf("Value:        %s wei\n", signed.Value)
+			fmt.Printf("Signing hash: %s\n", signed.SigningHash)
+			fmt.Printf("Tx hash:      %s\n", signed.Hash)
+			fmt.Printf("Raw tx:       %s\n", signed.RawTransaction)
+		},
+	}
+	signEVMCmd.Flags().String("tx", "", "Unsigned transaction template (JSON) (required)")
+	signEVMCmd.Flags().Bool("json", false, "Output in JSON format")
+	addKeySourceFlags(signEVMCmd, &vaultFile, &password)
+	if err := signEVMCmd.MarkFlagRequired("tx"); err != nil {
+		fmt.Printf("Error setting up sign-evm CLI flags: %v\n", err)
+		os.Exit(1)
+	}
+
+	// sign-psbt: sign the vault's inputs of a PSBT offline
+	signPSBTCmd := &cobra.Command{
+		Use:   "sign-psbt --psbt <file> [share files...]",
+		Short: "Sign the vault's inputs of a Bitcoin, Litecoin or Dogecoin PSBT offline",
+		Long: `Sign every input of a PSBT that the vault can spend, finalise it and, once all
+inputs are final, extract the raw transaction ready to broadcast.
+
+Inputs are matched to vault keys through their BIP32 derivation entries, or by
+trying the receive and change addresses at the chain's common paths (BIP84,
+BIP49 and BIP44, indexes 0-19). P2WPKH, P2SH-P2WPKH and P2PKH inputs are
+supported; inputs of other wallets are left for their signers.
+
+Sign with ≥t share files (threshold keysign) or with the key printed by
+'recover' plus the vault it came from.`,
+		Example: `  # Sign and extract a Bitcoin sweep with 2 of 3 shares
+  vultool sign-psbt --psbt sweep.psbt share1.vult share2.vult
+
+  # Sign a Litecoin PSBT with a recovered key and keep the signed PSBT
+  vultool sign-psbt --psbt ltc.psbt --chain ltc --private-key <hex> -f share1.vult --output signed.psbt`,
+		Run: func(cmd *cobra.Command, args []string) {
+			psbtFile, _ := cmd.Flags().GetString("psbt")
+			chainName, _ := cmd.Flags().GetString("chain")
+			outputFile, _ := cmd.Flags().GetString("output")
+			useJSON, _ := cmd.Flags().GetBool("json")
+
+			chain, err := utxo.LookupChain(chainName)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+			packet, err := readPSBT(psbtFile)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+
+			source, vaultInfo, err := keySource(cmd, args, password)
+			if err != nil {
+				fmt.Printf("Error setting up the signing key: %v\n", err)
+				return
+			}
+
+			if !useJSON {
+				fmt.Printf("🔄 Signing %s PSBT for vault %s with %s...\n", chain.Name, vaultInfo.Name, source.Describe())
+			}
+			result, err := utxo.SignPSBT(packet, chain, vaultInfo, source)
+			if err != nil {
+				fmt.Printf("❌ Signing failed: %v\n", err)
+				return
+			}
+
+			if outputFile != "" {
+				if err := vault.ValidateSafeOutputPath(outputFile); err != nil {
+					fmt.Printf("Unsafe output path: %v\n", err)
+					return
+				}
+				if err := os.WriteFile(outputFile, []byte(result.PSBT+"\n"), 0o600); err != nil {
+					fmt.Printf("Error writing signed PSBT: %v\n", err)
+					return
+				}
+			}
+
+			if useJSON {
+				if err := util.OutputResult(result, "json", os.Stdout); err != nil {
+					fmt.Printf("Error outputting JSON: %v\n", err)
+				}
+				return
+			}
+			fmt.Printf("✅ Signed %d input(s)\n", len(result.Signed))
+			for _, input := range result.Signed {
+				fmt.Printf("  #%d  %-12s %s  %d sat  (%s)\n", input.Index, input.ScriptType, input.Address, input.Amount, input.DerivePath)
+			}
+			if len(result.Skipped) > 0 {
+				fmt.Printf("⚠️  Inputs %v do not belong to this vault and were left unsigned\n", result.Skipped)
+			}
+			if outputFile != "" {
+				fmt.Printf("Signed PSBT written to: %s\n", outputFile)
+			}
+			if !result.Complete {
+				if outputFile == "" {
+					fmt.Printf("\nSigned PSBT (incomplete, pass it to the other signers):\n%s\n", result.PSBT)
+				}
+				return
+			}
+			fmt.Printf("\nTxID:   %s\n", result.TxID)
+			fmt.Printf("Raw tx: %s\n", result.RawTransaction)
+		},
+	}
+	signPSBTCmd.Flags().String("psbt", "", "PSBT file (binary, base64 or hex), or - for stdin (required)")
+	signPSBTCmd.Flags().String("chain", "btc", "Chain the PSBT spends on: btc, ltc or doge")
+	signPSBTCmd.Flags().String("output", "", "Write the signed PSBT (base64) to this file")
+	signPSBTCmd.Flags().Bool("json", false, "Output in JSON format")
+	addKeySourceFlags(signPSBTCmd, &vaultFile, &password)
+	if err := signPSBTCmd.MarkFlagRequired("psbt"); err != nil {
+		fmt.Printf("Error setting up sign-psbt CLI flags: %v\n", err)
+		os.Exit(1)
+	}
+
+	// sign-solana: sign a Solana transaction with the vault's EdDSA key
+	signSolanaCmd := &cobra.Command{
+		Use:   "sign-solana --tx <file> [share files...]",
+		Short: "Sign a Solana transaction offline with the vault's EdDSA key",
+		Long: `Sign a serialized Solana transaction, or a bare transaction message, with the
+vault's EdDSA key and print the signed transaction in base64 and base58, ready
+to broadcast from any networked machine.
+
+TSS EdDSA keys are bare scalars, not seeds: most Solana wallets cannot import
+the key 'recover' prints because they expand a seed into a different key. This
+command signs with the scalar itself (its hex, or the base64 seed or keypair
+formats 'recover' prints) or with ≥t share files, and checks every signature
+against the vault's EdDSA public key, which is its Solana address.
+
+The transaction may be base64, base58, hex or raw bytes. The vault must be one
+of its required signers; other signers' slots are left as they are.`,
+		Example: `  # Sign a transfer built elsewhere with a recovered key
+  vultool sign-solana --tx transfer.b64 --private-key <hex> -f share1.vult
+
+  # Co-sign with 2 of 3 shares
+  vultool sign-solana --tx transfer.b64 share1.vult share2.vult --json`,
+		Run: func(cmd *cobra.Command, args []string) {
+			txFile, _ := cmd.Flags().GetString("tx")
+			useJSON, _ := cmd.Flags().GetBool("json")
+
+			var data []byte
+			var err error
+			if txFile == "-" {
+				data, err = io.ReadAll(os.Stdin)
+			} else {
+				data, err = os.ReadFile(txFile)
+			}
+			if err != nil {
+				fmt.Printf("Error reading transaction: %v\n", err)
+				return
+			}
+			tx, err := solana.Decode(data)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+
+			source, vaultInfo, err := eddsaKeySource(cmd, args, password)
+			if err != nil {
+				fmt.Printf("Error setting up the signing key: %v\n", err)
+				return
+			}
+
+			if !useJSON {
+				fmt.Printf("🔄 Signing Solana transaction for vault %s with %s...\n", vaultInfo.Name, source.Describe())
+			}
+			signed, err := solana.Sign(tx, source, vaultInfo)
+			if err != nil {
+				fmt.Printf("❌ Signing failed: %v\n", err)
+				return
+			}
+
+			if useJSON {
+				if err := util.OutputResult(signed, "json", os.Stdout); err != nil {
+					fmt.Printf("Error outputting JSON: %v\n", err)
+				}
+				return
+			}
+			fmt.Println("✅ Transaction signed")
+			fmt.Printf("Signer:       %s (signature %d)\n", signed.Signer, signed.SignerIndex)
+			fmt.Printf("Fee payer:    %s\n", signed.FeePayer)
+			fmt.Printf("Message:      %s, %d instruction(s), blockhash %s\n", signed.Version, signed.Instructions, signed.Blockhash)
+			for _, transfer := range signed.Transfers {
+				fmt.Printf("Transfer:     %d lamports %s → %s\n", transfer.Lamports, transfer.From, transfer.To)
+			}
+			fmt.Printf("Signature:    %s\n", signed.Signature)
+			if !signed.Complete {
+				fmt.Println("⚠️  Other signers still have to sign before it can be broadcast")
+			}
+			fmt.Printf("\nBase64:\n%s\n", signed.Base64)
+			fmt.Printf("\nBase58:\n%s\n", signed.Base58)
+		},
+	}
+	signSolanaCmd.Flags().String("tx", "", "Serialized transaction or message (base64, base58, hex or raw), or - for stdin (required)")
+	signSolanaCmd.Flags().Bool("json", false, "Output in JSON format")
+	signSolanaCmd.Flags().String("private-key", "", "Sign with this recovered EdDSA key (hex scalar or base64, as printed by 'recover') instead of share files")
+	signSolanaCmd.Flags().StringVarP(&vaultFile, "vault", "f", "", "Vault the recovered key belongs to (required with --private-key)")
+	signSolanaCmd.Flags().StringVar(&password, "password", "", "Password for encrypted vault files")
+	if err := signSolanaCmd.MarkFlagRequired("tx"); err != nil {
+		fmt.Printf("Error setting up sign-solana CLI flags: %v\n", err)
+		os.Exit(1)
+	}
+
+	// verify-signature: check a message signature against a vault
+	verifySignatureCmd := &cobra.Command{
+		Use:   "verify-signature --format <format> --signature <sig> -f <vault.vult>",
+		Short: "Check that a message signature was made by one of the vault's keys",
+		Long: `Verify a message signature and confirm its signer is one of the vault's derived
+addresses or public keys, e.g. to validate proof-of-reserves and ownership
+attestations. Exits with code 0 if the vault signed the message, 1 otherwise.
+
+Formats:
+  bip137   Bitcoin Core / Electrum compact signature (base64)
+  bip322   BIP-322 simple or full signature; --address is required
+  eip191   Ethereum personal_sign (65-byte r||s||v)
+  eip712   Ethereum typed data; the message is the typed data JSON
+  ed25519  raw Ed25519 signature of the message bytes (Solana, SUI)
+  solana   Solana off-chain message signature (solana sign-offchain-message)
+
+ECDSA signers are looked for at the --path given, or at the common paths: BIP84,
+BIP49 and BIP44 receive and change addresses 0-19 for Bitcoin, and
+m/44'/60'/0'/0/0-19 and m/44'/60'/0-19'/0/0 for Ethereum. Ed25519 signatures are
+checked against the vault's EdDSA key.`,
+		Example: `  vultool verify-signature -f vault.vult --format bip322 --address bc1q... \
+    --message "proof of reserves" --signature AkcwRAIg...
+
+  vultool verify-signature -f vault.vult --format eip712 --message-file typed.json --signature 0x...
+
+  vultool verify-signature -f vault.vult --format ed25519 --message "hello" --signature <base58>`,
+		Run: func(cmd *cobra.Command, args []string) {
+			format, _ := cmd.Flags().GetString("format")
+			text, _ := cmd.Flags().GetString("message")
+			messageFile, _ := cmd.Flags().GetString("message-file")
+			signature, _ := cmd.Flags().GetString("signature")
+			address, _ := cmd.Flags().GetString("address")
+			paths, _ := cmd.Flags().GetStringSlice("path")
+			useJSON, _ := cmd.Flags().GetBool("json")
+
+			msg := []byte(text)
+			if messageFile != "" {
+				if text != "" {
+					fmt.Println("Give either --message or --message-file, not both.")
+					os.Exit(1)
+				}
+				var err error
+				if msg, err = os.ReadFile(messageFile); err != nil {
+					fmt.Printf("Error reading message: %v\n", err)
+					os.Exit(1)
+				}
+			}
+			absPath, err := filepath.Abs(vaultFile)
+			if err != nil {
+				fmt.Printf("Error getting absolute path: %v\n", err)
+				os.Exit(1)
+			}
+			vaultInfo, err := vault.ParseVaultFileWithPassword(absPath, password)
+			if err != nil {
+				fmt.Printf("Error parsing vault file: %v\n", err)
+				os.Exit(1)
+			}
+
+			result, err := message.Verify(&message.Request{
+				Format:    strings.ToLower(format),
+				Message:   msg,
+				Signature: signature,
+				Address:   address,
+				Paths:     paths,
+			}, vaultInfo)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+
+			if useJSON {
+				if err := util.OutputResult(result, "json", os.Stdout); err != nil {
+					fmt.Printf("Error outputting JSON: %v\n", err)
+				}
+			} else {
+				switch {
+				case result.Verified():
+					fmt.Printf("✅ Signed by vault %s\n", vaultInfo.Name)
+				case result.Valid:
+					fmt.Printf("❌ Valid signature, but not by vault %s: %s\n", vaultInfo.Name, result.Reason)
+				default:
+					fmt.Printf("❌ Invalid signature: %s\n", result.Reason)
+				}
+				fmt.Printf("  Format:      %s\n", result.Format)
+				if result.Signer != "" {
+					fmt.Printf("  Signer:      %s\n", result.Signer)
+				}
+				if result.ScriptType != "" {
+					fmt.Printf("  Script type: %s\n", result.ScriptType)
+				}
+				if result.PublicKey != "" {
+					fmt.Printf("  Public key:  %s\n", result.PublicKey)
+				}
+				if result.DerivePath != "" {
+					fmt.Printf("  Derive path: %s\n", result.DerivePath)
+				}
+				if result.Hash != "" {
+					fmt.Printf("  Signed hash: %s\n", result.Hash)
+				}
+			}
+			if !result.Verified() {
+				os.Exit(1)
+			}
+		},
+	}
+	verifySignatureCmd.Flags().String("format", "", "Signature format: "+strings.Join(message.Formats, ", ")+" (required)")
+	verifySignatureCmd.Flags().String("message", "", "Signed message text (or EIP-712 typed data JSON)")
+	verifySignatureCmd.Flags().String("message-file", "", "Read the signed message from this file, byte for byte")
+	verifySignatureCmd.Flags().String("signature", "", "Signature in hex, base64 or base58 (required)")
+	verifySignatureCmd.Flags().String("address", "", "Address or public key that claims to have signed (required for bip322)")
+	verifySignatureCmd.Flags().StringSlice("path", []string{}, "Derivation paths to look for the signer at (default: common paths)")
+	verifySignatureCmd.Flags().StringVarP(&vaultFile, "vault", "f", "", "Vault file to check the signer against (required)")
+	verifySignatureCmd.Flags().StringVar(&password, "password", "", "Password for an encrypted vault file")
+	verifySignatureCmd.Flags().Bool("json", false, "Output in JSON format")
+	for _, flag := range []string{"format", "signature", "vault"} {
+		if err := verifySignatureCmd.MarkFlagRequired(flag); err != nil {
+			fmt.Printf("Error setting up verify-signature CLI flags: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// sign-message: sign an off-chain message with one of the vault's keys
+	signMessageCmd := &cobra.Command{
+		Use:   "sign-message --format <format> --message <text> [share files...]",
+		Short: "Sign an off-chain message for ownership proofs and address whitelisting",
+		Long: `Sign a message with the vault's key at a derivation path and print the
+signature in the format the message's ecosystem expects, e.g. to prove
+ownership of an address to an exchange or for proof of reserves.
+
+Formats:
+  bip137   Bitcoin Core / Electrum compact signature (base64)
+  bip322   BIP-322 simple signature (base64 witness); the full to_sign
+           transaction for P2SH-P2WPKH and BIP-137 for P2PKH addresses
+  eip191   Ethereum personal_sign, 0x-prefixed r||s||v with v 27 or 28
+  eip712   Ethereum typed data; the message is the typed data JSON
+  solana   Solana off-chain message (base58), as 'solana sign-offchain-message'
+  ed25519  raw Ed25519 signature of the message bytes (base58)
+
+Bitcoin formats sign for the address type of the path's purpose: 84 native
+SegWit, 49 nested SegWit, 44 legacy. The default paths are m/84'/0'/0'/0/0 for
+Bitcoin and m/44'/60'/0'/0/0 for Ethereum; Solana and Ed25519 messages are
+signed with the vault's EdDSA key, which is not derived.
+
+Signs with a key printed by 'recover' or with ≥t share files, and checks every
+signature the way verify-signature does before printing it.`,
+		Example: `  # Prove ownership of the vault's Bitcoin address with a recovered key
+  vultool sign-message --format bip322 --message "I own this address" \
+    --private-key <hex> -f share1.vult
+
+  # Sign EIP-712 typed data with 2 of 3 shares
+  vultool sign-message --format eip712 --message-file typed.json share1.vult share2.vult
+
+  # Solana off-chain message with the recovered EdDSA key
+  vultool sign-message --format solana --message "hello" --private-key <hex> -f share1.vult`,
+		Run: func(cmd *cobra.Command, args []string) {
+			format, _ := cmd.Flags().GetString("format")
+			text, _ := cmd.Flags().GetString("message")
+			messageFile, _ := cmd.Flags().GetString("message-file")
+			path, _ := cmd.Flags().GetString("path")
+			useJSON, _ := cmd.Flags().GetBool("json")
+			format = strings.ToLower(format)
+
+			msg := []byte(text)
+			if messageFile != "" {
+				if text != "" {
+					fmt.Println("Give either --message or --message-file, not both.")
+					return
+				}
+				var err error
+				if msg, err = os.ReadFile(messageFile); err != nil {
+					fmt.Printf("Error reading message: %v\n", err)
+					return
+				}
+			}
+
+			var signed *message.Signed
+			switch format {
+			case message.FormatSolana, message.FormatEd25519:
+				if path != "" {
+					fmt.Printf("Error: %s messages are signed with the vault's EdDSA key, which takes no --path\n", format)
+					return
+				}
+				source, vaultInfo, err := eddsaKeySource(cmd, args, password)
+				if err != nil {
+					fmt.Printf("Error setting up the signing key: %v\n", err)
+					return
+				}
+				if !useJSON {
+					fmt.Printf("🔄 Signing %s message for vault %s with %s...\n", format, vaultInfo.Name, source.Describe())
+				}
+				if signed, err = message.SignEdDSA(format, msg, source, vaultInfo); err != nil {
+					fmt.Printf("❌ Signing failed: %v\n", err)
+					return
+				}
+			case message.FormatBIP137, message.FormatBIP322, message.FormatEIP191, message.FormatEIP712:
+				source, vaultInfo, err := keySource(cmd, args, password)
+				if err != nil {
+					fmt.Printf("Error setting up the signing key: %v\n", err)
+					return
+				}
+				if !useJSON {
+					fmt.Printf("🔄 Signing %s message for vault %s with %s...\n", format, vaultInfo.Name, source.Describe())
+				}
+				if signed, err = message.Sign(format, msg, path, source, vaultInfo); err != nil {
+					fmt.Printf("❌ Signing failed: %v\n", err)
+					return
+				}
+			default:
+				fmt.Printf("Error: unsupported format %q (use %s)\n", format, strings.Join(message.Formats, ", "))
+				return
+			}
+
+			if useJSON {
+				if err := util.OutputResult(signed, "json", os.Stdout); err != nil {
+					fmt.Printf("Error outputting JSON: %v\n", err)
+				}
+				return
+			}
+			fmt.Println("✅ Message signed")
+			fmt.Printf("  Format:      %s\n", signed.Format)
+			fmt.Printf("  Signer:      %s\n", signed.Signer)
+			if signed.ScriptType != "" {
+				fmt.Printf("  Script type: %s\n", signed.ScriptType)
+			}
+			fmt.Printf("  Public key:  %s\n", signed.PublicKey)
+			if signed.DerivePath != "" {
+				fmt.Printf("  Derive path: %s\n", signed.DerivePath)
+			}
+			if signed.Hash != "" {
+				fmt.Printf("  Signed hash: %s\n", signed.Hash)
+			}
+			fmt.Printf("\nSignature (%s):\n%s\n", signed.Encoding, signed.Signature)
+		},
+	}
+	signMessageCmd.Flags().String("format", "", "Signature format: "+strings.Join(message.Formats, ", ")+" (required)")
+	signMessageCmd.Flags().String("message", "", "Message text (or EIP-712 typed data JSON)")
+	signMessageCmd.Flags().String("message-file", "", "Read the message from this file, byte for byte")
+	signMessageCmd.Flags().String("path", "", "Derivation path to sign with (default: m/84'/0'/0'/0/0 for Bitcoin, m/44'/60'/0'/0/0 for Ethereum)")
+	signMessageCmd.Flags().Bool("json", false, "Output in JSON format")
+	signMessageCmd.Flags().String("private-key", "", "Sign with this recovered key (ECDSA hex or WIF, or the EdDSA key for solana and ed25519, as printed by 'recover') instead of share files")
+	signMessageCmd.Flags().StringVarP(&vaultFile, "vault", "f", "", "Vault the recovered key belongs to (required with --private-key)")
+	signMessageCmd.Flags().StringVar(&password, "password", "", "Password for encrypted vault files")
+	if err := signMessageCmd.MarkFlagRequired("format"); err != nil {
+		fmt.Printf("Error setting up sign-message CLI flags: %v\n", err)
+		os.Exit(1)
+	}
+
+	// batch-sign: sign a manifest of jobs with one key source
+	batchSignCmd := &cobra.Command{
+		Use:   "batch-sign --manifest <file> [share files...]",
+		Short: "Sign many messages and transactions from a CSV or JSON manifest",
+		Long: `Sign every job of a manifest with one key source, set up once, and write a
+results file with each job's status, signature and signed transaction. A job
+that fails is recorded with its error and the others are still signed.
+
+The manifest is a JSON array of jobs (or {"jobs": [...]}) or CSV with a header
+row. Each job has:
+  id             names the job in the results (default: its position)
+  chain          the chain of tx jobs: btc, ltc or doge (PSBT), solana, or any
+                 EVM chain (the template carries the chain ID)
+  digest         a sign-message format (` + strings.Join(message.Formats, ", ") + `),
+                 ecdsa (32-byte hash in hex, at path), eddsa (bytes in hex)
+                 or tx (a transaction template)
+  path           derivation path of ecdsa and Bitcoin/Ethereum message jobs
+  message        the message, or the hex of ecdsa and eddsa jobs
+  template       EVM template JSON, PSBT or Solana transaction of tx jobs
+  template_file  file holding the template, relative to the manifest
+
+Signs with ≥t share files, which sign every job, or with recovered keys:
+--private-key for ECDSA jobs and --eddsa-key for Solana and Ed25519 jobs.
+Exits with code 1 if any job failed.`,
+		Example: `  # payouts.csv:
+  #   id,chain,digest,template_file
+  #   alice,ethereum,tx,alice.json
+  #   bob,base,tx,bob.json
+  vultool batch-sign --manifest payouts.csv share1.vult share2.vult
+
+  # CSV results, signing with recovered keys
+  vultool batch-sign --manifest jobs.json --output results.csv \
+    --private-key <hex> --eddsa-key <hex> -f share1.vult`,
+		Run: func(cmd *cobra.Command, args []string) {
+			manifestFile, _ := cmd.Flags().GetString("manifest")
+			output, _ := cmd.Flags().GetString("output")
+			force, _ := cmd.Flags().GetBool("force")
+
+			if output == "" {
+				output = strings.TrimSuffix(manifestFile, filepath.Ext(manifestFile)) + "-results.json"
+			}
+			if _, err := os.Stat(output); err == nil && !force {
+				fmt.Printf("Error: %s already exists (use --force to overwrite)\n", output)
+				os.Exit(1)
+			}
+			jobs, err := batch.LoadManifest(manifestFile)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			signers, vaultInfo, err := batchSigners(cmd, args, password)
+			if err != nil {
+				fmt.Printf("Error setting up the signing keys: %v\n", err)
+				os.Exit(1)
+			}
+
+			fmt.Printf("🔄 Signing %d jobs for vault %s...\n", len(jobs), vaultInfo.Name)
+			results, summary := batch.Run(jobs, signers, vaultInfo, func(r *batch.Result) {
+				if r.Status == batch.StatusSigned {
+					fmt.Printf("  ✅ %s: signed by %s\n", r.ID, r.Signer)
+				} else {
+					fmt.Printf("  ❌ %s: %s\n", r.ID, r.Error)
+				}
+			})
+
+			format := "json"
+			if strings.EqualFold(filepath.Ext(output), ".csv") {
+				format = "csv"
+			}
+			file, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+			if err != nil {
+				fmt.Printf("Error writing results: %v\n", err)
+				os.Exit(1)
+			}
+			err = batch.WriteResults(file, results, format)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				fmt.Printf("Error writing results: %v\n", err)
+				os.Exit(1)
+			}
+
+			fmt.Printf("\n%d signed, %d failed; results written to %s\n", summary.Signed, summary.Failed, output)
+			if summary.Failed > 0 {
+				os.Exit(1)
+			}
+		},
+	}
+	batchSignCmd.Flags().String("manifest", "", "CSV or JSON manifest of signing jobs (required)")
+	batchSignCmd.Flags().String("output", "", "Results file, CSV if it ends in .csv (default: <manifest>-results.json)")
+	batchSignCmd.Flags().Bool("force", false, "Overwrite an existing results file")
+	batchSignCmd.Flags().String("private-key", "", "Sign ECDSA jobs with this recovered private key (hex or WIF, as printed by 'recover') instead of share files")
+	batchSignCmd.Flags().String("eddsa-key", "", "Sign EdDSA jobs with this recovered EdDSA key (hex scalar or base64, as printed by 'recover') instead of share files")
+	batchSignCmd.Flags().StringVarP(&vaultFile, "vault", "f", "", "Vault the recovered keys belong to (required with --private-key or --eddsa-key)")
+	batchSignCmd.Flags().StringVar(&password, "password", "", "Password for encrypted vault files")
+	if err := batchSignCmd.MarkFlagRequired("manifest"); err != nil {
+		fmt.Printf("Error setting up batch-sign CLI flags: %v\n", err)
+		os.Exit(1)
+	}
+
+	// analyze-psbt: read-only view of what a PSBT does to the vault's funds
+	analyzePSBTCmd := &cobra.Command{
+		Use:   "analyze-psbt --psbt <file> -f <vault.vult>",
+		Short: "Show which inputs and outputs of a PSBT are the vault's, the amount leaving it and the fee",
+		Long: `Inspect a PSBT against a vault without signing anything: a second, independent
+view of what a transaction does before anyone co-signs it.
+
+Inputs and outputs are matched to vault keys derived from the vault's ECDSA
+public key and chain code, at the BIP32 paths the PSBT records and at the
+chain's common receive and change paths. Vault outputs of a transaction the
+vault funds are reported as change.`,
+		Example: `  vultool analyze-psbt --psbt sweep.psbt -f share1.vult
+  vultool analyze-psbt --psbt ltc.psbt --chain ltc -f share1.vult --json`,
+		Run: func(cmd *cobra.Command, args []string) {
+			psbtFile, _ := cmd.Flags().GetString("psbt")
+			chainName, _ := cmd.Flags().GetString("chain")
+			useJSON, _ := cmd.Flags().GetBool("json")
+
+			chain, err := utxo.LookupChain(chainName)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+			packet, err := readPSBT(psbtFile)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+			absPath, err := filepath.Abs(vaultFile)
+			if err != nil {
+				fmt.Printf("Error getting absolute path: %v\n", err)
+				return
+			}
+			vaultInfo, err := vault.ParseVaultFileWithPassword(absPath, password)
+			if err != nil {
+				fmt.Printf("Error parsing vault file: %v\n", err)
+				return
+			}
+
+			a, err := utxo.AnalyzePSBT(packet, chain, vaultInfo)
+			if err != nil {
+				fmt.Printf("Error analysing PSBT: %v\n", err)
+				return
+			}
+			if useJSON {
+				if err := util.OutputResult(a, "json", os.Stdout); err != nil {
+					fmt.Printf("Error outputting JSON: %v\n", err)
+				}
+				return
+			}
+
+			fmt.Printf("🔎 %s PSBT %s against vault %s\n", a.Chain, a.TxID, a.Vault)
+			fmt.Println("\nInputs:")
+			for _, in := range a.Inputs {
+				owner := "external"
+				if in.Vault {
+					owner = "VAULT " + in.DerivePath
+				}
+				if in.Unknown {
+					fmt.Printf("  #%d  %s  unknown amount and owner\n", in.Index, in.Outpoint)
+					continue
+				}
+				signed := ""
+				if in.Signed {
+					signed = "  (signed)"
+				}
+				fmt.Printf("  #%d  %12d sat  %s  [%s]%s\n", in.Index, in.Amount, in.Address, owner, signed)
+			}
+			fmt.Println("\nOutputs:")
+			for _, out := range a.Outputs {
+				owner := "external"
+				switch {
+				case out.Change:
+					owner = "CHANGE " + out.DerivePath
+				case out.Vault:
+					owner = "VAULT " + out.DerivePath
+				}
+				fmt.Printf("  #%d  %12d sat  %s  [%s]\n", out.Index, out.Amount, out.Address, owner)
+			}
+
+			fmt.Println()
+			fmt.Printf("Vault inputs:       %d sat\n", a.VaultInputTotal)
+			fmt.Printf("Back to the vault:  %d sat\n", a.VaultOutputTotal)
+			fmt.Printf("Net leaving vault:  %d sat\n", a.NetAmount)
+			fmt.Printf("Paid to others:     %d sat\n", a.ExternalTotal)
+			if a.FeeKnown {
+				fmt.Printf("Fee:                %d sat\n", a.Fee)
+			} else {
+				fmt.Println("Fee:                unknown")
+			}
+			for _, warning := range a.Warnings {
+				fmt.Printf("⚠️  %s\n", warning)
+			}
+		},
+	}
+	analyzePSBTCmd.Flags().String("psbt", "", "PSBT file (binary, base64 or hex), or - for stdin (required)")
+	analyzePSBTCmd.Flags().String("chain", "btc", "Chain the PSBT spends on: btc, ltc or doge")
+	analyzePSBTCmd.Flags().StringVarP(&vaultFile, "vault", "f", "", "Vault file to check the PSBT against (required)")
+	analyzePSBTCmd.Flags().StringVar(&password, "password", "", "Password for an encrypted vault file")
+	analyzePSBTCmd.Flags().Bool("json", false, "Output in JSON format")
+	for _, flag := range []string{"psbt", "vault"} {
+		if err := analyzePSBTCmd.MarkFlagRequired(flag); err != nil {
+			fmt.Printf("Error setting up analyze-psbt CLI flags: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// relay: local Vultisig relay server
+	relayCmd := &cobra.Command{
+		Use:   "relay",
+		Short: "Run a local Vultisig-compatible relay server",
+		Long: `Run an HTTP relay implementing the Vultisig relay protocol (session registration,
+start, message post/poll/delete and completion endpoints) so devices on an
+isolated LAN can run keygen and keysign without the public relay.
+
+All state is kept in memory; sessions are dropped after --ttl without activity.
+Point the apps' local mode or vultool's relay transport at http://<host>:<port>.`,
+		Example: `  # Serve on the port the apps use for local mode
+  vultool relay
+
+  # Listen on one interface with a longer session TTL
+  vultool relay --listen 192.168.1.10:18080 --ttl 10m`,
+		Run: func(cmd *cobra.Command, args []string) {
+			listen, _ := cmd.Flags().GetString("listen")
+			ttl, _ := cmd.Flags().GetDuration("ttl")
+
+			server := relay.NewServer(ttl)
+			stop := make(chan struct{})
+			go server.RunSweeper(time.Minute, stop)
+
+			httpServer := &http.Server{
+				Addr:              listen,
+				Handler:           server.Handler(),
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+
+			signals := make(chan os.Signal, 1)
+			signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+			go func() {
+				<-signals
+				close(stop)
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				if err := httpServer.Shutdown(ctx); err != nil {
+					fmt.Printf("Error shutting down relay: %v\n", err)
+				}
+			}()
+
+			fmt.Printf("📡 Vultisig relay listening on %s (session TTL %s)\n", listen, ttl)
+			if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				fmt.Printf("❌ Relay failed: %v\n", err)
+				return
+			}
+			fmt.Println("Relay stopped")
+		},
+	}
+	relayCmd.Flags().String("listen", ":18080", "Address to listen on (the apps' local relay port by default)")
+	relayCmd.Flags().Duration("ttl", relay.DefaultTTL, "Drop sessions idle for longer than this")
+
+	// qr-session: carry data to and from an air-gapped device as QR codes
+	qrSessionCmd := &cobra.Command{
+		Use:   "qr-session",
+		Short: "Show or scan multi-part QR codes for air-gapped devices",
+		Long: `Move data to and from a device that is never networked as animated multi-part
+QR codes. Payloads are split into checksummed frames that a scanner may read
+in any order; repeated frames are ignored.
+
+To run keygen, reshare or sign itself over QR codes, pass --qr to those commands.`,
+	}
+
+	qrShowCmd := &cobra.Command{
+		Use:   "show [file]",
+		Short: "Show a file as animated QR frames until interrupted",
+		Example: `  # Loop a keysign payload on the terminal
+  vultool qr-session show keysign.bin
+
+  # Write PNG frames for a slideshow instead
+  vultool qr-session show keysign.bin --png-dir ./frames`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			chunkSize, _ := cmd.Flags().GetInt("chunk-size")
+			interval, _ := cmd.Flags().GetDuration("interval")
+			pngDir, _ := cmd.Flags().GetString("png-dir")
+			text, _ := cmd.Flags().GetBool("text")
+
+			var payload []byte
+			var err error
+			if len(args) == 0 || args[0] == "-" {
+				payload, err = io.ReadAll(os.Stdin)
+			} else {
+				payload, err = os.ReadFile(args[0])
+			}
+			if err != nil {
+				fmt.Printf("Error reading payload: %v\n", err)
+				return
+			}
+
+			frames, err := transport.EncodeFrames(payload, chunkSize)
+			if err != nil {
+				fmt.Printf("Error encoding payload: %v\n", err)
+				return
+			}
+
+			if text {
+				for _, frame := range frames {
+					fmt.Println(frame)
+				}
+				return
+			}
+			if pngDir != "" {
+				if err := showQRFrames(pngDir)(context.Background(), "payload", frames); err != nil {
+					fmt.Printf("Error writing frames: %v\n", err)
+				}
+				return
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			caption := fmt.Sprintf("%d bytes, Ctrl-C to stop", len(payload))
+			if err := transport.Animate(ctx, os.Stdout, frames, interval, caption); err != nil {
+				fmt.Printf("Error showing frames: %v\n", err)
+			}
+		},
+	}
+	qrShowCmd.Flags().Int("chunk-size", transport.DefaultChunkSize, "Payload bytes per frame")
+	qrShowCmd.Flags().Duration("interval", qrFrameInterval, "Time each frame is shown")
+	qrShowCmd.Flags().String("png-dir", "", "Write PNG frames into this directory instead of animating")
+	qrShowCmd.Flags().Bool("text", false, "Print the frame texts, one per line, instead of codes")
+
+	qrScanCmd := &cobra.Command{
+		Use:   "scan",
+		Short: "Reassemble a payload from scanned frame texts on stdin",
+		Example: `  # Pipe a scanner's output, one frame per line
+  zbarcam --raw | vultool qr-session scan --output keysign.bin`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			output, _ := cmd.Flags().GetString("output")
+
+			decoder := transport.NewFrameDecoder()
+			scanner := bufio.NewScanner(os.Stdin)
+			scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+			for scanner.Scan() {
+				payload, err := decoder.Add(scanner.Text())
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "⚠️  Ignoring frame: %v\n", err)
+					continue
+				}
+				if payload == nil {
+					for _, progress := range decoder.Progress() {
+						fmt.Fprintf(os.Stderr, "📷 %s: %d/%d frames\n", progress.ID, progress.Have, progress.Total)
+					}
+					continue
+				}
+
+				if output == "" {
+					_, _ = os.Stdout.Write(payload)
+					return
+				}
+				if err := os.WriteFile(output, payload, 0o600); err != nil {
+					fmt.Printf("Error writing %s: %v\n", output, err)
+					return
+				}
+				fmt.Fprintf(os.Stderr, "✅ Wrote %d bytes to %s\n", len(payload), output)
+				return
+			}
+			if err := scanner.Err(); err != nil {
+				fmt.Printf("Error reading frames: %v\n", err)
+				return
+			}
+			fmt.Println("❌ Input ended before a complete payload was scanned")
+		},
+	}
+	qrScanCmd.Flags().StringP("output", "o", "", "Write the payload here instead of stdout")
+
+	qrSessionCmd.AddCommand(qrShowCmd)
+	qrSessionCmd.AddCommand(qrScanCmd)
+
 	// Add all commands to root
 	rootCmd.AddCommand(inspectCmd)
 	rootCmd.AddCommand(infoCmd)
@@ -979,6 +3234,25 @@ and sequential addresses for Ethereum and other chains. The --count flag is not
 	rootCmd.AddCommand(deriveCmd)
 	rootCmd.AddCommand(listAddressesCmd)
 	rootCmd.AddCommand(listAddressesPathsCmd)
+	rootCmd.AddCommand(exportXpubCmd)
+	rootCmd.AddCommand(exportDescriptorsCmd)
+
+	// Add Creator milestone commands
+	rootCmd.AddCommand(importSeedCmd)
+	rootCmd.AddCommand(keygenCmd)
+	rootCmd.AddCommand(reshareCmd)
+	rootCmd.AddCommand(refreshCmd)
+	rootCmd.AddCommand(signCmd)
+	rootCmd.AddCommand(explainKeysignCmd)
+	rootCmd.AddCommand(signEVMCmd)
+	rootCmd.AddCommand(signPSBTCmd)
+	rootCmd.AddCommand(analyzePSBTCmd)
+	rootCmd.AddCommand(signSolanaCmd)
+	rootCmd.AddCommand(signMessageCmd)
+	rootCmd.AddCommand(batchSignCmd)
+	rootCmd.AddCommand(verifySignatureCmd)
+	rootCmd.AddCommand(relayCmd)
+	rootCmd.AddCommand(qrSessionCmd)
 
 	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
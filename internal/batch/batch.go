@@ -0,0 +1,318 @@
+package batch
+
+import (
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"slices"
+	"strings"
+
+	"github.com/rowbotony/vultool/internal/evm"
+	"github.com/rowbotony/vultool/internal/message"
+	"github.com/rowbotony/vultool/internal/network"
+	"github.com/rowbotony/vultool/internal/signer"
+	"github.com/rowbotony/vultool/internal/solana"
+	"github.com/rowbotony/vultool/internal/utxo"
+	"github.com/rowbotony/vultool/internal/vault"
+)
+
+// Job statuses
+const (
+	StatusSigned = "signed"
+	StatusFailed = "failed"
+)
+
+// Signers are the keys a batch signs with; a key type that is not set
+// fails only the jobs that need it
+type Signers struct {
+	ECDSA signer.Source
+	EdDSA signer.EdDSASource
+}
+
+// Result is the outcome of one job
+type Result struct {
+	ID          string `json:"id"`
+	Chain       string `json:"chain,omitempty"`
+	Digest      string `json:"digest"`
+	Path        string `json:"path,omitempty"`
+	Status      string `json:"status"`
+	Signer      string `json:"signer,omitempty"`
+	Signature   string `json:"signature,omitempty"`
+	Transaction string `json:"transaction,omitempty"` // signed raw transaction, or the PSBT until it is complete
+	TxHash      string `json:"tx_hash,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// Summary counts a batch's results
+type Summary struct {
+	Signed int `json:"signed"`
+	Failed int `json:"failed"`
+}
+
+// Run signs every job in order. A job that fails is recorded as failed and
+// the batch moves on; done, when given, is called after each job.
+func Run(jobs []Job, signers Signers, info *vault.VaultInfo, done func(*Result)) ([]*Result, Summary) {
+	var results []*Result
+	var summary Summary
+	for i := range jobs {
+		job := &jobs[i]
+		result := &Result{ID: job.ID, Chain: job.Chain, Digest: job.Digest, Path: job.Path}
+		if err := runJob(job, result, signers, info); err != nil {
+			result.Status, result.Error = StatusFailed, err.Error()
+			summary.Failed++
+		} else {
+			result.Status = StatusSigned
+			summary.Signed++
+		}
+		if done != nil {
+			done(result)
+		}
+		results = append(results, result)
+	}
+	return results, summary
+}
+
+// runJob signs one job into result. A panic while signing fails the job
+// rather than the whole batch, which would lose every result.
+func runJob(job *Job, result *Result, signers Signers, info *vault.VaultInfo) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("internal error: %v", r)
+		}
+	}()
+	if job.templateErr != nil {
+		return job.templateErr
+	}
+
+	switch {
+	case slices.Contains(message.Formats, job.Digest):
+		return signMessage(job, result, signers, info)
+	case job.Digest == DigestECDSA:
+		return signECDSA(job, result, signers)
+	case job.Digest == DigestEdDSA:
+		return signEdDSA(job, result, signers)
+	case job.Digest == DigestTx:
+		return signTransaction(job, result, signers, info)
+	case job.Digest == "":
+		return fmt.Errorf("no digest type given")
+	default:
+		return fmt.Errorf("unsupported digest type %q (use %s, %s, %s or %s)", job.Digest,
+			strings.Join(message.Formats, ", "), DigestECDSA, DigestEdDSA, DigestTx)
+	}
+}
+
+// signMessage signs an off-chain message the way sign-message does
+func signMessage(job *Job, result *Result, signers Signers, info *vault.VaultInfo) error {
+	if job.Message == "" {
+		return fmt.Errorf("no message given")
+	}
+	var signed *message.Signed
+	var err error
+	if job.Digest == message.FormatSolana || job.Digest == message.FormatEd25519 {
+		if err := noPath(job); err != nil {
+			return err
+		}
+		if signers.EdDSA == nil {
+			return fmt.Errorf("no EdDSA key to sign with")
+		}
+		signed, err = message.SignEdDSA(job.Digest, []byte(job.Message), signers.EdDSA, info)
+	} else {
+		if signers.ECDSA == nil {
+			return fmt.Errorf("no ECDSA key to sign with")
+		}
+		signed, err = message.Sign(job.Digest, []byte(job.Message), job.Path, signers.ECDSA, info)
+	}
+	if err != nil {
+		return err
+	}
+	result.Path = signed.DerivePath
+	result.Signer, result.Signature = signed.Signer, signed.Signature
+	return nil
+}
+
+// signECDSA signs a prepared 32-byte hash at the job's path
+func signECDSA(job *Job, result *Result, signers Signers) error {
+	if job.Path == "" {
+		return fmt.Errorf("ecdsa jobs need a path")
+	}
+	hash, err := hex.DecodeString(strings.TrimPrefix(job.Message, "0x"))
+	if err != nil || len(hash) != 32 {
+		return fmt.Errorf("ecdsa jobs sign a 32-byte hash given in hex")
+	}
+	if signers.ECDSA == nil {
+		return fmt.Errorf("no ECDSA key to sign with")
+	}
+	sig, err := signers.ECDSA.SignECDSA(hash, job.Path)
+	if err != nil {
+		return err
+	}
+	result.Signer, result.Signature = sig.PublicKey, sig.Signature
+	if sig.V != nil {
+		result.Signature += fmt.Sprintf("%02x", *sig.V)
+	}
+	return nil
+}
+
+// signEdDSA signs bytes given in hex with the vault's EdDSA key
+func signEdDSA(job *Job, result *Result, signers Signers) error {
+	if err := noPath(job); err != nil {
+		return err
+	}
+	msg, err := hex.DecodeString(strings.TrimPrefix(job.Message, "0x"))
+	if err != nil || len(msg) == 0 {
+		return fmt.Errorf("eddsa jobs sign bytes given in hex")
+	}
+	if signers.EdDSA == nil {
+		return fmt.Errorf("no EdDSA key to sign with")
+	}
+	sig, err := signers.EdDSA.SignEdDSA(msg)
+	if err != nil {
+		return err
+	}
+	result.Signer, result.Signature = sig.PublicKey, sig.Signature
+	return nil
+}
+
+// signTransaction signs a transaction template for the job's chain: a
+// PSBT for UTXO chains, a serialized transaction for Solana and a JSON
+// template for EVM chains
+func signTransaction(job *Job, result *Result, signers Signers, info *vault.VaultInfo) error {
+	if job.Template == "" {
+		return fmt.Errorf("tx jobs need a template or template_file")
+	}
+	if job.Chain == "" {
+		return fmt.Errorf("tx jobs need a chain")
+	}
+	if chain, err := utxo.LookupChain(job.Chain); err == nil {
+		return signPSBT(job, result, chain, signers, info)
+	}
+	if strings.EqualFold(job.Chain, "solana") || strings.EqualFold(job.Chain, "sol") {
+		return signSolana(job, result, signers, info)
+	}
+	chainID, ok := network.EVMChainID(job.Chain)
+	if !ok {
+		return fmt.Errorf("unsupported chain %q for tx jobs", job.Chain)
+	}
+	return signEVM(job, result, chainID, signers, info)
+}
+
+func signPSBT(job *Job, result *Result, chain *utxo.Chain, signers Signers, info *vault.VaultInfo) error {
+	if err := noPath(job); err != nil {
+		return err
+	}
+	if signers.ECDSA == nil {
+		return fmt.Errorf("no ECDSA key to sign with")
+	}
+	p, err := utxo.ParsePSBT([]byte(job.Template))
+	if err != nil {
+		return err
+	}
+	signed, err := utxo.SignPSBT(p, chain, info, signers.ECDSA)
+	if err != nil {
+		return err
+	}
+	var addresses []string
+	for _, input := range signed.Signed {
+		if !slices.Contains(addresses, input.Address) {
+			addresses = append(addresses, input.Address)
+		}
+	}
+	result.Signer = strings.Join(addresses, " ")
+	result.Transaction, result.TxHash = signed.RawTransaction, signed.TxID
+	if !signed.Complete {
+		result.Transaction = signed.PSBT
+	}
+	return nil
+}
+
+func signSolana(job *Job, result *Result, signers Signers, info *vault.VaultInfo) error {
+	if err := noPath(job); err != nil {
+		return err
+	}
+	if signers.EdDSA == nil {
+		return fmt.Errorf("no EdDSA key to sign with")
+	}
+	tx, err := solana.Decode([]byte(job.Template))
+	if err != nil {
+		return err
+	}
+	signed, err := solana.Sign(tx, signers.EdDSA, info)
+	if err != nil {
+		return err
+	}
+	result.Signer, result.Signature = signed.Signer, signed.Signature
+	result.Transaction = signed.Base64
+	if signed.Complete && signed.SignerIndex == 0 {
+		result.TxHash = signed.Signature
+	}
+	return nil
+}
+
+func signEVM(job *Job, result *Result, chainID *big.Int, signers Signers, info *vault.VaultInfo) error {
+	if job.Path != "" && job.Path != evm.DerivePath {
+		return fmt.Errorf("EVM transactions are signed at %s", evm.DerivePath)
+	}
+	if signers.ECDSA == nil {
+		return fmt.Errorf("no ECDSA key to sign with")
+	}
+	template, err := evm.ParseTemplate([]byte(job.Template))
+	if err != nil {
+		return err
+	}
+	// The chain column must not disagree with what the template signs for
+	if template.ChainID.Cmp(chainID) != 0 {
+		return fmt.Errorf("template chainId %s is not %s (chain ID %s)", template.ChainID.String(), job.Chain, chainID)
+	}
+	from, err := evm.VaultAddress(info)
+	if err != nil {
+		return err
+	}
+	signed, err := evm.Sign(template, signers.ECDSA, from)
+	if err != nil {
+		return err
+	}
+	result.Path = evm.DerivePath
+	result.Signer = signed.From
+	result.Transaction, result.TxHash = signed.RawTransaction, signed.Hash
+	return nil
+}
+
+// noPath rejects a path on jobs whose key is not chosen by one
+func noPath(job *Job) error {
+	switch {
+	case job.Path == "":
+		return nil
+	case job.Digest == DigestTx:
+		return fmt.Errorf("%s transactions take no path", job.Chain)
+	default:
+		return fmt.Errorf("%s jobs take no path", job.Digest)
+	}
+}
+
+// resultColumns are the columns of a CSV results file
+var resultColumns = []string{"id", "chain", "digest", "path", "status", "signer", "signature", "transaction", "tx_hash", "error"}
+
+// WriteResults writes results as JSON, or as CSV when format is "csv"
+func WriteResults(w io.Writer, results []*Result, format string) error {
+	if format != "csv" {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(results)
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(resultColumns); err != nil {
+		return err
+	}
+	for _, r := range results {
+		if err := writer.Write([]string{r.ID, r.Chain, r.Digest, r.Path, r.Status, r.Signer, r.Signature,
+			r.Transaction, r.TxHash, r.Error}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
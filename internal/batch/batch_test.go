@@ -0,0 +1,183 @@
+package batch
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+
+	"github.com/rowbotony/vultool/internal/ceremony"
+	"github.com/rowbotony/vultool/internal/message"
+	"github.com/rowbotony/vultool/internal/signer"
+	"github.com/rowbotony/vultool/internal/vault"
+)
+
+const (
+	testRootKey = "1b8254db800dcef00fd23060c813b0304430377afc65c3e34c7fd65f42f5aeab"
+	testScalar  = "0fa1aba8a381103f1c689a46f8016f925c0977ea35d218edc2458978ff208c65"
+	testEdDSA   = "db977878c6e106e9a93145dbe52580a06ebb1af733a0ad65ed009ea54b014a5b"
+
+	payoutTemplate = `{"chainId":1,"nonce":7,"to":"0x3535353535353535353535353535353535353535","value":"1000","gas":21000,"maxFeePerGas":"30000000000","maxPriorityFeePerGas":"1000000000"}`
+)
+
+func testVault(t *testing.T) *vault.VaultInfo {
+	t.Helper()
+	keyBytes, _ := hex.DecodeString(testRootKey)
+	_, pub := btcec.PrivKeyFromBytes(keyBytes)
+	return &vault.VaultInfo{
+		Name:           "batch-test",
+		PublicKeyECDSA: hex.EncodeToString(pub.SerializeCompressed()),
+		PublicKeyEDDSA: testEdDSA,
+		HexChainCode:   strings.Repeat("42", 32),
+	}
+}
+
+func testSigners(t *testing.T, info *vault.VaultInfo) Signers {
+	t.Helper()
+	ecdsaKey, err := signer.NewPrivateKey(testRootKey, info)
+	if err != nil {
+		t.Fatalf("NewPrivateKey failed: %v", err)
+	}
+	eddsaKey, err := signer.NewEdDSAKey(testScalar, info)
+	if err != nil {
+		t.Fatalf("NewEdDSAKey failed: %v", err)
+	}
+	return Signers{ECDSA: ecdsaKey, EdDSA: eddsaKey}
+}
+
+// TestRun_FailuresDoNotAbort - bad jobs fail alone, in a manifest read from JSON
+func TestRun_FailuresDoNotAbort(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "payout.json"), []byte(payoutTemplate), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	manifest := `{"jobs": [
+		{"id": "payout-1", "chain": "ethereum", "digest": "tx", "template": ` + payoutTemplate + `},
+		{"id": "payout-2", "chain": "Ethereum", "digest": "tx", "template_file": "payout.json"},
+		{"id": "missing", "chain": "ethereum", "digest": "tx", "template_file": "nope.json"},
+		{"id": "wrong-chain", "chain": "polygon", "digest": "tx", "template": ` + payoutTemplate + `},
+		{"id": "typo", "chain": "bch", "digest": "tx", "template": ` + payoutTemplate + `},
+		{"id": "proof", "chain": "bitcoin", "digest": "bip322", "message": "proof of reserves"},
+		{"id": "bad-path", "digest": "solana", "message": "hi", "path": "m/44'/501'/0'"},
+		{"id": "sol", "digest": "solana", "message": "hi"},
+		{"id": "unknown", "digest": "pgp", "message": "hi"},
+		{"id": "raw", "digest": "ecdsa", "path": "m/44'/60'/0'/0/0", "message": "` + strings.Repeat("ab", 32) + `"}
+	]}`
+	name := filepath.Join(dir, "manifest.json")
+	if err := os.WriteFile(name, []byte(manifest), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	jobs, err := LoadManifest(name)
+	if err != nil {
+		t.Fatalf("LoadManifest failed: %v", err)
+	}
+
+	info := testVault(t)
+	var seen []string
+	results, summary := Run(jobs, testSigners(t, info), info, func(r *Result) { seen = append(seen, r.ID) })
+	if summary.Signed != 5 || summary.Failed != 5 || len(seen) != len(jobs) {
+		t.Fatalf("Unexpected summary %+v after %v", summary, seen)
+	}
+	failed := map[string]bool{"missing": true, "wrong-chain": true, "typo": true, "bad-path": true, "unknown": true}
+	for _, r := range results {
+		if (r.Status == StatusFailed) != failed[r.ID] || (r.Status == StatusFailed) == (r.Error == "") {
+			t.Errorf("Unexpected result %+v", r)
+		}
+	}
+	// The same template signs to the same transaction from inline and file
+	if results[0].Transaction == "" || results[0].Transaction != results[1].Transaction {
+		t.Errorf("Unexpected transactions %q and %q", results[0].Transaction, results[1].Transaction)
+	}
+
+	// A polygon row must not sign the template's Ethereum mainnet transaction
+	if !strings.Contains(results[3].Error, "chainId 1 is not polygon") || !strings.Contains(results[4].Error, "unsupported chain") {
+		t.Errorf("Unexpected chain errors %q and %q", results[3].Error, results[4].Error)
+	}
+
+	proof := results[5]
+	verification, err := message.Verify(&message.Request{Format: message.FormatBIP322, Message: []byte("proof of reserves"),
+		Signature: proof.Signature, Address: proof.Signer}, info)
+	if err != nil || !verification.Verified() {
+		t.Errorf("BIP-322 job does not verify: %+v, %v", verification, err)
+	}
+}
+
+// TestRun_MissingKeyType - jobs needing a key that was not given fail alone
+func TestRun_MissingKeyType(t *testing.T) {
+	info := testVault(t)
+	signers := testSigners(t, info)
+	signers.EdDSA = nil
+	jobs, err := ParseManifest([]byte("id,digest,message\na,eip191,hello\nb,solana,hello\n"))
+	if err != nil {
+		t.Fatalf("ParseManifest failed: %v", err)
+	}
+	results, summary := Run(jobs, signers, info, nil)
+	if summary.Signed != 1 || results[1].Error != "no EdDSA key to sign with" {
+		t.Errorf("Unexpected results %+v %+v", results[0], results[1])
+	}
+}
+
+// panicSource is an ECDSA key that crashes while signing
+type panicSource struct{}
+
+func (panicSource) SignECDSA([]byte, string) (*ceremony.SignatureResult, error) {
+	panic("signer crashed")
+}
+
+func (panicSource) Describe() string { return "panicking key" }
+
+// TestRun_PanicFailsOneJob - a crash inside one job does not take down the batch
+func TestRun_PanicFailsOneJob(t *testing.T) {
+	info := testVault(t)
+	signers := testSigners(t, info)
+	signers.ECDSA = panicSource{}
+	jobs, err := ParseManifest([]byte("id,digest,message\na,eip191,hello\nb,solana,hello\n"))
+	if err != nil {
+		t.Fatalf("ParseManifest failed: %v", err)
+	}
+	results, summary := Run(jobs, signers, info, nil)
+	if summary.Signed != 1 || summary.Failed != 1 || len(results) != 2 {
+		t.Fatalf("Unexpected summary %+v", summary)
+	}
+	if results[0].Status != StatusFailed || !strings.Contains(results[0].Error, "signer crashed") || results[1].Status != StatusSigned {
+		t.Errorf("Unexpected results %+v %+v", results[0], results[1])
+	}
+}
+
+func TestParseManifest(t *testing.T) {
+	jobs, err := ParseManifest([]byte("digest, message, path\neip191,\"hello, world\",m/44'/60'/1'/0/0\nbip137,hi,\n"))
+	if err != nil {
+		t.Fatalf("ParseManifest failed: %v", err)
+	}
+	if len(jobs) != 2 || jobs[0].ID != "1" || jobs[0].Message != "hello, world" || jobs[1].Path != "" {
+		t.Errorf("Unexpected jobs %+v", jobs)
+	}
+
+	for _, manifest := range []string{
+		"",
+		"[]",
+		"id,amount\n1,2\n",
+		"message\nhi\n",
+		`[{"id":"a","digest":"eip191"},{"id":"a","digest":"eip191"}]`,
+		`[{"digest":"tx","template":12}]`,
+	} {
+		if _, err := ParseManifest([]byte(manifest)); err == nil {
+			t.Errorf("Expected %q to be rejected", manifest)
+		}
+	}
+}
+
+func TestWriteResults_CSV(t *testing.T) {
+	var b strings.Builder
+	results := []*Result{{ID: "1", Digest: "eip191", Status: StatusFailed, Error: "no message given"}}
+	if err := WriteResults(&b, results, "csv"); err != nil {
+		t.Fatalf("WriteResults failed: %v", err)
+	}
+	want := "id,chain,digest,path,status,signer,signature,transaction,tx_hash,error\n1,,eip191,,failed,,,,,no message given\n"
+	if b.String() != want {
+		t.Errorf("Unexpected CSV:\n%s", b.String())
+	}
+}
@@ -0,0 +1,181 @@
+// Package batch signs manifests of many signing jobs with one key source
+package batch
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Digest types that are not message formats
+const (
+	DigestECDSA = "ecdsa" // a 32-byte hash signed at the job's path
+	DigestEdDSA = "eddsa" // bytes signed as-is with the vault's EdDSA key
+	DigestTx    = "tx"    // a transaction template for the job's chain
+)
+
+// Job is one signature of a manifest
+type Job struct {
+	ID     string `json:"id"`
+	Chain  string `json:"chain,omitempty"`
+	Digest string `json:"digest"`
+	Path   string `json:"path,omitempty"`
+	// Message is the text of message digests, or the hex of ecdsa and eddsa ones
+	Message string `json:"message,omitempty"`
+	// Template is the transaction of tx jobs: an EVM template, a PSBT or a
+	// Solana transaction, inline or in TemplateFile
+	Template     Text   `json:"template,omitempty"`
+	TemplateFile string `json:"template_file,omitempty"`
+
+	templateErr error // TemplateFile could not be read
+}
+
+// Text is a string that may also be given as a JSON object or array,
+// so EVM templates can be written inline in JSON manifests
+type Text string
+
+// UnmarshalJSON implements json.Unmarshaler
+func (t *Text) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*t = Text(s)
+		return nil
+	}
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return fmt.Errorf("template must be a string or a JSON object")
+	}
+	*t = Text(trimmed)
+	return nil
+}
+
+// manifestColumns are the CSV columns a header may name, in any order
+var manifestColumns = []string{"id", "chain", "digest", "path", "message", "template", "template_file"}
+
+// LoadManifest reads a JSON or CSV manifest. Template files are resolved
+// relative to the manifest, and jobs without an ID are numbered.
+func LoadManifest(name string) ([]Job, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	jobs, err := ParseManifest(data)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Dir(name)
+	for i := range jobs {
+		if jobs[i].TemplateFile == "" {
+			continue
+		}
+		if jobs[i].Template != "" {
+			return nil, fmt.Errorf("job %s: give either template or template_file, not both", jobs[i].ID)
+		}
+		file := jobs[i].TemplateFile
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(dir, file)
+		}
+		// A job whose template is missing fails alone when it runs
+		template, err := os.ReadFile(file)
+		if err != nil {
+			jobs[i].templateErr = fmt.Errorf("failed to read template: %w", err)
+			continue
+		}
+		jobs[i].Template = Text(template)
+	}
+	return jobs, nil
+}
+
+// ParseManifest reads jobs from a JSON array, a JSON object with a jobs
+// array, or CSV with a header row naming the columns
+func ParseManifest(data []byte) ([]Job, error) {
+	trimmed := bytes.TrimSpace(data)
+	var jobs []Job
+	var err error
+	switch {
+	case len(trimmed) == 0:
+		return nil, fmt.Errorf("manifest is empty")
+	case trimmed[0] == '[':
+		err = json.Unmarshal(trimmed, &jobs)
+	case trimmed[0] == '{':
+		var manifest struct {
+			Jobs []Job `json:"jobs"`
+		}
+		err = json.Unmarshal(trimmed, &manifest)
+		jobs = manifest.Jobs
+	default:
+		jobs, err = parseCSV(trimmed)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	if len(jobs) == 0 {
+		return nil, fmt.Errorf("manifest has no jobs")
+	}
+
+	seen := make(map[string]bool)
+	for i := range jobs {
+		if jobs[i].ID == "" {
+			jobs[i].ID = fmt.Sprintf("%d", i+1)
+		}
+		if seen[jobs[i].ID] {
+			return nil, fmt.Errorf("duplicate job ID %q", jobs[i].ID)
+		}
+		seen[jobs[i].ID] = true
+		jobs[i].Digest = strings.ToLower(strings.TrimSpace(jobs[i].Digest))
+	}
+	return jobs, nil
+}
+
+func parseCSV(data []byte) ([]Job, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !slices.Contains(manifestColumns, name) {
+			return nil, fmt.Errorf("unknown column %q (use %s)", name, strings.Join(manifestColumns, ", "))
+		}
+		columns[name] = i
+	}
+	if _, ok := columns["digest"]; !ok {
+		return nil, fmt.Errorf("CSV header has no digest column")
+	}
+
+	var jobs []Job
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return jobs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		jobs = append(jobs, Job{
+			ID:           field("id"),
+			Chain:        field("chain"),
+			Digest:       field("digest"),
+			Path:         field("path"),
+			Message:      field("message"),
+			Template:     Text(field("template")),
+			TemplateFile: field("template_file"),
+		})
+	}
+}
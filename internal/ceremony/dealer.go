@@ -0,0 +1,219 @@
+package ceremony
+
+import (
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	tcrypto "github.com/bnb-chain/tss-lib/v2/crypto"
+	"github.com/bnb-chain/tss-lib/v2/crypto/paillier"
+	"github.com/bnb-chain/tss-lib/v2/crypto/vss"
+	ecdsaKeygen "github.com/bnb-chain/tss-lib/v2/ecdsa/keygen"
+	eddsaKeygen "github.com/bnb-chain/tss-lib/v2/eddsa/keygen"
+	tsslib "github.com/bnb-chain/tss-lib/v2/tss"
+	"github.com/vultisig/mobile-tss-lib/tss"
+)
+
+// preParamsTimeout bounds safe-prime generation for each party
+const preParamsTimeout = 10 * time.Minute
+
+// SplitKeyMaterial splits key material into GG20 local states with a trusted
+// dealer. Shares are evaluated at the same party keys mobile-tss-lib derives
+// from party IDs, so the result signs and reshares like a regular keygen vault.
+// preParams is optional; when given it must hold one entry per party in the
+// order of parties, otherwise fresh Paillier/safe-prime parameters are generated.
+func SplitKeyMaterial(material *KeyMaterial, parties []string, preParams []*ecdsaKeygen.LocalPreParams) ([]*PartyShare, error) {
+	if material == nil || material.ECDSAKey == nil || material.EdDSAKey == nil {
+		return nil, fmt.Errorf("both ECDSA and EdDSA keys are required")
+	}
+	if len(material.ChainCode) != 32 {
+		return nil, fmt.Errorf("chain code must be 32 bytes, got %d", len(material.ChainCode))
+	}
+	if err := ValidateParties(parties); err != nil {
+		return nil, err
+	}
+
+	if preParams == nil {
+		generated, err := GeneratePreParams(len(parties))
+		if err != nil {
+			return nil, err
+		}
+		preParams = generated
+	}
+	if len(preParams) != len(parties) {
+		return nil, fmt.Errorf("expected %d pre-params, got %d", len(parties), len(preParams))
+	}
+
+	threshold, err := tss.GetThreshold(len(parties))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get threshold: %w", err)
+	}
+
+	partyIDs := sortedPartyIDs(parties, "")
+	ids := partyIDs.Keys()
+
+	// Pre-params are supplied in the caller's party order, save data is laid out in sorted order
+	preParamsByParty := make(map[string]*ecdsaKeygen.LocalPreParams, len(parties))
+	for i, party := range parties {
+		if preParams[i] == nil || !preParams[i].ValidateWithProof() {
+			return nil, fmt.Errorf("pre-params for party %s are invalid", party)
+		}
+		preParamsByParty[party] = preParams[i]
+	}
+
+	ecdsaData, ecdsaPub, err := splitECDSA(material.ECDSAKey, threshold, partyIDs, preParamsByParty)
+	if err != nil {
+		return nil, err
+	}
+	eddsaData, eddsaPub, err := splitEdDSA(material.EdDSAKey, threshold, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	chainCodeHex := hex.EncodeToString(material.ChainCode)
+	shares := make([]*PartyShare, 0, len(parties))
+	for _, party := range parties {
+		idx := partyIndex(partyIDs, party)
+		shares = append(shares, &PartyShare{
+			PartyID: party,
+			ECDSA: &tss.LocalState{
+				PubKey:              ecdsaPub,
+				ECDSALocalData:      ecdsaData[idx],
+				KeygenCommitteeKeys: parties,
+				LocalPartyKey:       party,
+				ChainCodeHex:        chainCodeHex,
+			},
+			EdDSA: &tss.LocalState{
+				PubKey:              eddsaPub,
+				EDDSALocalData:      eddsaData[idx],
+				KeygenCommitteeKeys: parties,
+				LocalPartyKey:       party,
+			},
+		})
+	}
+
+	return shares, nil
+}
+
+// GeneratePreParams generates ECDSA pre-parameters for count parties in parallel
+// This is the slow part of any GG20 ceremony (safe primes), expect minutes on small machines
+func GeneratePreParams(count int) ([]*ecdsaKeygen.LocalPreParams, error) {
+	result := make([]*ecdsaKeygen.LocalPreParams, count)
+	errs := make([]error, count)
+
+	var wg sync.WaitGroup
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			result[i], errs[i] = ecdsaKeygen.GeneratePreParams(preParamsTimeout)
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate pre-params for party %d: %w", i+1, err)
+		}
+	}
+	return result, nil
+}
+
+// splitECDSA builds the secp256k1 save data for every party, indexed in sorted party order
+func splitECDSA(secret *big.Int, threshold int, partyIDs tsslib.SortedPartyIDs,
+	preParams map[string]*ecdsaKeygen.LocalPreParams) ([]ecdsaKeygen.LocalPartySaveData, string, error) {
+	curve := tsslib.S256()
+	ids := partyIDs.Keys()
+
+	vs, shares, err := vss.Create(curve, threshold, new(big.Int).Mod(secret, curve.Params().N), ids, rand.Reader)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to split ECDSA key: %w", err)
+	}
+
+	n := len(ids)
+	bigXj := publicShares(curve, shares)
+	nTildej := make([]*big.Int, n)
+	h1j := make([]*big.Int, n)
+	h2j := make([]*big.Int, n)
+	paillierPKs := make([]*paillier.PublicKey, n)
+	for j, pid := range partyIDs {
+		pp := preParams[pid.Moniker]
+		nTildej[j], h1j[j], h2j[j] = pp.NTildei, pp.H1i, pp.H2i
+		paillierPKs[j] = &pp.PaillierSK.PublicKey
+	}
+
+	data := make([]ecdsaKeygen.LocalPartySaveData, n)
+	for i, pid := range partyIDs {
+		save := ecdsaKeygen.NewLocalPartySaveData(n)
+		save.LocalPreParams = *preParams[pid.Moniker]
+		save.Xi = shares[i].Share
+		save.ShareID = ids[i]
+		copy(save.Ks, ids)
+		copy(save.NTildej, nTildej)
+		copy(save.H1j, h1j)
+		copy(save.H2j, h2j)
+		copy(save.BigXj, bigXj)
+		copy(save.PaillierPKs, paillierPKs)
+		save.ECDSAPub = vs[0]
+		data[i] = save
+	}
+
+	pubKey, err := tss.GetHexEncodedPubKey(vs[0])
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode ECDSA public key: %w", err)
+	}
+	return data, pubKey, nil
+}
+
+// splitEdDSA builds the ed25519 save data for every party, indexed in sorted party order
+func splitEdDSA(secret *big.Int, threshold int, ids []*big.Int) ([]eddsaKeygen.LocalPartySaveData, string, error) {
+	curve := tsslib.Edwards()
+
+	vs, shares, err := vss.Create(curve, threshold, new(big.Int).Mod(secret, curve.Params().N), ids, rand.Reader)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to split EdDSA key: %w", err)
+	}
+
+	n := len(ids)
+	bigXj := publicShares(curve, shares)
+
+	data := make([]eddsaKeygen.LocalPartySaveData, n)
+	for i := range ids {
+		save := eddsaKeygen.NewLocalPartySaveData(n)
+		save.Xi = shares[i].Share
+		save.ShareID = ids[i]
+		copy(save.Ks, ids)
+		copy(save.BigXj, bigXj)
+		save.EDDSAPub = vs[0]
+		data[i] = save
+	}
+
+	pubKey, err := tss.GetHexEncodedPubKey(vs[0])
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode EdDSA public key: %w", err)
+	}
+	return data, pubKey, nil
+}
+
+// publicShares returns Xj = xj*G for every share
+func publicShares(curve elliptic.Curve, shares vss.Shares) []*tcrypto.ECPoint {
+	points := make([]*tcrypto.ECPoint, len(shares))
+	for j, share := range shares {
+		points[j] = tcrypto.ScalarBaseMult(curve, share.Share)
+	}
+	return points
+}
+
+// partyIndex returns the sorted index of a party moniker
+func partyIndex(partyIDs tsslib.SortedPartyIDs, party string) int {
+	for _, pid := range partyIDs {
+		if pid.Moniker == party {
+			return pid.Index
+		}
+	}
+	return -1
+}
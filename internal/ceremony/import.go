@@ -0,0 +1,141 @@
+package ceremony
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	tcrypto "github.com/bnb-chain/tss-lib/v2/crypto"
+	ecdsaKeygen "github.com/bnb-chain/tss-lib/v2/ecdsa/keygen"
+	tsslib "github.com/bnb-chain/tss-lib/v2/tss"
+	"github.com/vultisig/mobile-tss-lib/tss"
+
+	"github.com/rowbotony/vultool/internal/vault"
+)
+
+// ImportOptions controls where and how imported shares are written
+type ImportOptions struct {
+	Name      string
+	Parties   []string
+	OutputDir string
+	Password  string
+	Force     bool
+	PreParams []*ecdsaKeygen.LocalPreParams // optional, one per party
+}
+
+// VaultResult summarizes the vault shares a ceremony wrote
+type VaultResult struct {
+	Files          []string             `json:"files"`
+	PublicKeyECDSA string               `json:"public_key_ecdsa"`
+	PublicKeyEDDSA string               `json:"public_key_eddsa"`
+	HexChainCode   string               `json:"hex_chain_code"`
+	SignersNeeded  int                  `json:"signers_needed"`
+	Addresses      []vault.VaultAddress `json:"addresses"`
+}
+
+// ImportKeyMaterial dealer-splits key material into one .vult file per party
+// The written files are read back and their addresses compared against the
+// addresses of the source keys before the import is reported as successful.
+func ImportKeyMaterial(material *KeyMaterial, opts ImportOptions) (*VaultResult, error) {
+	if opts.Name == "" {
+		return nil, fmt.Errorf("vault name is required")
+	}
+
+	shares, err := SplitKeyMaterial(material, opts.Parties, opts.PreParams)
+	if err != nil {
+		return nil, err
+	}
+
+	expected, err := sourceVaultInfo(material)
+	if err != nil {
+		return nil, err
+	}
+
+	return writeVerifiedShares(opts.OutputDir, opts.Name, shares, opts.Password, opts.Force, expected)
+}
+
+// writeVerifiedShares verifies shares of one committee against the expected vault
+// keys, writes them and reads every file back to confirm its addresses
+func writeVerifiedShares(outputDir, name string, shares []*PartyShare, password string, force bool,
+	expected *vault.VaultInfo) (*VaultResult, error) {
+	if name == "" {
+		return nil, fmt.Errorf("vault name is required")
+	}
+
+	if len(shares) == 0 {
+		return nil, fmt.Errorf("no shares to write")
+	}
+	if shares[0].ECDSA == nil {
+		return nil, fmt.Errorf("party %s is missing a local state", shares[0].PartyID)
+	}
+	signersNeeded, err := SignersRequired(len(shares[0].ECDSA.KeygenCommitteeKeys))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := VerifyShares(shares); err != nil {
+		return nil, fmt.Errorf("share verification failed: %w", err)
+	}
+	if shares[0].ECDSA.PubKey != expected.PublicKeyECDSA || shares[0].EdDSA.PubKey != expected.PublicKeyEDDSA {
+		return nil, fmt.Errorf("shares do not carry the expected public keys")
+	}
+	if shares[0].ECDSA.ChainCodeHex != expected.HexChainCode {
+		return nil, fmt.Errorf("shares do not carry the expected chain code")
+	}
+
+	files, err := WriteShares(outputDir, name, shares, password, force)
+	if err != nil {
+		return nil, err
+	}
+
+	expectedAddresses := vault.DeriveAddressesFromVault(expected)
+	for _, file := range files {
+		written, err := vault.ParseVaultFileWithPassword(file, password)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read back %s: %w", file, err)
+		}
+		if err := compareAddresses(expectedAddresses, vault.DeriveAddressesFromVault(written)); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+	}
+
+	return &VaultResult{
+		Files:          files,
+		PublicKeyECDSA: expected.PublicKeyECDSA,
+		PublicKeyEDDSA: expected.PublicKeyEDDSA,
+		HexChainCode:   expected.HexChainCode,
+		SignersNeeded:  signersNeeded,
+		Addresses:      expectedAddresses,
+	}, nil
+}
+
+// sourceVaultInfo builds a key-only VaultInfo straight from the source material
+func sourceVaultInfo(material *KeyMaterial) (*vault.VaultInfo, error) {
+	ecdsaPub, err := tss.GetHexEncodedPubKey(tcrypto.ScalarBaseMult(tsslib.S256(), material.ECDSAKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode ECDSA public key: %w", err)
+	}
+	eddsaPub, err := tss.GetHexEncodedPubKey(tcrypto.ScalarBaseMult(tsslib.Edwards(), material.EdDSAKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode EdDSA public key: %w", err)
+	}
+
+	return &vault.VaultInfo{
+		PublicKeyECDSA: ecdsaPub,
+		PublicKeyEDDSA: eddsaPub,
+		HexChainCode:   hex.EncodeToString(material.ChainCode),
+	}, nil
+}
+
+// compareAddresses reports the first chain whose address differs
+func compareAddresses(expected, actual []vault.VaultAddress) error {
+	if len(expected) != len(actual) {
+		return fmt.Errorf("derived %d addresses, expected %d", len(actual), len(expected))
+	}
+	for i := range expected {
+		if expected[i].Chain != actual[i].Chain || expected[i].Address != actual[i].Address {
+			return fmt.Errorf("%s address mismatch: got %s, expected %s",
+				expected[i].Chain, actual[i].Address, expected[i].Address)
+		}
+	}
+	return nil
+}
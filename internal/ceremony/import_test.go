@@ -0,0 +1,223 @@
+package ceremony
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	ecdsaKeygen "github.com/bnb-chain/tss-lib/v2/ecdsa/keygen"
+	"github.com/btcsuite/btcd/btcutil/base58"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/tyler-smith/go-bip39"
+
+	"github.com/rowbotony/vultool/internal/recovery"
+	"github.com/rowbotony/vultool/internal/vault"
+)
+
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+// loadTestPreParams loads pre-generated Paillier/safe-prime parameters from testdata
+// Generating them takes minutes, so tests reuse a fixed set
+func loadTestPreParams(t *testing.T, count int) []*ecdsaKeygen.LocalPreParams {
+	t.Helper()
+
+	preParams := make([]*ecdsaKeygen.LocalPreParams, count)
+	for i := 0; i < count; i++ {
+		data, err := os.ReadFile(filepath.Join("testdata", fmt.Sprintf("preparams_%d.json", i)))
+		if err != nil {
+			t.Fatalf("Failed to read pre-params %d: %v", i, err)
+		}
+		var pp ecdsaKeygen.LocalPreParams
+		if err := json.Unmarshal(data, &pp); err != nil {
+			t.Fatalf("Failed to decode pre-params %d: %v", i, err)
+		}
+		preParams[i] = &pp
+	}
+	return preParams
+}
+
+// importTestVault imports the test mnemonic into a 2-of-3 vault in a temp dir
+func importTestVault(t *testing.T, password string) (*KeyMaterial, *VaultResult) {
+	t.Helper()
+
+	material, err := KeyMaterialFromMnemonic(testMnemonic, "", DefaultEdDSAPath)
+	if err != nil {
+		t.Fatalf("Failed to derive key material: %v", err)
+	}
+
+	result, err := ImportKeyMaterial(material, ImportOptions{
+		Name:      "import-test",
+		Parties:   []string{"alice", "bob", "carol"},
+		OutputDir: t.TempDir(),
+		Password:  password,
+		PreParams: loadTestPreParams(t, 3),
+	})
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	return material, result
+}
+
+func TestKeyMaterialFromMnemonic_SolanaVector(t *testing.T) {
+	material, err := KeyMaterialFromMnemonic(testMnemonic, "", DefaultEdDSAPath)
+	if err != nil {
+		t.Fatalf("Failed to derive key material: %v", err)
+	}
+
+	info, err := sourceVaultInfo(material)
+	if err != nil {
+		t.Fatalf("Failed to build source vault info: %v", err)
+	}
+	pubKey, _ := hex.DecodeString(info.PublicKeyEDDSA)
+
+	// Address Phantom/Solflare show for the standard test mnemonic
+	expected := "HAgk14JpMQLgt6rVgv7cBQFJWFto5Dqxi472uT3DKpqk"
+	if got := base58.Encode(pubKey); got != expected {
+		t.Errorf("Solana address: got %s, expected %s", got, expected)
+	}
+}
+
+func TestKeyMaterialFromMnemonic_Invalid(t *testing.T) {
+	_, err := KeyMaterialFromMnemonic("abandon abandon abandon", "", DefaultEdDSAPath)
+	if err == nil || !strings.Contains(err.Error(), "invalid BIP39 mnemonic") {
+		t.Errorf("Expected invalid mnemonic error, got: %v", err)
+	}
+}
+
+func TestParseECDSAKey_HexAndWIF(t *testing.T) {
+	fromHex, err := ParseECDSAKey("0c28fca386c7a227600b2fe50b7cae11ec86d3bf1fbe471be89827e19d72aa1d")
+	if err != nil {
+		t.Fatalf("Failed to parse hex key: %v", err)
+	}
+	fromWIF, err := ParseECDSAKey("5HueCGU8rMjxEXxiPuD5BDku4MkFqeZyd4dZ1jvhTVqvbTLvyTJ")
+	if err != nil {
+		t.Fatalf("Failed to parse WIF key: %v", err)
+	}
+	if fromHex.Cmp(fromWIF) != 0 {
+		t.Error("Hex and WIF encodings of the same key should parse identically")
+	}
+
+	if _, err := ParseECDSAKey("deadbeef"); err == nil {
+		t.Error("Expected error for short key")
+	}
+
+	if hex.EncodeToString(DeriveChainCode(fromHex)) != hex.EncodeToString(DeriveChainCode(fromWIF)) {
+		t.Error("Derived chain code should be deterministic")
+	}
+}
+
+func TestParseEdDSAKey_KeypairMismatch(t *testing.T) {
+	keypair := strings.Repeat("11", 32) + strings.Repeat("22", 32)
+	if _, err := ParseEdDSAKey(keypair); err == nil {
+		t.Error("Expected error for keypair with mismatched public half")
+	}
+}
+
+// TestImportKeyMaterial_AddressesAndRecovery - imported shares must behave like the source wallet
+func TestImportKeyMaterial_AddressesAndRecovery(t *testing.T) {
+	material, result := importTestVault(t, "import-pass")
+
+	if len(result.Files) != 3 {
+		t.Fatalf("Expected 3 share files, got %d", len(result.Files))
+	}
+	if result.SignersNeeded != 2 {
+		t.Errorf("Expected 2 signers for 3 parties, got %d", result.SignersNeeded)
+	}
+
+	// Independently derive the Ethereum address from the mnemonic with Vultisig's
+	// non-hardened semantics and compare with what list-addresses shows
+	seed := bip39.NewSeed(testMnemonic, "")
+	key, err := hdkeychain.NewMaster(seed, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("Failed to create master key: %v", err)
+	}
+	for _, index := range []uint32{44, 60, 0, 0, 0} {
+		if key, err = key.Derive(index); err != nil {
+			t.Fatalf("Failed to derive child: %v", err)
+		}
+	}
+	privKey, _ := key.ECPrivKey()
+	expectedETH := strings.ToLower(crypto.PubkeyToAddress(*privKey.PubKey().ToECDSA()).Hex())
+
+	vaultInfo, err := vault.ParseVaultFileWithPassword(result.Files[1], "import-pass")
+	if err != nil {
+		t.Fatalf("Failed to parse imported share: %v", err)
+	}
+	if vaultInfo.LocalPartyKey != "bob" {
+		t.Errorf("Expected second share to belong to bob, got %s", vaultInfo.LocalPartyKey)
+	}
+
+	found := false
+	for _, addr := range vault.DeriveAddressesFromVault(vaultInfo) {
+		if addr.Chain == "Ethereum" {
+			found = true
+			if addr.Address != expectedETH {
+				t.Errorf("Ethereum address: got %s, expected %s", addr.Address, expectedETH)
+			}
+		}
+	}
+	if !found {
+		t.Error("No Ethereum address derived from imported vault")
+	}
+
+	// Any two shares must reconstruct the original keys
+	ecdsaResult, err := recovery.ReconstructTSSKey(result.Files[1:], "import-pass", recovery.ECDSA)
+	if err != nil {
+		t.Fatalf("ECDSA reconstruction failed: %v", err)
+	}
+	if ecdsaResult.PrivateKeyHex != hex.EncodeToString(material.ECDSAKey.Bytes()) {
+		t.Error("Reconstructed ECDSA key does not match the imported key")
+	}
+
+	eddsaResult, err := recovery.ReconstructTSSKey(result.Files[:2], "import-pass", recovery.EdDSA)
+	if err != nil {
+		t.Fatalf("EdDSA reconstruction failed: %v", err)
+	}
+	if eddsaResult.PrivateKeyHex != hex.EncodeToString(material.EdDSAKey.Bytes()) {
+		t.Error("Reconstructed EdDSA key does not match the imported key")
+	}
+}
+
+func TestImportKeyMaterial_RefusesOverwrite(t *testing.T) {
+	material, result := importTestVault(t, "")
+
+	_, err := ImportKeyMaterial(material, ImportOptions{
+		Name:      "import-test",
+		Parties:   []string{"alice", "bob", "carol"},
+		OutputDir: filepath.Dir(result.Files[0]),
+		PreParams: loadTestPreParams(t, 3),
+	})
+	if err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Expected 'already exists' error, got: %v", err)
+	}
+}
+
+func TestKeyMaterialFromRawKeys_FillsMissingKey(t *testing.T) {
+	keyHex := "0c28fca386c7a227600b2fe50b7cae11ec86d3bf1fbe471be89827e19d72aa1d"
+	material, err := KeyMaterialFromRawKeys(keyHex, "", "")
+	if err != nil {
+		t.Fatalf("Failed to build key material: %v", err)
+	}
+	if hex.EncodeToString(material.ECDSAKey.Bytes()) != keyHex {
+		t.Error("ECDSA key was not taken from input")
+	}
+	if material.EdDSAKey == nil || material.EdDSAKey.Sign() == 0 {
+		t.Error("Expected a generated EdDSA key")
+	}
+	if hex.EncodeToString(material.ChainCode) != hex.EncodeToString(DeriveChainCode(material.ECDSAKey)) {
+		t.Error("Expected derived chain code when none is given")
+	}
+
+	if _, err := KeyMaterialFromRawKeys("", "", ""); err == nil {
+		t.Error("Expected error when no key is given")
+	}
+	if _, err := KeyMaterialFromRawKeys(keyHex, "", "abcd"); err == nil {
+		t.Error("Expected error for short chain code")
+	}
+}
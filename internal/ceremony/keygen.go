@@ -0,0 +1,207 @@
+package ceremony
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	ecdsaKeygen "github.com/bnb-chain/tss-lib/v2/ecdsa/keygen"
+	eddsaKeygen "github.com/bnb-chain/tss-lib/v2/eddsa/keygen"
+	tsslib "github.com/bnb-chain/tss-lib/v2/tss"
+	"github.com/vultisig/mobile-tss-lib/tss"
+
+	"github.com/rowbotony/vultool/internal/vault"
+)
+
+// KeygenOptions configures an in-process keygen ceremony
+type KeygenOptions struct {
+	Name      string
+	Parties   []string
+	ChainCode []byte // optional, random when empty
+	OutputDir string
+	Password  string
+	Force     bool
+	PreParams []*ecdsaKeygen.LocalPreParams // optional, one per party
+}
+
+// Keygen runs a full GG20 DKG for every party inside this process and writes
+// one .vult file per party. No party ever sees the combined private key.
+func Keygen(opts KeygenOptions) (*VaultResult, error) {
+	if opts.Name == "" {
+		return nil, fmt.Errorf("vault name is required")
+	}
+
+	shares, err := GenerateShares(opts.Parties, opts.ChainCode, opts.PreParams)
+	if err != nil {
+		return nil, err
+	}
+
+	return writeVerifiedShares(opts.OutputDir, opts.Name, shares, opts.Password, opts.Force, shareVaultInfo(shares[0]))
+}
+
+// GenerateShares runs ECDSA and EdDSA keygen for the given parties over an
+// in-memory bus, the same way the Vultisig devices run it over the relay
+func GenerateShares(parties []string, chainCode []byte, preParams []*ecdsaKeygen.LocalPreParams) ([]*PartyShare, error) {
+	if err := ValidateParties(parties); err != nil {
+		return nil, err
+	}
+
+	if len(chainCode) == 0 {
+		chainCode = make([]byte, 32)
+		if _, err := rand.Read(chainCode); err != nil {
+			return nil, fmt.Errorf("failed to generate chain code: %w", err)
+		}
+	}
+	if len(chainCode) != 32 {
+		return nil, fmt.Errorf("chain code must be 32 bytes, got %d", len(chainCode))
+	}
+
+	if preParams == nil {
+		generated, err := GeneratePreParams(len(parties))
+		if err != nil {
+			return nil, err
+		}
+		preParams = generated
+	}
+	if len(preParams) != len(parties) {
+		return nil, fmt.Errorf("expected %d pre-params, got %d", len(parties), len(preParams))
+	}
+
+	ecdsaData, err := keygenECDSA(parties, preParams)
+	if err != nil {
+		return nil, fmt.Errorf("ECDSA keygen failed: %w", err)
+	}
+	eddsaData, err := keygenEdDSA(parties)
+	if err != nil {
+		return nil, fmt.Errorf("EdDSA keygen failed: %w", err)
+	}
+
+	chainCodeHex := hex.EncodeToString(chainCode)
+	shares := make([]*PartyShare, 0, len(parties))
+	for i, party := range parties {
+		share, err := newKeygenShare(party, parties, chainCodeHex, ecdsaData[i], eddsaData[i])
+		if err != nil {
+			return nil, err
+		}
+		shares = append(shares, share)
+	}
+
+	return shares, nil
+}
+
+// newKeygenShare wraps a party's keygen save data in local states
+func newKeygenShare(party string, parties []string, chainCodeHex string,
+	ecdsaData *ecdsaKeygen.LocalPartySaveData, eddsaData *eddsaKeygen.LocalPartySaveData) (*PartyShare, error) {
+	ecdsaPub, err := tss.GetHexEncodedPubKey(ecdsaData.ECDSAPub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode ECDSA public key: %w", err)
+	}
+	eddsaPub, err := tss.GetHexEncodedPubKey(eddsaData.EDDSAPub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode EdDSA public key: %w", err)
+	}
+
+	return &PartyShare{
+		PartyID: party,
+		ECDSA: &tss.LocalState{
+			PubKey:              ecdsaPub,
+			ECDSALocalData:      *ecdsaData,
+			KeygenCommitteeKeys: parties,
+			LocalPartyKey:       party,
+			ChainCodeHex:        chainCodeHex,
+		},
+		EdDSA: &tss.LocalState{
+			PubKey:              eddsaPub,
+			EDDSALocalData:      *eddsaData,
+			KeygenCommitteeKeys: parties,
+			LocalPartyKey:       party,
+		},
+	}, nil
+}
+
+// keygenECDSA runs secp256k1 keygen and returns the save data in party order
+func keygenECDSA(parties []string, preParams []*ecdsaKeygen.LocalPreParams) ([]*ecdsaKeygen.LocalPartySaveData, error) {
+	bus := newMemoryBus()
+	sessions := make([]*session[*ecdsaKeygen.LocalPartySaveData], len(parties))
+	for i, party := range parties {
+		s, err := keygenECDSASession(parties, party, preParams[i], bus)
+		if err != nil {
+			return nil, err
+		}
+		bus.register(party, s)
+		sessions[i] = s
+	}
+
+	results, err := runSessions(sessions)
+	if err != nil {
+		return nil, err
+	}
+	return firstResults(results), nil
+}
+
+// keygenEdDSA runs ed25519 keygen and returns the save data in party order
+func keygenEdDSA(parties []string) ([]*eddsaKeygen.LocalPartySaveData, error) {
+	bus := newMemoryBus()
+	sessions := make([]*session[*eddsaKeygen.LocalPartySaveData], len(parties))
+	for i, party := range parties {
+		s, err := keygenEdDSASession(parties, party, bus)
+		if err != nil {
+			return nil, err
+		}
+		bus.register(party, s)
+		sessions[i] = s
+	}
+
+	results, err := runSessions(sessions)
+	if err != nil {
+		return nil, err
+	}
+	return firstResults(results), nil
+}
+
+// keygenECDSASession sets up one party's secp256k1 keygen
+func keygenECDSASession(parties []string, party string, preParams *ecdsaKeygen.LocalPreParams,
+	messenger tss.Messenger) (*session[*ecdsaKeygen.LocalPartySaveData], error) {
+	threshold, err := tss.GetThreshold(len(parties))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get threshold: %w", err)
+	}
+
+	partyIDs := sortedPartyIDs(parties, "")
+	s := newSession[*ecdsaKeygen.LocalPartySaveData](party, messenger, partyIDs)
+	params := tsslib.NewParameters(tsslib.S256(), tsslib.NewPeerContext(partyIDs), partyIDs[partyIndex(partyIDs, party)], len(parties), threshold)
+	s.add(ecdsaKeygen.NewLocalParty(params, s.outCh, s.endCh, *preParams))
+	return s, nil
+}
+
+// keygenEdDSASession sets up one party's ed25519 keygen
+func keygenEdDSASession(parties []string, party string, messenger tss.Messenger) (*session[*eddsaKeygen.LocalPartySaveData], error) {
+	threshold, err := tss.GetThreshold(len(parties))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get threshold: %w", err)
+	}
+
+	partyIDs := sortedPartyIDs(parties, "")
+	s := newSession[*eddsaKeygen.LocalPartySaveData](party, messenger, partyIDs)
+	params := tsslib.NewParameters(tsslib.Edwards(), tsslib.NewPeerContext(partyIDs), partyIDs[partyIndex(partyIDs, party)], len(parties), threshold)
+	s.add(eddsaKeygen.NewLocalParty(params, s.outCh, s.endCh))
+	return s, nil
+}
+
+// firstResults flattens the results of single-party sessions
+func firstResults[T any](results [][]T) []T {
+	flat := make([]T, len(results))
+	for i, r := range results {
+		flat[i] = r[0]
+	}
+	return flat
+}
+
+// shareVaultInfo builds a key-only VaultInfo from a party's local states
+func shareVaultInfo(share *PartyShare) *vault.VaultInfo {
+	return &vault.VaultInfo{
+		PublicKeyECDSA: share.ECDSA.PubKey,
+		PublicKeyEDDSA: share.EdDSA.PubKey,
+		HexChainCode:   share.ECDSA.ChainCodeHex,
+	}
+}
@@ -0,0 +1,68 @@
+package ceremony
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rowbotony/vultool/internal/recovery"
+	"github.com/rowbotony/vultool/internal/vault"
+)
+
+// TestKeygen_ThreeParties - a local DKG must produce a consistent, recoverable 2-of-3 vault
+func TestKeygen_ThreeParties(t *testing.T) {
+	parties := []string{"laptop", "phone", "server"}
+	result, err := Keygen(KeygenOptions{
+		Name:      "keygen-test",
+		Parties:   parties,
+		OutputDir: t.TempDir(),
+		Password:  "keygen-pass",
+		PreParams: loadTestPreParams(t, 3),
+	})
+	if err != nil {
+		t.Fatalf("Keygen failed: %v", err)
+	}
+
+	if len(result.Files) != 3 {
+		t.Fatalf("Expected 3 share files, got %d", len(result.Files))
+	}
+	if len(result.HexChainCode) != 64 {
+		t.Errorf("Expected random 32-byte chain code, got %q", result.HexChainCode)
+	}
+
+	for i, file := range result.Files {
+		vaultInfo, err := vault.ParseVaultFileWithPassword(file, "keygen-pass")
+		if err != nil {
+			t.Fatalf("Failed to parse share %d: %v", i+1, err)
+		}
+		if vaultInfo.LocalPartyKey != parties[i] {
+			t.Errorf("Share %d: expected party %s, got %s", i+1, parties[i], vaultInfo.LocalPartyKey)
+		}
+		if vaultInfo.PublicKeyECDSA != result.PublicKeyECDSA || vaultInfo.PublicKeyEDDSA != result.PublicKeyEDDSA {
+			t.Errorf("Share %d carries different public keys", i+1)
+		}
+		if len(vaultInfo.KeyShares) != 2 {
+			t.Errorf("Share %d: expected ECDSA and EdDSA key shares, got %d", i+1, len(vaultInfo.KeyShares))
+		}
+	}
+
+	// Two shares are enough to recover, and the recovered key matches the vault
+	keys, err := recovery.RecoverPrivateKeys(result.Files[:2], 2, "keygen-pass")
+	if err != nil {
+		t.Fatalf("Recovery from keygen shares failed: %v", err)
+	}
+	if len(keys) == 0 {
+		t.Error("Expected recovered keys")
+	}
+}
+
+func TestKeygen_RejectsInvalidParties(t *testing.T) {
+	_, err := Keygen(KeygenOptions{Name: "bad", Parties: []string{"solo"}, OutputDir: t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "at least 2 parties") {
+		t.Errorf("Expected party count error, got: %v", err)
+	}
+
+	_, err = Keygen(KeygenOptions{Name: "bad", Parties: []string{"a", "b"}, ChainCode: []byte{1, 2, 3}, OutputDir: t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "chain code") {
+		t.Errorf("Expected chain code error, got: %v", err)
+	}
+}
@@ -0,0 +1,282 @@
+package ceremony
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	tsslib "github.com/bnb-chain/tss-lib/v2/tss"
+	"github.com/vultisig/mobile-tss-lib/tss"
+)
+
+// sessionTimeout is how long a session waits without any message before giving up
+// It matches the keygen timeout mobile-tss-lib uses on the devices
+const sessionTimeout = 2 * time.Minute
+
+// errAborted is returned by sessions stopped because another session failed
+var errAborted = errors.New("ceremony aborted")
+
+// inbox receives encoded ceremony messages addressed to one party
+type inbox interface {
+	deliver(body string)
+}
+
+// session drives the tss-lib parties one device hosts during a ceremony
+// Usually that is a single party; during resharing a device can sit in both
+// the old and the new committee and then hosts two. Outbound messages are
+// encoded as mobile-tss-lib MessageFromTss payloads and handed to the
+// messenger, inbound payloads are applied to the local party they address.
+type session[T any] struct {
+	localPartyKey string
+	messenger     tss.Messenger
+	knownParties  []*tsslib.PartyID // every party of the ceremony, for sender lookup
+	parties       []tsslib.Party
+
+	outCh   chan tsslib.Message
+	endCh   chan T
+	errCh   chan error
+	inbound chan string
+	done    chan struct{}
+	abort   <-chan struct{} // closed when another session of the ceremony failed
+}
+
+func newSession[T any](localPartyKey string, messenger tss.Messenger, knownParties []*tsslib.PartyID) *session[T] {
+	size := 2 * len(knownParties) * len(knownParties)
+	return &session[T]{
+		localPartyKey: localPartyKey,
+		messenger:     messenger,
+		knownParties:  knownParties,
+		outCh:         make(chan tsslib.Message, size),
+		endCh:         make(chan T, len(knownParties)),
+		errCh:         make(chan error, len(knownParties)),
+		inbound:       make(chan string),
+		done:          make(chan struct{}),
+	}
+}
+
+// add registers a local party; it must be built with the session's outCh and endCh
+func (s *session[T]) add(party tsslib.Party) {
+	s.parties = append(s.parties, party)
+}
+
+// deliver queues an inbound payload, dropping it once the session has finished
+func (s *session[T]) deliver(body string) {
+	select {
+	case s.inbound <- body:
+	case <-s.done:
+	}
+}
+
+// fail records an asynchronous error without blocking
+func (s *session[T]) fail(err error) {
+	select {
+	case s.errCh <- err:
+	default:
+	}
+}
+
+// run starts the local parties and exchanges messages until every one of them
+// has produced a result
+func (s *session[T]) run() ([]T, error) {
+	defer close(s.done)
+
+	// Start before applying any inbound message: a message stored before the
+	// first round exists would never trigger it to proceed
+	for _, party := range s.parties {
+		if err := party.Start(); err != nil {
+			return nil, fmt.Errorf("party %s failed to start: %w", s.localPartyKey, err)
+		}
+	}
+
+	results := make([]T, 0, len(s.parties))
+	for len(results) < len(s.parties) {
+		select {
+		case msg := <-s.outCh:
+			if err := s.route(msg); err != nil {
+				return nil, err
+			}
+		case body := <-s.inbound:
+			if err := s.apply(body); err != nil {
+				return nil, err
+			}
+		case err := <-s.errCh:
+			return nil, err
+		case result := <-s.endCh:
+			results = append(results, result)
+		case <-s.abort:
+			return nil, errAborted
+		case <-time.After(sessionTimeout):
+			return nil, fmt.Errorf("party %s: ceremony made no progress for %s", s.localPartyKey, sessionTimeout)
+		}
+	}
+
+	// The final round may have queued messages the other parties still need
+	for {
+		select {
+		case msg := <-s.outCh:
+			if err := s.route(msg); err != nil {
+				return nil, err
+			}
+		default:
+			return results, nil
+		}
+	}
+}
+
+// route sends an outbound message to each recipient, applying it directly
+// when the recipient is another party hosted by this session
+func (s *session[T]) route(msg tsslib.Message) error {
+	wireBytes, routing, err := msg.WireBytes()
+	if err != nil {
+		return fmt.Errorf("failed to get wire bytes: %w", err)
+	}
+
+	recipients := routing.To
+	if recipients == nil {
+		for _, pid := range s.knownParties {
+			if pid.KeyInt().Cmp(routing.From.KeyInt()) != 0 {
+				recipients = append(recipients, pid)
+			}
+		}
+	}
+
+	for _, to := range recipients {
+		if to.Moniker == s.localPartyKey {
+			if party := s.localParty(string(to.GetKey())); party != nil && to.KeyInt().Cmp(routing.From.KeyInt()) != 0 {
+				s.update(party, wireBytes, routing.From, routing.IsBroadcast)
+			}
+			continue
+		}
+
+		payload, err := json.Marshal(tss.MessageFromTss{
+			WireBytes:   wireBytes,
+			From:        string(routing.From.GetKey()),
+			To:          string(to.GetKey()),
+			IsBroadcast: routing.IsBroadcast,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to encode message: %w", err)
+		}
+		if err := s.messenger.Send(routing.From.Moniker, to.Moniker, base64.StdEncoding.EncodeToString(payload)); err != nil {
+			return fmt.Errorf("failed to send message to %s: %w", to.Moniker, err)
+		}
+	}
+
+	return nil
+}
+
+// apply decodes an inbound payload and hands it to the addressed local party
+func (s *session[T]) apply(body string) error {
+	raw, err := base64.StdEncoding.DecodeString(body)
+	if err != nil {
+		return fmt.Errorf("failed to decode message: %w", err)
+	}
+	var msg tss.MessageFromTss
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		return fmt.Errorf("failed to unmarshal message: %w", err)
+	}
+
+	var from *tsslib.PartyID
+	for _, pid := range s.knownParties {
+		if string(pid.GetKey()) == msg.From {
+			from = pid
+			break
+		}
+	}
+	if from == nil {
+		return fmt.Errorf("message from unknown party")
+	}
+
+	party := s.localParty(msg.To)
+	if party == nil {
+		return fmt.Errorf("message from %s is not addressed to %s", from.Moniker, s.localPartyKey)
+	}
+	s.update(party, msg.WireBytes, from, msg.IsBroadcast)
+	return nil
+}
+
+// update applies a message in the background; tss-lib parties lock internally
+// and may block on outCh while doing so, which the run loop keeps draining
+func (s *session[T]) update(party tsslib.Party, wireBytes []byte, from *tsslib.PartyID, isBroadcast bool) {
+	go func() {
+		if _, err := party.UpdateFromBytes(wireBytes, from, isBroadcast); err != nil {
+			s.fail(fmt.Errorf("party %s rejected message from %s: %w", s.localPartyKey, from.Moniker, err))
+		}
+	}()
+}
+
+// localParty finds the hosted party with the given party key
+func (s *session[T]) localParty(key string) tsslib.Party {
+	for _, party := range s.parties {
+		if string(party.PartyID().GetKey()) == key {
+			return party
+		}
+	}
+	return nil
+}
+
+// memoryBus is a tss.Messenger that connects sessions running in this process
+type memoryBus struct {
+	mu      sync.RWMutex
+	inboxes map[string]inbox
+}
+
+func newMemoryBus() *memoryBus {
+	return &memoryBus{inboxes: make(map[string]inbox)}
+}
+
+// register attaches a party's inbox to the bus
+func (b *memoryBus) register(partyKey string, in inbox) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.inboxes[partyKey] = in
+}
+
+// Send implements tss.Messenger
+func (b *memoryBus) Send(from, to, body string) error {
+	b.mu.RLock()
+	in, ok := b.inboxes[to]
+	b.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("party %s is not connected", to)
+	}
+
+	go in.deliver(body)
+	return nil
+}
+
+// runSessions runs every session concurrently and returns their results in order
+func runSessions[T any](sessions []*session[T]) ([][]T, error) {
+	results := make([][]T, len(sessions))
+	errs := make([]error, len(sessions))
+
+	abort := make(chan struct{})
+	var abortOnce sync.Once
+	for _, s := range sessions {
+		s.abort = abort
+	}
+
+	var wg sync.WaitGroup
+	for i, s := range sessions {
+		wg.Add(1)
+		go func(i int, s *session[T]) {
+			defer wg.Done()
+			results[i], errs[i] = s.run()
+			if errs[i] != nil {
+				abortOnce.Do(func() { close(abort) })
+			}
+		}(i, s)
+	}
+	wg.Wait()
+
+	// Report the error that caused the abort rather than the aborted sessions
+	for _, err := range errs {
+		if err != nil && !errors.Is(err, errAborted) {
+			return nil, err
+		}
+	}
+	return results, nil
+}
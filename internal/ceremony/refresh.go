@@ -0,0 +1,74 @@
+package ceremony
+
+import (
+	"fmt"
+	"hash/crc32"
+	"strings"
+
+	ecdsaKeygen "github.com/bnb-chain/tss-lib/v2/ecdsa/keygen"
+)
+
+// RefreshOptions configures a proactive share refresh
+type RefreshOptions struct {
+	Name      string
+	OutputDir string
+	Password  string
+	Force     bool
+	PreParams []*ecdsaKeygen.LocalPreParams // optional, one per party of the vault
+}
+
+// Refresh re-randomises every party's shares without changing the roster,
+// threshold, public keys or chain code, and writes one new .vult per party.
+// The new shares lie on a fresh polynomial, so old shares (and backups of
+// them) can no longer be combined with the new ones.
+func Refresh(shares []*PartyShare, opts RefreshOptions) (*VaultResult, error) {
+	if opts.Name == "" {
+		return nil, fmt.Errorf("vault name is required")
+	}
+
+	refreshed, err := RefreshShares(shares, opts.PreParams)
+	if err != nil {
+		return nil, err
+	}
+
+	return writeVerifiedShares(opts.OutputDir, opts.Name, refreshed, opts.Password, opts.Force, shareVaultInfo(shares[0]))
+}
+
+// RefreshShares reshares from at least t+1 of a vault's shares to the vault's
+// own committee. Parties that did not contribute an old share still receive
+// a refreshed one.
+func RefreshShares(shares []*PartyShare, preParams []*ecdsaKeygen.LocalPreParams) ([]*PartyShare, error) {
+	if len(shares) == 0 || shares[0].ECDSA == nil {
+		return nil, fmt.Errorf("no shares given")
+	}
+
+	roster := append([]string(nil), shares[0].ECDSA.KeygenCommitteeKeys...)
+	for _, share := range shares {
+		if !containsParty(roster, share.PartyID) {
+			return nil, fmt.Errorf("party %s is not in the vault committee", share.PartyID)
+		}
+	}
+
+	return reshareShares(shares, roster, refreshPrefix(roster, shares[0].ECDSA.ResharePrefix), preParams)
+}
+
+// refreshPrefix picks the reshare prefix for a same-roster reshare. The apps'
+// prefix only depends on the party list, which would collide with the current
+// prefix when the vault was already reshared to this roster; chain the
+// current prefix in for that case.
+func refreshPrefix(roster []string, currentPrefix string) string {
+	prefix := newResharePrefix(roster)
+	if prefix != currentPrefix {
+		return prefix
+	}
+	return fmt.Sprintf("%x", crc32.ChecksumIEEE([]byte(currentPrefix+":"+strings.Join(roster, ","))))
+}
+
+func containsParty(parties []string, party string) bool {
+	for _, p := range parties {
+		if p == party {
+			return true
+		}
+	}
+	return false
+}
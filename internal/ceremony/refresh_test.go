@@ -0,0 +1,78 @@
+package ceremony
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/rowbotony/vultool/internal/recovery"
+)
+
+// TestRefresh_SameRoster - refreshed shares keep the key but cannot mix with old ones
+func TestRefresh_SameRoster(t *testing.T) {
+	material, oldShares := splitTestShares(t)
+	oldDir := t.TempDir()
+	oldFiles, err := WriteShares(oldDir, "refresh-test", oldShares, "", false)
+	if err != nil {
+		t.Fatalf("Failed to write old shares: %v", err)
+	}
+
+	result, err := Refresh(oldShares[1:], RefreshOptions{
+		Name:      "refresh-test",
+		OutputDir: t.TempDir(),
+		PreParams: loadTestPreParams(t, 3),
+	})
+	if err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	if result.PublicKeyECDSA != oldShares[0].ECDSA.PubKey || result.PublicKeyEDDSA != oldShares[0].EdDSA.PubKey {
+		t.Error("Refresh changed the vault public keys")
+	}
+	if result.SignersNeeded != 2 {
+		t.Errorf("Expected threshold to stay at 2, got %d", result.SignersNeeded)
+	}
+
+	newShares, _, err := LoadShares(result.Files, "")
+	if err != nil {
+		t.Fatalf("Failed to load refreshed shares: %v", err)
+	}
+	for i, share := range newShares {
+		// alice did not take part but still gets a refreshed share
+		if share.PartyID != oldShares[i].PartyID {
+			t.Errorf("Share %d: expected party %s, got %s", i+1, oldShares[i].PartyID, share.PartyID)
+		}
+		if share.ECDSA.ECDSALocalData.Xi.Cmp(oldShares[i].ECDSA.ECDSALocalData.Xi) == 0 {
+			t.Errorf("Share %d was not re-randomised", i+1)
+		}
+	}
+
+	refreshed, err := recovery.ReconstructTSSKey(result.Files[:2], "", recovery.ECDSA)
+	if err != nil {
+		t.Fatalf("Reconstruction from refreshed shares failed: %v", err)
+	}
+	if refreshed.PrivateKeyHex != hex.EncodeToString(material.ECDSAKey.Bytes()) {
+		t.Error("Refreshed shares reconstruct a different key")
+	}
+
+	// An old backup combined with a refreshed share is worthless
+	mixed, err := recovery.ReconstructTSSKey([]string{oldFiles[0], result.Files[1]}, "", recovery.ECDSA)
+	if err == nil && mixed.PrivateKeyHex == refreshed.PrivateKeyHex {
+		t.Error("Old and refreshed shares combined still reconstruct the key")
+	}
+}
+
+func TestRefreshPrefix_NeverReusesCurrent(t *testing.T) {
+	roster := []string{"alice", "bob", "carol"}
+
+	if got := refreshPrefix(roster, ""); got != newResharePrefix(roster) {
+		t.Errorf("Expected the app prefix for a fresh vault, got %s", got)
+	}
+
+	current := newResharePrefix(roster)
+	next := refreshPrefix(roster, current)
+	if next == current {
+		t.Error("Refresh prefix must differ from the current prefix")
+	}
+	if refreshPrefix(roster, next) == next {
+		t.Error("Second refresh prefix must differ from the first")
+	}
+}
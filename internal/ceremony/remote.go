@@ -0,0 +1,365 @@
+package ceremony
+
+import (
+	"context"
+	"crypto/md5" // #nosec G501 - keysign message IDs, as the apps derive them
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	ecdsaKeygen "github.com/bnb-chain/tss-lib/v2/ecdsa/keygen"
+	eddsaKeygen "github.com/bnb-chain/tss-lib/v2/eddsa/keygen"
+
+	"github.com/rowbotony/vultool/internal/recovery"
+	"github.com/rowbotony/vultool/internal/transport"
+	"github.com/rowbotony/vultool/internal/vault"
+)
+
+// Peer is this process's seat in a ceremony whose other parties run in other
+// vultool processes, usually on other machines, reached through a transport
+type Peer struct {
+	Transport transport.Interface
+	Party     string
+}
+
+// KeygenRemote runs this party's side of a keygen and writes its own share
+// Every process must pass the same opts.Parties and opts.ChainCode; the
+// committee is sorted so the processes agree on it regardless of flag order.
+// At most one pre-params entry is used, for the local party.
+func KeygenRemote(ctx context.Context, peer Peer, opts KeygenOptions) (*VaultResult, error) {
+	if opts.Name == "" {
+		return nil, fmt.Errorf("vault name is required")
+	}
+	if err := ValidateParties(opts.Parties); err != nil {
+		return nil, err
+	}
+	if len(opts.ChainCode) != 32 {
+		return nil, fmt.Errorf("a distributed keygen needs the same 32-byte chain code on every device")
+	}
+
+	preParams, err := localPreParams(opts.PreParams)
+	if err != nil {
+		return nil, err
+	}
+
+	committee, err := peer.Transport.Join(ctx, peer.Party, opts.Parties)
+	if err != nil {
+		return nil, err
+	}
+
+	ecdsaCh := peer.Transport.Channel("keygen-ecdsa")
+	ecdsaSession, err := keygenECDSASession(committee, peer.Party, preParams, ecdsaCh)
+	if err != nil {
+		return nil, err
+	}
+	ecdsaData, err := runRemote(ctx, ecdsaSession, ecdsaCh)
+	if err != nil {
+		return nil, fmt.Errorf("ECDSA keygen failed: %w", err)
+	}
+
+	eddsaCh := peer.Transport.Channel("keygen-eddsa")
+	eddsaSession, err := keygenEdDSASession(committee, peer.Party, eddsaCh)
+	if err != nil {
+		return nil, err
+	}
+	eddsaData, err := runRemote(ctx, eddsaSession, eddsaCh)
+	if err != nil {
+		return nil, fmt.Errorf("EdDSA keygen failed: %w", err)
+	}
+
+	share, err := newKeygenShare(peer.Party, committee, hex.EncodeToString(opts.ChainCode), ecdsaData[0], eddsaData[0])
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := writeVerifiedShares(opts.OutputDir, opts.Name, []*PartyShare{share}, opts.Password, opts.Force, shareVaultInfo(share))
+	if err != nil {
+		return nil, err
+	}
+	complete(ctx, peer)
+	return result, nil
+}
+
+// SignRemote runs this party's side of a keysign with the other signers
+// Every signer must pass the same signers, key type, message and path; they
+// all end up with the same signature.
+func SignRemote(ctx context.Context, peer Peer, share *PartyShare, signers []string, keyType recovery.TssKeyType,
+	message []byte, derivePath string) (*SignatureResult, error) {
+	if share.PartyID != peer.Party {
+		return nil, fmt.Errorf("share belongs to %s, not %s", share.PartyID, peer.Party)
+	}
+	if err := ValidateParties(signers); err != nil {
+		return nil, err
+	}
+
+	committee := append([]string(nil), signers...)
+	sort.Strings(committee)
+	job, err := newSignJob(share, committee, keyType, message, derivePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := peer.Transport.Join(ctx, peer.Party, committee); err != nil {
+		return nil, err
+	}
+
+	// Keysign messages are scoped by message, as the apps do, so several
+	// signatures can share a session
+	digest := md5.Sum(message) // #nosec G401 - identifier only
+	ch := peer.Transport.Channel(fmt.Sprintf("keysign-%s-%x", keyType, digest))
+	s, err := job.session(share, ch)
+	if err != nil {
+		return nil, err
+	}
+	results, err := runRemote(ctx, s, ch)
+	if err != nil {
+		return nil, fmt.Errorf("keysign failed: %w", err)
+	}
+
+	result, err := job.result(results[0])
+	if err != nil {
+		return nil, err
+	}
+	complete(ctx, peer)
+	return result, nil
+}
+
+// reshareSetup is what a party joining a vault needs to know about it; the
+// first old party sends it to the newcomers before resharing starts
+type reshareSetup struct {
+	PublicKeyECDSA string `json:"public_key_ecdsa"`
+	PublicKeyEDDSA string `json:"public_key_eddsa"`
+	HexChainCode   string `json:"hex_chain_code"`
+	ResharePrefix  string `json:"reshare_prefix"`
+	KeygenSize     int    `json:"keygen_size"`
+}
+
+// ReshareRemote runs this party's side of a resharing from oldParties to
+// opts.NewParties. share is nil for a party joining the vault. Parties in the
+// new committee get a share written to opts.OutputDir; a party that only
+// leaves gets a result without files. Both committees are sorted so every
+// process derives the same reshare prefix.
+func ReshareRemote(ctx context.Context, peer Peer, share *PartyShare, oldParties []string, opts ReshareOptions) (*VaultResult, error) {
+	if opts.Name == "" {
+		return nil, fmt.Errorf("vault name is required")
+	}
+	if err := ValidateParties(opts.NewParties); err != nil {
+		return nil, err
+	}
+	if len(oldParties) == 0 {
+		return nil, fmt.Errorf("the old committee is required")
+	}
+
+	oldCommittee := append([]string(nil), oldParties...)
+	sort.Strings(oldCommittee)
+	newParties := append([]string(nil), opts.NewParties...)
+	sort.Strings(newParties)
+
+	var localOld []*PartyShare
+	if share != nil {
+		if share.PartyID != peer.Party {
+			return nil, fmt.Errorf("share belongs to %s, not %s", share.PartyID, peer.Party)
+		}
+		if !containsParty(oldCommittee, peer.Party) {
+			return nil, fmt.Errorf("party %s holds a share but is not in the old committee %v", peer.Party, oldCommittee)
+		}
+		if err := VerifyShares([]*PartyShare{share}); err != nil {
+			return nil, fmt.Errorf("old share verification failed: %w", err)
+		}
+		localOld = []*PartyShare{share}
+	} else if containsParty(oldCommittee, peer.Party) {
+		return nil, fmt.Errorf("party %s is in the old committee and needs its share", peer.Party)
+	}
+
+	isNew := containsParty(newParties, peer.Party)
+	if share == nil && !isNew {
+		return nil, fmt.Errorf("party %s is in neither committee", peer.Party)
+	}
+
+	var preParams *ecdsaKeygen.LocalPreParams
+	if isNew {
+		var err error
+		if preParams, err = localPreParams(opts.PreParams); err != nil {
+			return nil, err
+		}
+	}
+
+	devices := append([]string(nil), oldCommittee...)
+	for _, party := range newParties {
+		if !containsParty(devices, party) {
+			devices = append(devices, party)
+		}
+	}
+	if _, err := peer.Transport.Join(ctx, peer.Party, devices); err != nil {
+		return nil, err
+	}
+
+	setup, err := exchangeReshareSetup(ctx, peer, share, oldCommittee, devices)
+	if err != nil {
+		return nil, err
+	}
+	signersNeeded, err := SignersRequired(setup.KeygenSize)
+	if err != nil {
+		return nil, err
+	}
+	if len(oldCommittee) < signersNeeded {
+		return nil, fmt.Errorf("vault needs %d old shares to reshare, only %d parties in the old committee", signersNeeded, len(oldCommittee))
+	}
+	newPrefix := newResharePrefix(newParties)
+	if newPrefix == setup.ResharePrefix {
+		return nil, fmt.Errorf("new reshare prefix %q is the same as the current one", newPrefix)
+	}
+
+	c, err := newCommittees(localOld, oldCommittee, setup.ResharePrefix, setup.KeygenSize, newParties, newPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	ecdsaCh := peer.Transport.Channel("reshare-ecdsa")
+	ecdsaResults, err := runRemote(ctx, c.ecdsaSession(peer.Party, ecdsaCh, preParams), ecdsaCh)
+	if err != nil {
+		return nil, fmt.Errorf("ECDSA resharing failed: %w", err)
+	}
+	eddsaCh := peer.Transport.Channel("reshare-eddsa")
+	eddsaResults, err := runRemote(ctx, c.eddsaSession(peer.Party, eddsaCh), eddsaCh)
+	if err != nil {
+		return nil, fmt.Errorf("EdDSA resharing failed: %w", err)
+	}
+
+	expected := &vault.VaultInfo{
+		PublicKeyECDSA: setup.PublicKeyECDSA,
+		PublicKeyEDDSA: setup.PublicKeyEDDSA,
+		HexChainCode:   setup.HexChainCode,
+	}
+	if !isNew {
+		complete(ctx, peer)
+		newSignersNeeded, err := SignersRequired(len(newParties))
+		if err != nil {
+			return nil, err
+		}
+		return &VaultResult{
+			PublicKeyECDSA: expected.PublicKeyECDSA,
+			PublicKeyEDDSA: expected.PublicKeyEDDSA,
+			HexChainCode:   expected.HexChainCode,
+			SignersNeeded:  newSignersNeeded,
+			Addresses:      vault.DeriveAddressesFromVault(expected),
+		}, nil
+	}
+
+	ecdsaData, err := newCommitteeData(peer.Party, ecdsaResults, func(data *ecdsaKeygen.LocalPartySaveData) bool {
+		return data.ECDSAPub != nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	eddsaData, err := newCommitteeData(peer.Party, eddsaResults, func(data *eddsaKeygen.LocalPartySaveData) bool {
+		return data.EDDSAPub != nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	newShare, err := newReshareShare(peer.Party, newParties, setup.HexChainCode, newPrefix, ecdsaData, eddsaData)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := writeVerifiedShares(opts.OutputDir, opts.Name, []*PartyShare{newShare}, opts.Password, opts.Force, expected)
+	if err != nil {
+		return nil, err
+	}
+	complete(ctx, peer)
+	return result, nil
+}
+
+// exchangeReshareSetup has the first old party describe the vault to the
+// parties without a share; the old parties read it from their own shares
+func exchangeReshareSetup(ctx context.Context, peer Peer, share *PartyShare, oldCommittee, devices []string) (*reshareSetup, error) {
+	ch := peer.Transport.Channel("reshare-setup")
+
+	if share == nil {
+		bodies, err := ch.Receive(ctx, peer.Party)
+		if err != nil {
+			return nil, fmt.Errorf("failed to receive the vault description: %w", err)
+		}
+		var setup reshareSetup
+		if err := json.Unmarshal([]byte(bodies[0]), &setup); err != nil {
+			return nil, fmt.Errorf("invalid vault description: %w", err)
+		}
+		return &setup, nil
+	}
+
+	setup := &reshareSetup{
+		PublicKeyECDSA: share.ECDSA.PubKey,
+		PublicKeyEDDSA: share.EdDSA.PubKey,
+		HexChainCode:   share.ECDSA.ChainCodeHex,
+		ResharePrefix:  share.ECDSA.ResharePrefix,
+		KeygenSize:     len(share.ECDSA.KeygenCommitteeKeys),
+	}
+	if peer.Party != oldCommittee[0] {
+		return setup, nil
+	}
+
+	body, err := json.Marshal(setup)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode vault description: %w", err)
+	}
+	for _, device := range devices {
+		if !containsParty(oldCommittee, device) {
+			if err := ch.Send(peer.Party, device, string(body)); err != nil {
+				return nil, fmt.Errorf("failed to send the vault description to %s: %w", device, err)
+			}
+		}
+	}
+	return setup, nil
+}
+
+// runRemote runs a single local session whose peers are reached through ch
+// Inbound payloads are pumped from the channel until the session finishes;
+// cancelling ctx aborts the session.
+func runRemote[T any](ctx context.Context, s *session[T], ch transport.Channel) ([]T, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	s.abort = ctx.Done()
+
+	go func() {
+		for {
+			bodies, err := ch.Receive(ctx, s.localPartyKey)
+			if err != nil {
+				if ctx.Err() == nil {
+					s.fail(fmt.Errorf("failed to receive messages: %w", err))
+				}
+				return
+			}
+			for _, body := range bodies {
+				s.deliver(body)
+			}
+		}
+	}()
+
+	results, err := s.run()
+	if errors.Is(err, errAborted) {
+		return nil, fmt.Errorf("%w: %v", errAborted, context.Cause(ctx))
+	}
+	return results, err
+}
+
+// complete reports the party as finished; the ceremony already succeeded, so
+// a relay that is gone by now is not an error
+func complete(ctx context.Context, peer Peer) {
+	_ = peer.Transport.Complete(ctx, peer.Party)
+}
+
+// localPreParams returns the single pre-params entry a remote party uses,
+// generating it when none was given
+func localPreParams(preParams []*ecdsaKeygen.LocalPreParams) (*ecdsaKeygen.LocalPreParams, error) {
+	if len(preParams) > 0 {
+		return preParams[0], nil
+	}
+	generated, err := GeneratePreParams(1)
+	if err != nil {
+		return nil, err
+	}
+	return generated[0], nil
+}
@@ -0,0 +1,149 @@
+package ceremony
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rowbotony/vultool/internal/recovery"
+	"github.com/rowbotony/vultool/internal/relay"
+	"github.com/rowbotony/vultool/internal/transport"
+)
+
+const testEncryptionKey = "5f4dcc3b5aa765d61d8327deb882cf995f4dcc3b5aa765d61d8327deb882cf99"
+
+// runProcesses runs fn for every party as if each ran in its own vultool
+// process: every party gets its own relay client for the session
+func runProcesses(t *testing.T, relayURL, sessionID string, parties []string, fn func(ctx context.Context, i int, peer Peer) error) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for i, party := range parties {
+		client, err := transport.NewRelayClient(relayURL, sessionID, testEncryptionKey)
+		if err != nil {
+			t.Fatalf("NewRelayClient failed: %v", err)
+		}
+		wg.Add(1)
+		go func(i int, peer Peer) {
+			defer wg.Done()
+			if err := fn(ctx, i, peer); err != nil {
+				t.Errorf("%s: %v", peer.Party, err)
+				cancel()
+			}
+		}(i, Peer{Transport: client, Party: party})
+	}
+	wg.Wait()
+}
+
+// TestRemote_KeygenAndSign - two processes create a vault and sign with it over a relay
+func TestRemote_KeygenAndSign(t *testing.T) {
+	server := httptest.NewServer(relay.NewServer(0).Handler())
+	defer server.Close()
+
+	parties := []string{"laptop", "phone"}
+	preParams := loadTestPreParams(t, 2)
+	chainCode := bytes.Repeat([]byte{0x42}, 32)
+	results := make([]*VaultResult, len(parties))
+
+	runProcesses(t, server.URL, "keygen-session", parties, func(ctx context.Context, i int, peer Peer) error {
+		var err error
+		results[i], err = KeygenRemote(ctx, peer, KeygenOptions{
+			Name:      "remote-test",
+			Parties:   parties,
+			ChainCode: chainCode,
+			OutputDir: t.TempDir(),
+			PreParams: preParams[i : i+1],
+		})
+		return err
+	})
+	if t.Failed() {
+		t.FailNow()
+	}
+
+	if results[0].PublicKeyECDSA != results[1].PublicKeyECDSA || results[0].PublicKeyEDDSA != results[1].PublicKeyEDDSA {
+		t.Fatal("Processes disagree on the vault public keys")
+	}
+	shares, _, err := LoadShares([]string{results[0].Files[0], results[1].Files[0]}, "")
+	if err != nil {
+		t.Fatalf("Failed to load the written shares: %v", err)
+	}
+	if err := VerifyShares(shares); err != nil {
+		t.Errorf("Shares written by different processes do not form a vault: %v", err)
+	}
+
+	hash := sha256.Sum256([]byte("signed across processes"))
+	signatures := make([]*SignatureResult, len(parties))
+	runProcesses(t, server.URL, "keysign-session", parties, func(ctx context.Context, i int, peer Peer) error {
+		var err error
+		signatures[i], err = SignRemote(ctx, peer, shares[i], parties, recovery.ECDSA, hash[:], "m/44'/60'/0'/0/0")
+		return err
+	})
+	if t.Failed() {
+		t.FailNow()
+	}
+	if signatures[0].Signature != signatures[1].Signature || signatures[0].DerivePath != "m/44'/60'/0'/0/0" {
+		t.Errorf("Unexpected signatures: %+v %+v", signatures[0], signatures[1])
+	}
+}
+
+// TestRemote_Reshare - alice leaves, carol and dave join without shares, bob stays
+func TestRemote_Reshare(t *testing.T) {
+	server := httptest.NewServer(relay.NewServer(0).Handler())
+	defer server.Close()
+
+	_, oldShares := splitTestShares(t)
+	preParams := loadTestPreParams(t, 3)
+	oldParties := []string{"alice", "bob"}
+	newParties := []string{"bob", "carol", "dave"}
+
+	devices := []string{"alice", "bob", "carol", "dave"}
+	localShares := []*PartyShare{oldShares[0], oldShares[1], nil, nil}
+	results := make([]*VaultResult, len(devices))
+
+	runProcesses(t, server.URL, "reshare-session", devices, func(ctx context.Context, i int, peer Peer) error {
+		opts := ReshareOptions{
+			Name:       "remote-reshare",
+			NewParties: newParties,
+			OutputDir:  t.TempDir(),
+		}
+		if i > 0 {
+			opts.PreParams = preParams[i-1 : i]
+		}
+		var err error
+		results[i], err = ReshareRemote(ctx, peer, localShares[i], oldParties, opts)
+		return err
+	})
+	if t.Failed() {
+		t.FailNow()
+	}
+
+	if len(results[0].Files) != 0 {
+		t.Errorf("A leaving party must not get a share, got %v", results[0].Files)
+	}
+	var files []string
+	for _, result := range results[1:] {
+		files = append(files, result.Files...)
+	}
+	newShares, vaultInfo, err := LoadShares(files, "")
+	if err != nil {
+		t.Fatalf("Failed to load the reshared vault: %v", err)
+	}
+	if vaultInfo.PublicKeyECDSA != oldShares[0].ECDSA.PubKey || vaultInfo.PublicKeyEDDSA != oldShares[0].EdDSA.PubKey {
+		t.Error("Reshare changed the vault public keys")
+	}
+	if err := VerifyShares(newShares); err != nil {
+		t.Errorf("New shares do not form a vault: %v", err)
+	}
+	for _, share := range newShares {
+		if share.ECDSA.ResharePrefix != newResharePrefix(newParties) {
+			t.Errorf("%s: unexpected reshare prefix %q", share.PartyID, share.ECDSA.ResharePrefix)
+		}
+	}
+}
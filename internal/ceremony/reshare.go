@@ -0,0 +1,309 @@
+package ceremony
+
+import (
+	"crypto/elliptic"
+	"fmt"
+	"hash/crc32"
+	"math/big"
+	"strings"
+
+	ecdsaKeygen "github.com/bnb-chain/tss-lib/v2/ecdsa/keygen"
+	ecdsaResharing "github.com/bnb-chain/tss-lib/v2/ecdsa/resharing"
+	eddsaKeygen "github.com/bnb-chain/tss-lib/v2/eddsa/keygen"
+	eddsaResharing "github.com/bnb-chain/tss-lib/v2/eddsa/resharing"
+	tsslib "github.com/bnb-chain/tss-lib/v2/tss"
+	"github.com/vultisig/mobile-tss-lib/tss"
+)
+
+// ReshareOptions configures a local resharing ceremony
+type ReshareOptions struct {
+	Name       string
+	NewParties []string
+	OutputDir  string
+	Password   string
+	Force      bool
+	PreParams  []*ecdsaKeygen.LocalPreParams // optional, one per new party
+}
+
+// Reshare moves a vault from the old quorum's shares to a new committee and
+// writes one .vult file per new party. The public keys and chain code stay
+// the same, so every address is unchanged; the old shares become useless
+// together with the new ones, which is how a lost device is rotated out.
+func Reshare(oldShares []*PartyShare, opts ReshareOptions) (*VaultResult, error) {
+	if opts.Name == "" {
+		return nil, fmt.Errorf("vault name is required")
+	}
+
+	shares, err := ReshareShares(oldShares, opts.NewParties, opts.PreParams)
+	if err != nil {
+		return nil, err
+	}
+
+	return writeVerifiedShares(opts.OutputDir, opts.Name, shares, opts.Password, opts.Force, shareVaultInfo(oldShares[0]))
+}
+
+// ReshareShares runs ECDSA and EdDSA resharing from at least t+1 old shares
+// to newParties over an in-memory bus. A party can sit in both committees;
+// its device then hosts an old and a new tss-lib party, as on the apps.
+func ReshareShares(oldShares []*PartyShare, newParties []string, preParams []*ecdsaKeygen.LocalPreParams) ([]*PartyShare, error) {
+	return reshareShares(oldShares, newParties, newResharePrefix(newParties), preParams)
+}
+
+func reshareShares(oldShares []*PartyShare, newParties []string, newPrefix string, preParams []*ecdsaKeygen.LocalPreParams) ([]*PartyShare, error) {
+	if err := ValidateParties(newParties); err != nil {
+		return nil, err
+	}
+	if len(oldShares) == 0 {
+		return nil, fmt.Errorf("no old shares given")
+	}
+
+	// The old shares must agree with each other before they are handed to the protocol
+	if err := VerifyShares(oldShares); err != nil {
+		return nil, fmt.Errorf("old share verification failed: %w", err)
+	}
+
+	reference := oldShares[0]
+	oldPrefix := reference.ECDSA.ResharePrefix
+	oldCommittee := make([]string, len(oldShares))
+	for i, share := range oldShares {
+		if share.ECDSA.ResharePrefix != oldPrefix || share.ECDSA.ChainCodeHex != reference.ECDSA.ChainCodeHex {
+			return nil, fmt.Errorf("party %s holds a share of a different reshare", share.PartyID)
+		}
+		oldCommittee[i] = share.PartyID
+	}
+	signersNeeded, err := SignersRequired(len(reference.ECDSA.KeygenCommitteeKeys))
+	if err != nil {
+		return nil, err
+	}
+	if len(oldShares) < signersNeeded {
+		return nil, fmt.Errorf("vault needs %d old shares to reshare, only %d given", signersNeeded, len(oldShares))
+	}
+	if newPrefix == oldPrefix {
+		return nil, fmt.Errorf("new reshare prefix %q is the same as the current one", newPrefix)
+	}
+
+	if preParams == nil {
+		generated, err := GeneratePreParams(len(newParties))
+		if err != nil {
+			return nil, err
+		}
+		preParams = generated
+	}
+	if len(preParams) != len(newParties) {
+		return nil, fmt.Errorf("expected %d pre-params, got %d", len(newParties), len(preParams))
+	}
+
+	c, err := newCommittees(oldShares, oldCommittee, oldPrefix, len(reference.ECDSA.KeygenCommitteeKeys), newParties, newPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	ecdsaData, err := reshareECDSA(c, preParams)
+	if err != nil {
+		return nil, fmt.Errorf("ECDSA resharing failed: %w", err)
+	}
+	eddsaData, err := reshareEdDSA(c)
+	if err != nil {
+		return nil, fmt.Errorf("EdDSA resharing failed: %w", err)
+	}
+
+	shares := make([]*PartyShare, 0, len(newParties))
+	for i, party := range newParties {
+		share, err := newReshareShare(party, newParties, reference.ECDSA.ChainCodeHex, newPrefix, ecdsaData[i], eddsaData[i])
+		if err != nil {
+			return nil, err
+		}
+		shares = append(shares, share)
+	}
+
+	return shares, nil
+}
+
+// newReshareShare wraps a new committee member's save data in local states
+func newReshareShare(party string, newParties []string, chainCodeHex, newPrefix string,
+	ecdsaData *ecdsaKeygen.LocalPartySaveData, eddsaData *eddsaKeygen.LocalPartySaveData) (*PartyShare, error) {
+	share, err := newKeygenShare(party, newParties, chainCodeHex, ecdsaData, eddsaData)
+	if err != nil {
+		return nil, err
+	}
+	share.ECDSA.ResharePrefix = newPrefix
+	share.EdDSA.ResharePrefix = newPrefix
+	return share, nil
+}
+
+// committees describes who takes part in a resharing and on which device
+type committees struct {
+	oldIDs       tsslib.SortedPartyIDs
+	newIDs       tsslib.SortedPartyIDs
+	oldThreshold int
+	newThreshold int
+	devices      []string // every distinct moniker, old committee first
+	oldShare     map[string]*PartyShare
+	newIndex     map[string]int // position in the caller's new party list
+}
+
+// newCommittees lays out a resharing; oldShares are the old shares held by
+// this process and oldKeygenSize is the size of the vault's keygen committee
+func newCommittees(oldShares []*PartyShare, oldCommittee []string, oldPrefix string, oldKeygenSize int,
+	newParties []string, newPrefix string) (*committees, error) {
+	// The old polynomial degree is fixed by the original committee size, not
+	// by how many of its members turn up
+	oldThreshold, err := tss.GetThreshold(oldKeygenSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get old threshold: %w", err)
+	}
+	newThreshold, err := tss.GetThreshold(len(newParties))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get threshold: %w", err)
+	}
+
+	c := &committees{
+		oldIDs:       sortedPartyIDs(oldCommittee, oldPrefix),
+		newIDs:       sortedPartyIDs(newParties, newPrefix),
+		oldThreshold: oldThreshold,
+		newThreshold: newThreshold,
+		oldShare:     make(map[string]*PartyShare, len(oldShares)),
+		newIndex:     make(map[string]int, len(newParties)),
+	}
+	for _, share := range oldShares {
+		c.oldShare[share.PartyID] = share
+		c.devices = append(c.devices, share.PartyID)
+	}
+	for i, party := range newParties {
+		c.newIndex[party] = i
+		if _, ok := c.oldShare[party]; !ok {
+			c.devices = append(c.devices, party)
+		}
+	}
+	return c, nil
+}
+
+// parameters returns the resharing parameters for a party of either committee
+func (c *committees) parameters(curve elliptic.Curve, partyID *tsslib.PartyID) *tsslib.ReSharingParameters {
+	return tsslib.NewReSharingParameters(curve, tsslib.NewPeerContext(c.oldIDs), tsslib.NewPeerContext(c.newIDs),
+		partyID, len(c.oldIDs), c.oldThreshold, len(c.newIDs), c.newThreshold)
+}
+
+// knownParties lists both committees for sender lookup
+func (c *committees) knownParties() []*tsslib.PartyID {
+	return append(append([]*tsslib.PartyID(nil), c.oldIDs...), c.newIDs...)
+}
+
+// reshareECDSA returns the new committee's save data in new party order
+func reshareECDSA(c *committees, preParams []*ecdsaKeygen.LocalPreParams) ([]*ecdsaKeygen.LocalPartySaveData, error) {
+	bus := newMemoryBus()
+	sessions := make([]*session[*ecdsaKeygen.LocalPartySaveData], len(c.devices))
+	for i, device := range c.devices {
+		var pp *ecdsaKeygen.LocalPreParams
+		if j, ok := c.newIndex[device]; ok {
+			pp = preParams[j]
+		}
+		s := c.ecdsaSession(device, bus, pp)
+		bus.register(device, s)
+		sessions[i] = s
+	}
+
+	results, err := runSessions(sessions)
+	if err != nil {
+		return nil, err
+	}
+
+	saved := make([]*ecdsaKeygen.LocalPartySaveData, len(c.newIndex))
+	for i, device := range c.devices {
+		if j, ok := c.newIndex[device]; ok {
+			if saved[j], err = newCommitteeData(device, results[i], func(data *ecdsaKeygen.LocalPartySaveData) bool {
+				return data.ECDSAPub != nil
+			}); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return saved, nil
+}
+
+// reshareEdDSA returns the new committee's save data in new party order
+func reshareEdDSA(c *committees) ([]*eddsaKeygen.LocalPartySaveData, error) {
+	bus := newMemoryBus()
+	sessions := make([]*session[*eddsaKeygen.LocalPartySaveData], len(c.devices))
+	for i, device := range c.devices {
+		s := c.eddsaSession(device, bus)
+		bus.register(device, s)
+		sessions[i] = s
+	}
+
+	results, err := runSessions(sessions)
+	if err != nil {
+		return nil, err
+	}
+
+	saved := make([]*eddsaKeygen.LocalPartySaveData, len(c.newIndex))
+	for i, device := range c.devices {
+		if j, ok := c.newIndex[device]; ok {
+			if saved[j], err = newCommitteeData(device, results[i], func(data *eddsaKeygen.LocalPartySaveData) bool {
+				return data.EDDSAPub != nil
+			}); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return saved, nil
+}
+
+// ecdsaSession sets up the secp256k1 resharing parties a device hosts
+// preParams is only used when the device is in the new committee.
+func (c *committees) ecdsaSession(device string, messenger tss.Messenger,
+	preParams *ecdsaKeygen.LocalPreParams) *session[*ecdsaKeygen.LocalPartySaveData] {
+	curve := tsslib.S256()
+	s := newSession[*ecdsaKeygen.LocalPartySaveData](device, messenger, c.knownParties())
+	// The new committee starts first, it waits for the old committee's messages
+	if _, ok := c.newIndex[device]; ok {
+		data := ecdsaKeygen.NewLocalPartySaveData(len(c.newIDs))
+		data.LocalPreParams = *preParams
+		params := c.parameters(curve, c.newIDs[partyIndex(c.newIDs, device)])
+		s.add(ecdsaResharing.NewLocalParty(params, data, s.outCh, s.endCh))
+	}
+	if share, ok := c.oldShare[device]; ok {
+		data := share.ECDSA.ECDSALocalData
+		// The old committee zeroes its secret share once done, keep the caller's intact
+		data.Xi = new(big.Int).Set(data.Xi)
+		params := c.parameters(curve, c.oldIDs[partyIndex(c.oldIDs, device)])
+		s.add(ecdsaResharing.NewLocalParty(params, data, s.outCh, s.endCh))
+	}
+	return s
+}
+
+// eddsaSession sets up the ed25519 resharing parties a device hosts
+func (c *committees) eddsaSession(device string, messenger tss.Messenger) *session[*eddsaKeygen.LocalPartySaveData] {
+	curve := tsslib.Edwards()
+	s := newSession[*eddsaKeygen.LocalPartySaveData](device, messenger, c.knownParties())
+	if _, ok := c.newIndex[device]; ok {
+		data := eddsaKeygen.NewLocalPartySaveData(len(c.newIDs))
+		params := c.parameters(curve, c.newIDs[partyIndex(c.newIDs, device)])
+		s.add(eddsaResharing.NewLocalParty(params, data, s.outCh, s.endCh))
+	}
+	if share, ok := c.oldShare[device]; ok {
+		data := share.EdDSA.EDDSALocalData
+		data.Xi = new(big.Int).Set(data.Xi)
+		params := c.parameters(curve, c.oldIDs[partyIndex(c.oldIDs, device)])
+		s.add(eddsaResharing.NewLocalParty(params, data, s.outCh, s.endCh))
+	}
+	return s
+}
+
+// newCommitteeData picks a device's new share out of its session results;
+// old-committee parties finish with empty save data
+func newCommitteeData[T any](device string, results []T, isNew func(T) bool) (T, error) {
+	for _, data := range results {
+		if isNew(data) {
+			return data, nil
+		}
+	}
+	var none T
+	return none, fmt.Errorf("party %s finished without a new share", device)
+}
+
+// newResharePrefix derives the party key prefix of a new committee the same
+// way mobile-tss-lib does: the CRC32 of the comma-separated party list
+func newResharePrefix(parties []string) string {
+	return fmt.Sprintf("%x", crc32.ChecksumIEEE([]byte(strings.Join(parties, ","))))
+}
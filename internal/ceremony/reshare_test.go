@@ -0,0 +1,91 @@
+package ceremony
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/rowbotony/vultool/internal/recovery"
+)
+
+// TestReshare_ReplaceParty - rotating carol out for dave keeps the vault keys
+func TestReshare_ReplaceParty(t *testing.T) {
+	material, oldShares := splitTestShares(t)
+	newParties := []string{"alice", "bob", "dave"}
+
+	result, err := Reshare(oldShares[:2], ReshareOptions{
+		Name:       "reshare-test",
+		NewParties: newParties,
+		OutputDir:  t.TempDir(),
+		Password:   "reshare-pass",
+		PreParams:  loadTestPreParams(t, 3),
+	})
+	if err != nil {
+		t.Fatalf("Reshare failed: %v", err)
+	}
+
+	if result.PublicKeyECDSA != oldShares[0].ECDSA.PubKey || result.PublicKeyEDDSA != oldShares[0].EdDSA.PubKey {
+		t.Error("Reshare changed the vault public keys")
+	}
+	if result.HexChainCode != oldShares[0].ECDSA.ChainCodeHex {
+		t.Error("Reshare changed the chain code")
+	}
+
+	newShares, _, err := LoadShares(result.Files, "reshare-pass")
+	if err != nil {
+		t.Fatalf("Failed to load reshared vault: %v", err)
+	}
+	if prefix := newShares[0].ECDSA.ResharePrefix; prefix == "" || prefix != newResharePrefix(newParties) {
+		t.Errorf("Unexpected reshare prefix %q", newShares[0].ECDSA.ResharePrefix)
+	}
+	for i, share := range newShares {
+		if share.PartyID != newParties[i] {
+			t.Errorf("Share %d: expected party %s, got %s", i+1, newParties[i], share.PartyID)
+		}
+		if share.ECDSA.ECDSALocalData.Xi.Cmp(oldShares[0].ECDSA.ECDSALocalData.Xi) == 0 {
+			t.Errorf("Share %d reuses an old secret share", i+1)
+		}
+	}
+
+	// Old shares must be left untouched by the ceremony
+	if err := VerifyShares(oldShares); err != nil {
+		t.Errorf("Old shares were modified: %v", err)
+	}
+
+	// The newcomer can recover and sign together with a remaining party
+	recovered, err := recovery.ReconstructTSSKey([]string{result.Files[0], result.Files[2]}, "reshare-pass", recovery.ECDSA)
+	if err != nil {
+		t.Fatalf("ECDSA reconstruction failed: %v", err)
+	}
+	if recovered.PrivateKeyHex != hex.EncodeToString(material.ECDSAKey.Bytes()) {
+		t.Error("Reshared ECDSA shares reconstruct a different key")
+	}
+
+	hash := sha256.Sum256([]byte("signed after reshare"))
+	if _, err := Sign(newShares[1:], recovery.ECDSA, hash[:], "m/44'/60'/0'/0/0"); err != nil {
+		t.Errorf("ECDSA signing with reshared shares failed: %v", err)
+	}
+	if _, err := Sign(newShares[:2], recovery.EdDSA, hash[:], ""); err != nil {
+		t.Errorf("EdDSA signing with reshared shares failed: %v", err)
+	}
+}
+
+func TestReshare_Validation(t *testing.T) {
+	_, oldShares := splitTestShares(t)
+
+	_, err := ReshareShares(oldShares[:1], []string{"alice", "bob"}, loadTestPreParams(t, 2))
+	if err == nil || !strings.Contains(err.Error(), "needs 2 old shares") {
+		t.Errorf("Expected quorum error, got: %v", err)
+	}
+
+	_, err = ReshareShares(oldShares, []string{"alice", "alice"}, loadTestPreParams(t, 2))
+	if err == nil || !strings.Contains(err.Error(), "duplicate party") {
+		t.Errorf("Expected duplicate party error, got: %v", err)
+	}
+
+	_, err = reshareShares(oldShares, []string{"alice", "bob"}, "", loadTestPreParams(t, 2))
+	if err == nil || !strings.Contains(err.Error(), "same as the current one") {
+		t.Errorf("Expected reshare prefix error, got: %v", err)
+	}
+}
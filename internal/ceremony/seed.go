@@ -0,0 +1,233 @@
+package ceremony
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/tyler-smith/go-bip39"
+)
+
+// DefaultEdDSAPath is the SLIP-0010 path Phantom and Solflare use for the first account
+const DefaultEdDSAPath = "m/44'/501'/0'/0'"
+
+// chainCodeLabel domain-separates chain codes derived for raw private keys
+const chainCodeLabel = "vultool import-seed chain code"
+
+// ed25519Order is the prime order L of the ed25519 base point
+var ed25519Order, _ = new(big.Int).SetString("1000000000000000000000000000000014DEF9DEA2F79CD65812631A5CF5D3ED", 16)
+
+// KeyMaterial is the secret material handed to the dealer for splitting
+type KeyMaterial struct {
+	ECDSAKey  *big.Int // secp256k1 root private key
+	ChainCode []byte   // BIP32 chain code belonging to the ECDSA root
+	EdDSAKey  *big.Int // ed25519 secret scalar, already clamped and reduced mod L
+}
+
+// KeyMaterialFromMnemonic derives vault key material from a BIP39 mnemonic
+// The ECDSA root is the BIP32 master key, so vault addresses match a wallet
+// that derives from the master key with non-hardened steps. The EdDSA key is
+// the SLIP-0010 key at eddsaPath, which matches Solana wallets exactly.
+func KeyMaterialFromMnemonic(mnemonic, passphrase, eddsaPath string) (*KeyMaterial, error) {
+	mnemonic = strings.Join(strings.Fields(mnemonic), " ")
+	if !bip39.IsMnemonicValid(mnemonic) {
+		return nil, fmt.Errorf("invalid BIP39 mnemonic (unknown word or bad checksum)")
+	}
+
+	seed := bip39.NewSeed(mnemonic, passphrase)
+
+	master, err := hdkeychain.NewMaster(seed, &chaincfg.MainNetParams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive BIP32 master key: %w", err)
+	}
+	privKey, err := master.ECPrivKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get BIP32 master private key: %w", err)
+	}
+
+	if eddsaPath == "" {
+		eddsaPath = DefaultEdDSAPath
+	}
+	edSeed, err := slip10Ed25519(seed, eddsaPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &KeyMaterial{
+		ECDSAKey:  new(big.Int).SetBytes(privKey.Serialize()),
+		ChainCode: master.ChainCode(),
+		EdDSAKey:  ed25519Scalar(edSeed),
+	}, nil
+}
+
+// ParseECDSAKey parses a secp256k1 private key given as 32-byte hex or WIF
+func ParseECDSAKey(input string) (*big.Int, error) {
+	input = strings.TrimSpace(input)
+
+	if wif, err := btcutil.DecodeWIF(input); err == nil {
+		return new(big.Int).SetBytes(wif.PrivKey.Serialize()), nil
+	}
+
+	keyBytes, err := hex.DecodeString(strings.TrimPrefix(input, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("ECDSA key is neither WIF nor hex: %w", err)
+	}
+	if len(keyBytes) != 32 {
+		return nil, fmt.Errorf("ECDSA key must be 32 bytes, got %d", len(keyBytes))
+	}
+
+	key := new(big.Int).SetBytes(keyBytes)
+	if key.Sign() == 0 || key.Cmp(secp256k1.S256().N) >= 0 {
+		return nil, fmt.Errorf("ECDSA key is out of range for secp256k1")
+	}
+	return key, nil
+}
+
+// ParseEdDSAKey parses an ed25519 private key given as a 32-byte seed or a
+// 64-byte seed||public key pair (the Solana keypair layout) in hex
+func ParseEdDSAKey(input string) (*big.Int, error) {
+	keyBytes, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(input), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("EdDSA key must be hex: %w", err)
+	}
+
+	switch len(keyBytes) {
+	case ed25519.SeedSize:
+		return ed25519Scalar(keyBytes), nil
+	case ed25519.PrivateKeySize:
+		seed := keyBytes[:ed25519.SeedSize]
+		derived := ed25519.NewKeyFromSeed(seed).Public().(ed25519.PublicKey)
+		if !bytes.Equal(derived, keyBytes[ed25519.SeedSize:]) {
+			return nil, fmt.Errorf("EdDSA keypair public half does not match its seed")
+		}
+		return ed25519Scalar(seed), nil
+	default:
+		return nil, fmt.Errorf("EdDSA key must be 32 or 64 bytes, got %d", len(keyBytes))
+	}
+}
+
+// KeyMaterialFromRawKeys builds key material from raw private keys
+// Either key may be empty, in which case a fresh random key is generated for
+// that curve. chainCodeHex is optional and defaults to DeriveChainCode.
+func KeyMaterialFromRawKeys(ecdsaInput, eddsaInput, chainCodeHex string) (*KeyMaterial, error) {
+	if ecdsaInput == "" && eddsaInput == "" {
+		return nil, fmt.Errorf("at least one of the ECDSA or EdDSA keys is required")
+	}
+
+	material := &KeyMaterial{}
+	var err error
+
+	if ecdsaInput != "" {
+		if material.ECDSAKey, err = ParseECDSAKey(ecdsaInput); err != nil {
+			return nil, err
+		}
+	} else {
+		privKey, err := secp256k1.GeneratePrivateKey()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate ECDSA key: %w", err)
+		}
+		material.ECDSAKey = new(big.Int).SetBytes(privKey.Serialize())
+	}
+
+	if eddsaInput != "" {
+		if material.EdDSAKey, err = ParseEdDSAKey(eddsaInput); err != nil {
+			return nil, err
+		}
+	} else {
+		seed := make([]byte, ed25519.SeedSize)
+		if _, err := rand.Read(seed); err != nil {
+			return nil, fmt.Errorf("failed to generate EdDSA key: %w", err)
+		}
+		material.EdDSAKey = ed25519Scalar(seed)
+	}
+
+	if chainCodeHex != "" {
+		chainCode, err := hex.DecodeString(strings.TrimPrefix(chainCodeHex, "0x"))
+		if err != nil || len(chainCode) != 32 {
+			return nil, fmt.Errorf("chain code must be 32 bytes of hex")
+		}
+		material.ChainCode = chainCode
+	} else {
+		material.ChainCode = DeriveChainCode(material.ECDSAKey)
+	}
+
+	return material, nil
+}
+
+// DeriveChainCode derives a deterministic chain code for a raw ECDSA key
+// Raw keys have no BIP32 chain code, so one is derived from the key itself to
+// make repeated imports of the same key produce the same vault addresses
+func DeriveChainCode(ecdsaKey *big.Int) []byte {
+	keyBytes := make([]byte, 32)
+	ecdsaKey.FillBytes(keyBytes)
+
+	mac := hmac.New(sha256.New, []byte(chainCodeLabel))
+	mac.Write(keyBytes)
+	return mac.Sum(nil)
+}
+
+// slip10Ed25519 derives an ed25519 seed along a fully hardened SLIP-0010 path
+func slip10Ed25519(seed []byte, path string) ([]byte, error) {
+	components := strings.Split(path, "/")
+	if len(components) == 0 || components[0] != "m" {
+		return nil, fmt.Errorf("invalid EdDSA derivation path: %s", path)
+	}
+
+	mac := hmac.New(sha512.New, []byte("ed25519 seed"))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+	key, chainCode := sum[:32], sum[32:]
+
+	for _, component := range components[1:] {
+		if !strings.HasSuffix(component, "'") {
+			return nil, fmt.Errorf("ed25519 derivation only supports hardened indexes, got %s", component)
+		}
+		index, err := strconv.ParseUint(strings.TrimSuffix(component, "'"), 10, 31)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path component %s: %w", component, err)
+		}
+
+		data := make([]byte, 0, 37)
+		data = append(data, 0x00)
+		data = append(data, key...)
+		data = binary.BigEndian.AppendUint32(data, uint32(index)+hdkeychain.HardenedKeyStart)
+
+		mac = hmac.New(sha512.New, chainCode)
+		mac.Write(data)
+		sum = mac.Sum(nil)
+		key, chainCode = sum[:32], sum[32:]
+	}
+
+	return key, nil
+}
+
+// ed25519Scalar expands an ed25519 seed into the secret scalar used for signing
+// tss-lib works with the scalar directly, so the clamped value is reduced mod L
+func ed25519Scalar(seed []byte) *big.Int {
+	digest := sha512.Sum512(seed)
+	digest[0] &= 248
+	digest[31] &= 127
+	digest[31] |= 64
+
+	// The scalar is little-endian; big.Int wants big-endian
+	le := digest[:32]
+	be := make([]byte, 32)
+	for i := range le {
+		be[31-i] = le[i]
+	}
+
+	return new(big.Int).Mod(new(big.Int).SetBytes(be), ed25519Order)
+}
@@ -0,0 +1,201 @@
+// Package ceremony implements local key ceremonies (seed import, keygen,
+// signing and resharing) that produce or consume GG20 vault shares in the
+// same tss.LocalState format the Vultisig apps use.
+package ceremony
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	tsslib "github.com/bnb-chain/tss-lib/v2/tss"
+	v1 "github.com/vultisig/commondata/go/vultisig/vault/v1"
+	"github.com/vultisig/mobile-tss-lib/tss"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/rowbotony/vultool/internal/vault"
+)
+
+// PartyShare holds one party's ECDSA and EdDSA local states
+type PartyShare struct {
+	PartyID string
+	ECDSA   *tss.LocalState
+	EdDSA   *tss.LocalState
+}
+
+// ToVault assembles the vault protobuf for this party
+func (p *PartyShare) ToVault(name string, signers []string) (*v1.Vault, error) {
+	if p.ECDSA == nil || p.EdDSA == nil {
+		return nil, fmt.Errorf("party %s is missing a local state", p.PartyID)
+	}
+
+	ecdsaJSON, err := json.Marshal(p.ECDSA)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal ECDSA local state: %w", err)
+	}
+	eddsaJSON, err := json.Marshal(p.EdDSA)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal EdDSA local state: %w", err)
+	}
+
+	return &v1.Vault{
+		Name:           name,
+		PublicKeyEcdsa: p.ECDSA.PubKey,
+		PublicKeyEddsa: p.EdDSA.PubKey,
+		Signers:        signers,
+		CreatedAt:      timestamppb.Now(),
+		HexChainCode:   p.ECDSA.ChainCodeHex,
+		KeyShares: []*v1.Vault_KeyShare{
+			{PublicKey: p.ECDSA.PubKey, Keyshare: string(ecdsaJSON)},
+			{PublicKey: p.EdDSA.PubKey, Keyshare: string(eddsaJSON)},
+		},
+		LocalPartyId:  p.PartyID,
+		ResharePrefix: p.ECDSA.ResharePrefix,
+	}, nil
+}
+
+// LoadShare reads a party's GG20 local states from a .vult file
+func LoadShare(filePath, password string) (*PartyShare, *vault.VaultInfo, error) {
+	vaultInfo, err := vault.ParseVaultFileWithPassword(filePath, password)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	share := &PartyShare{PartyID: vaultInfo.LocalPartyKey}
+	for _, keyShare := range vaultInfo.KeyShares {
+		var localState tss.LocalState
+		if err := json.Unmarshal([]byte(keyShare.Keyshare), &localState); err != nil {
+			return nil, nil, fmt.Errorf("%s: keyshare is not a GG20 local state (DKLS vaults are not supported): %w", filePath, err)
+		}
+		if keyShare.KeyType == "EDDSA" {
+			share.EdDSA = &localState
+		} else {
+			share.ECDSA = &localState
+		}
+	}
+
+	if share.ECDSA == nil || share.EdDSA == nil {
+		return nil, nil, fmt.Errorf("%s: vault must contain both ECDSA and EdDSA key shares", filePath)
+	}
+	if share.ECDSA.LocalPartyKey != share.PartyID {
+		return nil, nil, fmt.Errorf("%s: keyshare belongs to %s, vault says %s", filePath, share.ECDSA.LocalPartyKey, share.PartyID)
+	}
+
+	return share, vaultInfo, nil
+}
+
+// LoadShares reads several parties' shares and checks they belong to the same vault
+func LoadShares(filePaths []string, password string) ([]*PartyShare, *vault.VaultInfo, error) {
+	if len(filePaths) == 0 {
+		return nil, nil, fmt.Errorf("no vault files given")
+	}
+
+	shares := make([]*PartyShare, 0, len(filePaths))
+	var reference *vault.VaultInfo
+	seen := make(map[string]string, len(filePaths))
+	for _, filePath := range filePaths {
+		share, vaultInfo, err := LoadShare(filePath, password)
+		if err != nil {
+			return nil, nil, err
+		}
+		if reference == nil {
+			reference = vaultInfo
+		} else if vaultInfo.PublicKeyECDSA != reference.PublicKeyECDSA || vaultInfo.PublicKeyEDDSA != reference.PublicKeyEDDSA {
+			return nil, nil, fmt.Errorf("%s belongs to a different vault than %s", filePath, filePaths[0])
+		}
+		if other, ok := seen[share.PartyID]; ok {
+			return nil, nil, fmt.Errorf("%s and %s hold the same party share (%s)", other, filePath, share.PartyID)
+		}
+		seen[share.PartyID] = filePath
+		shares = append(shares, share)
+	}
+
+	return shares, reference, nil
+}
+
+// WriteShares writes one .vult file per party into outputDir and returns the paths
+// Files are numbered by the party's place in its keygen committee, so devices
+// that each write only their own share still name them consistently.
+func WriteShares(outputDir, name string, shares []*PartyShare, password string, force bool) ([]string, error) {
+	paths := make([]string, 0, len(shares))
+	for _, share := range shares {
+		if share.ECDSA == nil {
+			return paths, fmt.Errorf("party %s is missing a local state", share.PartyID)
+		}
+		signers := share.ECDSA.KeygenCommitteeKeys
+		index := -1
+		for i, signer := range signers {
+			if signer == share.PartyID {
+				index = i
+			}
+		}
+		if index < 0 {
+			return paths, fmt.Errorf("party %s is not in its own committee %v", share.PartyID, signers)
+		}
+
+		v, err := share.ToVault(name, signers)
+		if err != nil {
+			return paths, err
+		}
+
+		path := filepath.Join(outputDir, vault.ShareFileName(name, index+1, len(signers)))
+		if err := vault.WriteVaultFile(path, v, password, force); err != nil {
+			return paths, fmt.Errorf("failed to write share for %s: %w", share.PartyID, err)
+		}
+		paths = append(paths, path)
+	}
+
+	return paths, nil
+}
+
+// SignersRequired returns how many parties must take part in signing for an
+// n-party vault; mobile-tss-lib fixes the polynomial degree at ceil(2n/3)-1
+func SignersRequired(parties int) (int, error) {
+	threshold, err := tss.GetThreshold(parties)
+	if err != nil {
+		return 0, fmt.Errorf("invalid party count %d: %w", parties, err)
+	}
+	return threshold + 1, nil
+}
+
+// ValidateParties checks a party list for the constraints mobile-tss-lib relies on
+func ValidateParties(parties []string) error {
+	if len(parties) < 2 {
+		return fmt.Errorf("at least 2 parties are required, got %d", len(parties))
+	}
+
+	seen := make(map[string]bool, len(parties))
+	for _, party := range parties {
+		if party == "" {
+			return fmt.Errorf("party ID must not be empty")
+		}
+		if strings.Contains(party, ",") {
+			return fmt.Errorf("party ID %q must not contain a comma", party)
+		}
+		if seen[party] {
+			return fmt.Errorf("duplicate party ID %q", party)
+		}
+		seen[party] = true
+	}
+
+	return nil
+}
+
+// sortedPartyIDs mirrors mobile-tss-lib's party ID construction so that share
+// IDs line up with what its keysign and reshare code expects
+func sortedPartyIDs(parties []string, keyPrefix string) tsslib.SortedPartyIDs {
+	sorted := append([]string(nil), parties...)
+	sort.Strings(sorted)
+
+	unsorted := make(tsslib.UnSortedPartyIDs, 0, len(sorted))
+	for idx, party := range sorted {
+		key := new(big.Int).SetBytes([]byte(keyPrefix + party))
+		unsorted = append(unsorted, tsslib.NewPartyID(strconv.Itoa(idx), party, key))
+	}
+
+	return tsslib.SortPartyIDs(unsorted)
+}
@@ -0,0 +1,280 @@
+package ceremony
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"github.com/bnb-chain/tss-lib/v2/common"
+	tcrypto "github.com/bnb-chain/tss-lib/v2/crypto"
+	"github.com/bnb-chain/tss-lib/v2/crypto/ckd"
+	ecdsaKeygen "github.com/bnb-chain/tss-lib/v2/ecdsa/keygen"
+	ecdsaSigning "github.com/bnb-chain/tss-lib/v2/ecdsa/signing"
+	eddsaSigning "github.com/bnb-chain/tss-lib/v2/eddsa/signing"
+	tsslib "github.com/bnb-chain/tss-lib/v2/tss"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/vultisig/mobile-tss-lib/tss"
+
+	"github.com/rowbotony/vultool/internal/recovery"
+)
+
+// SignatureResult is a threshold signature produced by a local keysign
+type SignatureResult struct {
+	KeyType      string   `json:"key_type"`
+	PublicKey    string   `json:"public_key"` // key the signature verifies against
+	DerivePath   string   `json:"derive_path,omitempty"`
+	Message      string   `json:"message"`
+	R            string   `json:"r"`
+	S            string   `json:"s"`
+	V            *int     `json:"v,omitempty"` // ECDSA recovery ID (0 or 1)
+	Signature    string   `json:"signature"`   // 64 bytes: r||s for ECDSA, R||S for ed25519
+	DERSignature string   `json:"der_signature,omitempty"`
+	Signers      []string `json:"signers"`
+}
+
+// Sign runs the GG20 keysign protocol between the given local shares
+// For ECDSA, message must be a 32-byte hash and the key is derived along
+// derivePath the same way mobile-tss-lib does (non-hardened, from the vault
+// root). For EdDSA the message is signed as-is with the root key. The shares
+// only ever exchange protocol messages; the private key is never assembled.
+func Sign(shares []*PartyShare, keyType recovery.TssKeyType, message []byte, derivePath string) (*SignatureResult, error) {
+	if len(shares) == 0 {
+		return nil, fmt.Errorf("no shares given")
+	}
+
+	committee := make([]string, len(shares))
+	for i, share := range shares {
+		if share.ECDSA == nil || share.EdDSA == nil {
+			return nil, fmt.Errorf("party %s is missing a local state", share.PartyID)
+		}
+		committee[i] = share.PartyID
+	}
+	for _, share := range shares[1:] {
+		if share.ECDSA.PubKey != shares[0].ECDSA.PubKey || share.ECDSA.ResharePrefix != shares[0].ECDSA.ResharePrefix {
+			return nil, fmt.Errorf("party %s holds a share of a different vault or reshare", share.PartyID)
+		}
+	}
+
+	job, err := newSignJob(shares[0], committee, keyType, message, derivePath)
+	if err != nil {
+		return nil, err
+	}
+
+	bus := newMemoryBus()
+	sessions := make([]*session[*common.SignatureData], len(shares))
+	for i, share := range shares {
+		s, err := job.session(share, bus)
+		if err != nil {
+			return nil, err
+		}
+		bus.register(share.PartyID, s)
+		sessions[i] = s
+	}
+
+	sig, err := agreedSignature(sessions)
+	if err != nil {
+		return nil, err
+	}
+	return job.result(sig)
+}
+
+// signJob holds what every signer of a keysign derives the same way from its
+// own share: the committee's party IDs, the threshold and the key to sign with
+type signJob struct {
+	keyType    recovery.TssKeyType
+	reference  *PartyShare
+	committee  []string
+	partyIDs   tsslib.SortedPartyIDs
+	threshold  int
+	message    []byte
+	derivePath string
+	delta      *big.Int         // ECDSA only: tweak from the root key to the derived key
+	derivedKey *ecdsa.PublicKey // ECDSA only: nil when signing with the root key
+}
+
+func newSignJob(reference *PartyShare, committee []string, keyType recovery.TssKeyType, message []byte, derivePath string) (*signJob, error) {
+	if len(message) == 0 {
+		return nil, fmt.Errorf("message to sign is empty")
+	}
+	if reference.ECDSA == nil || reference.EdDSA == nil {
+		return nil, fmt.Errorf("party %s is missing a local state", reference.PartyID)
+	}
+
+	signersNeeded, err := SignersRequired(len(reference.ECDSA.KeygenCommitteeKeys))
+	if err != nil {
+		return nil, err
+	}
+	if len(committee) < signersNeeded {
+		return nil, fmt.Errorf("vault needs %d signers, only %d shares given", signersNeeded, len(committee))
+	}
+
+	job := &signJob{
+		keyType:    keyType,
+		reference:  reference,
+		committee:  committee,
+		partyIDs:   sortedPartyIDs(committee, reference.ECDSA.ResharePrefix),
+		threshold:  signersNeeded - 1,
+		message:    message,
+		derivePath: derivePath,
+	}
+
+	switch keyType {
+	case recovery.ECDSA:
+		if len(message) != 32 {
+			return nil, fmt.Errorf("ECDSA signing needs a 32-byte message hash, got %d bytes", len(message))
+		}
+		chainCode, err := hex.DecodeString(reference.ECDSA.ChainCodeHex)
+		if err != nil || len(chainCode) != 32 {
+			return nil, fmt.Errorf("vault has an invalid chain code")
+		}
+		job.delta, job.derivedKey, err = deriveKeyDelta(reference.ECDSA.ECDSALocalData.ECDSAPub, chainCode, derivePath)
+		if err != nil {
+			return nil, err
+		}
+	case recovery.EdDSA:
+		job.derivePath = ""
+	default:
+		return nil, fmt.Errorf("unsupported key type %d", keyType)
+	}
+
+	return job, nil
+}
+
+// session sets up one signer's keysign party
+func (j *signJob) session(share *PartyShare, messenger tss.Messenger) (*session[*common.SignatureData], error) {
+	index := partyIndex(j.partyIDs, share.PartyID)
+	if index < 0 {
+		return nil, fmt.Errorf("party %s is not a signer", share.PartyID)
+	}
+
+	s := newSession[*common.SignatureData](share.PartyID, messenger, j.partyIDs)
+	if j.keyType == recovery.EdDSA {
+		curve := tsslib.Edwards()
+		params := tsslib.NewParameters(curve, tsslib.NewPeerContext(j.partyIDs), j.partyIDs[index], len(j.partyIDs), j.threshold)
+		// Pass the message length so leading zero bytes survive the big.Int round trip
+		m := new(big.Int).SetBytes(j.message)
+		s.add(eddsaSigning.NewLocalParty(m, params, share.EdDSA.EDDSALocalData, s.outCh, s.endCh, len(j.message)))
+		return s, nil
+	}
+
+	curve := tsslib.S256()
+	key := share.ECDSA.ECDSALocalData
+	if j.derivedKey != nil {
+		// UpdatePublicKeyAndAdjustBigXj rewrites BigXj in place, keep the caller's copy intact
+		key.BigXj = append([]*tcrypto.ECPoint(nil), key.BigXj...)
+		keys := []ecdsaKeygen.LocalPartySaveData{key}
+		if err := ecdsaSigning.UpdatePublicKeyAndAdjustBigXj(j.delta, keys, j.derivedKey, curve); err != nil {
+			return nil, fmt.Errorf("failed to derive key share: %w", err)
+		}
+		key = keys[0]
+	}
+
+	params := tsslib.NewParameters(curve, tsslib.NewPeerContext(j.partyIDs), j.partyIDs[index], len(j.partyIDs), j.threshold)
+	m := tss.HashToInt(j.message, curve)
+	s.add(ecdsaSigning.NewLocalPartyWithKDD(m, params, key, j.delta, s.outCh, s.endCh, len(j.message)))
+	return s, nil
+}
+
+// result verifies the agreed signature against the signing key and encodes it
+func (j *signJob) result(sig *common.SignatureData) (*SignatureResult, error) {
+	if j.keyType == recovery.EdDSA {
+		pubKey, err := hex.DecodeString(j.reference.EdDSA.PubKey)
+		if err != nil || len(pubKey) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("vault has an invalid EdDSA public key")
+		}
+		if !ed25519.Verify(pubKey, j.message, sig.Signature) {
+			return nil, fmt.Errorf("keysign produced a signature that does not verify")
+		}
+
+		return &SignatureResult{
+			KeyType:   recovery.EdDSA.String(),
+			PublicKey: j.reference.EdDSA.PubKey,
+			Message:   hex.EncodeToString(j.message),
+			R:         hex.EncodeToString(sig.Signature[:32]),
+			S:         hex.EncodeToString(sig.Signature[32:]),
+			Signature: hex.EncodeToString(sig.Signature),
+			Signers:   j.committee,
+		}, nil
+	}
+
+	pubKey := j.derivedKey
+	if pubKey == nil {
+		pubKey = j.reference.ECDSA.ECDSALocalData.ECDSAPub.ToECDSAPubKey()
+	}
+	r, s := new(big.Int).SetBytes(sig.R), new(big.Int).SetBytes(sig.S)
+	if !ecdsa.Verify(pubKey, j.message, r, s) {
+		return nil, fmt.Errorf("keysign produced a signature that does not verify")
+	}
+
+	derSig, err := tss.GetDERSignature(r, s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode DER signature: %w", err)
+	}
+
+	recoveryID := int(sig.SignatureRecovery[0])
+
+	return &SignatureResult{
+		KeyType:      recovery.ECDSA.String(),
+		PublicKey:    hex.EncodeToString(elliptic.MarshalCompressed(tsslib.S256(), pubKey.X, pubKey.Y)),
+		DerivePath:   j.derivePath,
+		Message:      hex.EncodeToString(j.message),
+		R:            hex.EncodeToString(sig.R),
+		S:            hex.EncodeToString(sig.S),
+		V:            &recoveryID,
+		Signature:    hex.EncodeToString(sig.Signature),
+		DERSignature: hex.EncodeToString(derSig),
+		Signers:      j.committee,
+	}, nil
+}
+
+// agreedSignature runs the keysign sessions and checks every party produced the same signature
+func agreedSignature(sessions []*session[*common.SignatureData]) (*common.SignatureData, error) {
+	results, err := runSessions(sessions)
+	if err != nil {
+		return nil, fmt.Errorf("keysign failed: %w", err)
+	}
+
+	signatures := firstResults(results)
+	for _, sig := range signatures[1:] {
+		if !bytes.Equal(sig.Signature, signatures[0].Signature) {
+			return nil, fmt.Errorf("parties produced different signatures")
+		}
+	}
+	return signatures[0], nil
+}
+
+// deriveKeyDelta computes the BIP32 tweak and child public key for a
+// non-hardened path from the vault root, mirroring mobile-tss-lib. Hardened
+// markers are ignored, as they are by the Vultisig apps. An empty path or "m"
+// signs with the root key and returns a nil child key.
+func deriveKeyDelta(rootPub *tcrypto.ECPoint, chainCode []byte, derivePath string) (*big.Int, *ecdsa.PublicKey, error) {
+	if rootPub == nil {
+		return nil, nil, fmt.Errorf("vault has no ECDSA public key")
+	}
+
+	path, err := tss.GetDerivePathBytes(derivePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid derivation path %q: %w", derivePath, err)
+	}
+	if len(path) == 0 {
+		return nil, nil, nil
+	}
+
+	curve := tsslib.S256()
+	parent := &ckd.ExtendedKey{
+		PublicKey: ecdsa.PublicKey{Curve: curve, X: rootPub.X(), Y: rootPub.Y()},
+		ChainCode: chainCode,
+		ParentFP:  []byte{0x00, 0x00, 0x00, 0x00},
+		Version:   chaincfg.MainNetParams.HDPrivateKeyID[:],
+	}
+
+	delta, child, err := ckd.DeriveChildKeyFromHierarchy(path, parent, curve.Params().N, curve)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to derive key for %s: %w", derivePath, err)
+	}
+	return delta, &child.PublicKey, nil
+}
@@ -0,0 +1,110 @@
+package ceremony
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/tyler-smith/go-bip39"
+
+	"github.com/rowbotony/vultool/internal/recovery"
+)
+
+// splitTestShares dealer-splits the test mnemonic into 3 in-memory shares
+func splitTestShares(t *testing.T) (*KeyMaterial, []*PartyShare) {
+	t.Helper()
+
+	material, err := KeyMaterialFromMnemonic(testMnemonic, "", DefaultEdDSAPath)
+	if err != nil {
+		t.Fatalf("Failed to derive key material: %v", err)
+	}
+	shares, err := SplitKeyMaterial(material, []string{"alice", "bob", "carol"}, loadTestPreParams(t, 3))
+	if err != nil {
+		t.Fatalf("Failed to split key material: %v", err)
+	}
+	return material, shares
+}
+
+// TestSign_ECDSADerivedPath - the signature must recover to the vault's Ethereum address
+func TestSign_ECDSADerivedPath(t *testing.T) {
+	_, shares := splitTestShares(t)
+	hash := sha256.Sum256([]byte("vultool threshold signing"))
+
+	result, err := Sign(shares[1:], recovery.ECDSA, hash[:], "m/44'/60'/0'/0/0")
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+
+	// Independent derivation of the expected signer from the mnemonic
+	key, err := hdkeychain.NewMaster(bip39.NewSeed(testMnemonic, ""), &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("Failed to create master key: %v", err)
+	}
+	for _, index := range []uint32{44, 60, 0, 0, 0} {
+		if key, err = key.Derive(index); err != nil {
+			t.Fatalf("Failed to derive child: %v", err)
+		}
+	}
+	pubKey, _ := key.ECPubKey()
+	if result.PublicKey != hex.EncodeToString(pubKey.SerializeCompressed()) {
+		t.Errorf("Signing key: got %s, expected %s", result.PublicKey, hex.EncodeToString(pubKey.SerializeCompressed()))
+	}
+
+	if result.V == nil {
+		t.Fatal("Expected a recovery ID for ECDSA")
+	}
+	sig, _ := hex.DecodeString(result.Signature)
+	recovered, err := crypto.SigToPub(hash[:], append(sig, byte(*result.V)))
+	if err != nil {
+		t.Fatalf("Failed to recover signer: %v", err)
+	}
+	if crypto.PubkeyToAddress(*recovered) != crypto.PubkeyToAddress(*pubKey.ToECDSA()) {
+		t.Error("Recovered signer does not match the derived key")
+	}
+
+	// Signing must not have modified the shares: a second run still works
+	if _, err := Sign(shares[:2], recovery.ECDSA, hash[:], "m/44'/60'/0'/0/0"); err != nil {
+		t.Errorf("Second signing run failed: %v", err)
+	}
+}
+
+// TestSign_EdDSA - signatures verify against the vault's ed25519 key, even with a leading zero byte
+func TestSign_EdDSA(t *testing.T) {
+	material, shares := splitTestShares(t)
+	info, err := sourceVaultInfo(material)
+	if err != nil {
+		t.Fatalf("Failed to build source vault info: %v", err)
+	}
+
+	message := []byte{0x00, 0x01, 0x02, 'v', 'u', 'l', 't'}
+	result, err := Sign([]*PartyShare{shares[0], shares[2]}, recovery.EdDSA, message, "")
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+
+	pubKey, _ := hex.DecodeString(info.PublicKeyEDDSA)
+	sig, _ := hex.DecodeString(result.Signature)
+	if !ed25519.Verify(pubKey, message, sig) {
+		t.Error("EdDSA signature does not verify against the vault public key")
+	}
+}
+
+func TestSign_NotEnoughShares(t *testing.T) {
+	_, shares := splitTestShares(t)
+	hash := sha256.Sum256([]byte("x"))
+
+	_, err := Sign(shares[:1], recovery.ECDSA, hash[:], "m/44'/60'/0'/0/0")
+	if err == nil || !strings.Contains(err.Error(), "needs 2 signers") {
+		t.Errorf("Expected signer count error, got: %v", err)
+	}
+
+	_, err = Sign(shares[:2], recovery.ECDSA, []byte("short"), "m/44'/60'/0'/0/0")
+	if err == nil || !strings.Contains(err.Error(), "32-byte") {
+		t.Errorf("Expected hash length error, got: %v", err)
+	}
+}
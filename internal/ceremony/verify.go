@@ -0,0 +1,147 @@
+package ceremony
+
+import (
+	"crypto/elliptic"
+	"fmt"
+	"math/big"
+
+	"github.com/bnb-chain/tss-lib/v2/common"
+	tcrypto "github.com/bnb-chain/tss-lib/v2/crypto"
+	tsslib "github.com/bnb-chain/tss-lib/v2/tss"
+	"github.com/vultisig/mobile-tss-lib/tss"
+)
+
+// VerifyShares checks that a set of parties' shares represent the vault's public keys
+// It never reconstructs a private key: every party's Xi*G must match the public share
+// the others hold for it, and the public shares must interpolate to the public key.
+func VerifyShares(shares []*PartyShare) error {
+	if len(shares) == 0 {
+		return fmt.Errorf("no shares to verify")
+	}
+
+	ecdsaStates := make([]localShare, 0, len(shares))
+	eddsaStates := make([]localShare, 0, len(shares))
+	for _, share := range shares {
+		if share.ECDSA == nil || share.EdDSA == nil {
+			return fmt.Errorf("party %s is missing a local state", share.PartyID)
+		}
+		ecdsaData := share.ECDSA.ECDSALocalData
+		eddsaData := share.EdDSA.EDDSALocalData
+		ecdsaStates = append(ecdsaStates, localShare{
+			party: share.PartyID, pubKey: share.ECDSA.PubKey, xi: ecdsaData.Xi, shareID: ecdsaData.ShareID,
+			ks: ecdsaData.Ks, bigXj: ecdsaData.BigXj, pub: ecdsaData.ECDSAPub,
+		})
+		eddsaStates = append(eddsaStates, localShare{
+			party: share.PartyID, pubKey: share.EdDSA.PubKey, xi: eddsaData.Xi, shareID: eddsaData.ShareID,
+			ks: eddsaData.Ks, bigXj: eddsaData.BigXj, pub: eddsaData.EDDSAPub,
+		})
+	}
+
+	threshold, err := tss.GetThreshold(len(shares[0].ECDSA.KeygenCommitteeKeys))
+	if err != nil {
+		return fmt.Errorf("failed to get threshold: %w", err)
+	}
+
+	if err := verifyLocalShares(tsslib.S256(), "ECDSA", ecdsaStates, threshold); err != nil {
+		return err
+	}
+	return verifyLocalShares(tsslib.Edwards(), "EdDSA", eddsaStates, threshold)
+}
+
+// localShare is the curve-agnostic view of a party's save data used for verification
+type localShare struct {
+	party   string
+	pubKey  string
+	xi      *big.Int
+	shareID *big.Int
+	ks      []*big.Int
+	bigXj   []*tcrypto.ECPoint
+	pub     *tcrypto.ECPoint
+}
+
+func verifyLocalShares(curve elliptic.Curve, keyType string, shares []localShare, threshold int) error {
+	reference := shares[0]
+	if reference.pub == nil {
+		return fmt.Errorf("%s: party %s has no public key", keyType, reference.party)
+	}
+	encoded, err := tss.GetHexEncodedPubKey(reference.pub)
+	if err != nil {
+		return fmt.Errorf("%s: invalid public key: %w", keyType, err)
+	}
+	if encoded != reference.pubKey {
+		return fmt.Errorf("%s: save data public key %s does not match local state %s", keyType, encoded, reference.pubKey)
+	}
+
+	for _, share := range shares {
+		if share.pubKey != reference.pubKey || !share.pub.Equals(reference.pub) {
+			return fmt.Errorf("%s: party %s holds a different public key", keyType, share.party)
+		}
+		if len(share.ks) != len(reference.ks) || len(share.bigXj) != len(share.ks) {
+			return fmt.Errorf("%s: party %s has inconsistent share indexes", keyType, share.party)
+		}
+
+		own := -1
+		for j, k := range share.ks {
+			if k.Cmp(reference.ks[j]) != 0 || !share.bigXj[j].Equals(reference.bigXj[j]) {
+				return fmt.Errorf("%s: party %s disagrees on public share %d", keyType, share.party, j)
+			}
+			if k.Cmp(share.shareID) == 0 {
+				own = j
+			}
+		}
+		if own < 0 {
+			return fmt.Errorf("%s: party %s share ID is not in the committee", keyType, share.party)
+		}
+		if !tcrypto.ScalarBaseMult(curve, share.xi).Equals(share.bigXj[own]) {
+			return fmt.Errorf("%s: party %s secret share does not match its public share", keyType, share.party)
+		}
+	}
+
+	if len(reference.ks) < threshold+1 {
+		return fmt.Errorf("%s: %d shares cannot meet threshold %d", keyType, len(reference.ks), threshold+1)
+	}
+
+	// Interpolate the public shares in the exponent over the first t+1 indexes
+	interpolated, err := interpolatePublic(curve, reference.ks[:threshold+1], reference.bigXj[:threshold+1])
+	if err != nil {
+		return fmt.Errorf("%s: %w", keyType, err)
+	}
+	if !interpolated.Equals(reference.pub) {
+		return fmt.Errorf("%s: public shares do not interpolate to the vault public key", keyType)
+	}
+
+	return nil
+}
+
+// interpolatePublic computes sum(lambda_j * Xj) at zero
+func interpolatePublic(curve elliptic.Curve, ks []*big.Int, points []*tcrypto.ECPoint) (*tcrypto.ECPoint, error) {
+	modQ := common.ModInt(curve.Params().N)
+
+	var result *tcrypto.ECPoint
+	for i, ki := range ks {
+		lambda := big.NewInt(1)
+		for j, kj := range ks {
+			if i == j {
+				continue
+			}
+			denominator := modQ.Sub(kj, ki)
+			if denominator.Sign() == 0 {
+				return nil, fmt.Errorf("duplicate share index")
+			}
+			lambda = modQ.Mul(lambda, modQ.Mul(kj, modQ.ModInverse(denominator)))
+		}
+
+		term := points[i].ScalarMult(lambda)
+		if result == nil {
+			result = term
+			continue
+		}
+		sum, err := result.Add(term)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add public shares: %w", err)
+		}
+		result = sum
+	}
+
+	return result, nil
+}
@@ -0,0 +1,151 @@
+package evm
+
+import (
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/rowbotony/vultool/internal/signer"
+)
+
+// eip155Key and eip155Template are the example from EIP-155
+const (
+	eip155Key      = "4646464646464646464646464646464646464646464646464646464646464646"
+	eip155Template = `{
+		"chainId": 1,
+		"nonce": 9,
+		"to": "0x3535353535353535353535353535353535353535",
+		"value": "1000000000000000000",
+		"gas": 21000,
+		"gasPrice": "0x4a817c800"
+	}`
+)
+
+func keyAddress(t *testing.T, keyHex string) common.Address {
+	t.Helper()
+	key, err := ethcrypto.HexToECDSA(keyHex)
+	if err != nil {
+		t.Fatalf("HexToECDSA failed: %v", err)
+	}
+	return ethcrypto.PubkeyToAddress(key.PublicKey)
+}
+
+// TestSign_EIP155Vector - a legacy transaction matches the EIP-155 example byte for byte
+func TestSign_EIP155Vector(t *testing.T) {
+	template, err := ParseTemplate([]byte(eip155Template))
+	if err != nil {
+		t.Fatalf("ParseTemplate failed: %v", err)
+	}
+	source, err := signer.NewPrivateKey(eip155Key, nil)
+	if err != nil {
+		t.Fatalf("NewPrivateKey failed: %v", err)
+	}
+
+	signed, err := Sign(template, source, keyAddress(t, eip155Key))
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	if signed.SigningHash != "daf5a779ae972f972197303d7b574746c7ef83eadac0f2791ad23db92e4c8e53" {
+		t.Errorf("Unexpected signing hash %s", signed.SigningHash)
+	}
+	want := "0xf86c098504a817c800825208943535353535353535353535353535353535353535880de0b6b3a7640000" +
+		"8025a028ef61340bd939bc2195fe537567866003e1a15d3c71ff63e1590620aa636276a067cbe9d8997f761aecb70330" +
+		"4b3800ccf555c9f3dc64214b297fb1966a3b6d83"
+	if signed.RawTransaction != want || signed.Type != TypeLegacy {
+		t.Errorf("Unexpected signed transaction %+v", signed)
+	}
+}
+
+func TestSign_EIP1559(t *testing.T) {
+	template, err := ParseTemplate([]byte(`{
+		"chainId": "8453",
+		"nonce": "0x0",
+		"to": "0x000000000000000000000000000000000000dEaD",
+		"value": 12345,
+		"data": "0xdeadbeef",
+		"gas": 50000,
+		"maxFeePerGas": "2000000000",
+		"maxPriorityFeePerGas": "1000000"
+	}`))
+	if err != nil {
+		t.Fatalf("ParseTemplate failed: %v", err)
+	}
+	source, _ := signer.NewPrivateKey(eip155Key, nil)
+	from := keyAddress(t, eip155Key)
+
+	signed, err := Sign(template, source, from)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+
+	var tx types.Transaction
+	if err := tx.UnmarshalBinary(hexutil.MustDecode(signed.RawTransaction)); err != nil {
+		t.Fatalf("Signed transaction does not decode: %v", err)
+	}
+	sender, err := types.Sender(types.LatestSignerForChainID(big.NewInt(8453)), &tx)
+	if err != nil || sender != from {
+		t.Errorf("Sender %s, %v; want %s", sender.Hex(), err, from.Hex())
+	}
+	if tx.Type() != types.DynamicFeeTxType || tx.Hash().Hex() != signed.Hash || hex.EncodeToString(tx.Data()) != "deadbeef" {
+		t.Errorf("Unexpected transaction %+v", signed)
+	}
+
+	// A key that is not the vault's must not produce a transaction
+	other, _ := signer.NewPrivateKey(strings.Repeat("11", 32), nil)
+	if _, err := Sign(template, other, from); err == nil || !strings.Contains(err.Error(), "does not recover") {
+		t.Errorf("Expected a signer mismatch, got: %v", err)
+	}
+}
+
+// TestAttachSignature_HighS - threshold signatures may come with a high S and any recovery ID
+func TestAttachSignature_HighS(t *testing.T) {
+	template, _ := ParseTemplate([]byte(eip155Template))
+	tx, _ := template.Transaction()
+	chainID := big.NewInt(1)
+	key, _ := ethcrypto.HexToECDSA(eip155Key)
+
+	sig, err := ethcrypto.Sign(SigningHash(tx, chainID), key)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	highS := new(big.Int).Sub(ethcrypto.S256().Params().N, new(big.Int).SetBytes(sig[32:64]))
+
+	signed, err := attachSignature(tx, chainID, hex.EncodeToString(sig[:32]), hex.EncodeToString(highS.Bytes()), keyAddress(t, eip155Key))
+	if err != nil {
+		t.Fatalf("attachSignature failed: %v", err)
+	}
+	if _, s, _ := signed.RawSignatureValues(); s.Cmp(secp256k1HalfN) > 0 {
+		t.Error("Expected S to be normalised to the lower half")
+	}
+}
+
+func TestTemplate_Validation(t *testing.T) {
+	cases := map[string]string{
+		"unknown field":   `{"chainId":1,"nonce":0,"to":"0x3535353535353535353535353535353535353535","gasLimit":21000,"gasPrice":1}`,
+		"missing gas":     `{"chainId":1,"nonce":0,"to":"0x3535353535353535353535353535353535353535","gasPrice":1}`,
+		"missing fees":    `{"chainId":1,"nonce":0,"to":"0x3535353535353535353535353535353535353535","gas":21000}`,
+		"both fee kinds":  `{"chainId":1,"nonce":0,"to":"0x3535353535353535353535353535353535353535","gas":21000,"gasPrice":1,"maxFeePerGas":2,"maxPriorityFeePerGas":1}`,
+		"tip above cap":   `{"chainId":1,"nonce":0,"to":"0x3535353535353535353535353535353535353535","gas":21000,"maxFeePerGas":1,"maxPriorityFeePerGas":2}`,
+		"bad address":     `{"chainId":1,"nonce":0,"to":"0x1234","gas":21000,"gasPrice":1}`,
+		"negative value":  `{"chainId":1,"nonce":0,"to":"0x3535353535353535353535353535353535353535","value":"-1","gas":21000,"gasPrice":1}`,
+		"no to, no data":  `{"chainId":1,"nonce":0,"gas":21000,"gasPrice":1}`,
+		"unknown type":    `{"type":"blob","chainId":1,"nonce":0,"to":"0x3535353535353535353535353535353535353535","gas":21000,"gasPrice":1}`,
+		"chain ID of 0":   `{"chainId":0,"nonce":0,"to":"0x3535353535353535353535353535353535353535","gas":21000,"gasPrice":1}`,
+		"odd data length": `{"chainId":1,"nonce":0,"to":"0x3535353535353535353535353535353535353535","data":"0xabc","gas":21000,"gasPrice":1}`,
+	}
+	for name, input := range cases {
+		template, err := ParseTemplate([]byte(input))
+		if err == nil {
+			_, err = template.Transaction()
+		}
+		if err == nil {
+			t.Errorf("%s: expected the template to be rejected", name)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package evm
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/rowbotony/vultool/internal/network"
+	"github.com/rowbotony/vultool/internal/signer"
+	"github.com/rowbotony/vultool/internal/vault"
+)
+
+// DerivePath is the path every EVM chain signs with in a Vultisig vault
+const DerivePath = "m/44'/60'/0'/0/0"
+
+// secp256k1HalfN is half the curve order; signatures above it are malleable
+var secp256k1HalfN = new(big.Int).Rsh(ethcrypto.S256().Params().N, 1)
+
+// SignedTransaction is a signed transaction ready to broadcast
+type SignedTransaction struct {
+	Type           string `json:"type"`
+	ChainID        string `json:"chain_id"`
+	Nonce          uint64 `json:"nonce"`
+	From           string `json:"from"`
+	To             string `json:"to,omitempty"`
+	Value          string `json:"value"`
+	SigningHash    string `json:"signing_hash"`
+	Hash           string `json:"hash"`
+	RawTransaction string `json:"raw_transaction"`
+}
+
+// VaultAddress returns the vault's Ethereum address, shared by all EVM chains
+func VaultAddress(info *vault.VaultInfo) (common.Address, error) {
+	for _, addr := range vault.DeriveAddressesFromVault(info) {
+		if addr.Chain == "Ethereum" {
+			return common.HexToAddress(addr.Address), nil
+		}
+	}
+	return common.Address{}, fmt.Errorf("vault %s has no Ethereum address", info.Name)
+}
+
+// SigningHash returns the RLP signing hash of tx on its chain
+func SigningHash(tx *types.Transaction, chainID *big.Int) []byte {
+	return types.LatestSignerForChainID(chainID).Hash(tx).Bytes()
+}
+
+// Sign builds the template's transaction, signs it with source at DerivePath
+// and checks the signature recovers to from, the vault's address. Mainnet
+// chain IDs are refused while a test network is selected.
+func Sign(t *Template, source signer.Source, from common.Address) (*SignedTransaction, error) {
+	tx, err := t.Transaction()
+	if err != nil {
+		return nil, err
+	}
+	chainID := &t.ChainID.Int
+	if err := network.CheckEVMChainID(chainID); err != nil {
+		return nil, err
+	}
+	hash := SigningHash(tx, chainID)
+
+	sig, err := source.SignECDSA(hash, DerivePath)
+	if err != nil {
+		return nil, err
+	}
+	signed, err := attachSignature(tx, chainID, sig.R, sig.S, from)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := signed.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode signed transaction: %w", err)
+	}
+	txType := TypeEIP1559
+	if signed.Type() == types.LegacyTxType {
+		txType = TypeLegacy
+	}
+	result := &SignedTransaction{
+		Type:           txType,
+		ChainID:        chainID.String(),
+		Nonce:          signed.Nonce(),
+		From:           from.Hex(),
+		Value:          signed.Value().String(),
+		SigningHash:    hex.EncodeToString(hash),
+		Hash:           signed.Hash().Hex(),
+		RawTransaction: hexutil.Encode(raw),
+	}
+	if signed.To() != nil {
+		result.To = signed.To().Hex()
+	}
+	return result, nil
+}
+
+// attachSignature adds r and s to tx with the recovery ID that recovers to
+// from. The ID is worked out rather than trusted, and S is normalised to the
+// lower half as Ethereum requires.
+func attachSignature(tx *types.Transaction, chainID *big.Int, rHex, sHex string, from common.Address) (*types.Transaction, error) {
+	rBytes, err := hex.DecodeString(rHex)
+	if err != nil || len(rBytes) > 32 {
+		return nil, fmt.Errorf("invalid signature r")
+	}
+	sBytes, err := hex.DecodeString(sHex)
+	if err != nil || len(sBytes) > 32 {
+		return nil, fmt.Errorf("invalid signature s")
+	}
+	s := new(big.Int).SetBytes(sBytes)
+	if s.Cmp(secp256k1HalfN) > 0 {
+		s.Sub(ethcrypto.S256().Params().N, s)
+	}
+
+	ethSigner := types.LatestSignerForChainID(chainID)
+	sig := make([]byte, 65)
+	copy(sig[32-len(rBytes):32], rBytes)
+	s.FillBytes(sig[32:64])
+	for v := byte(0); v < 2; v++ {
+		sig[64] = v
+		signed, err := tx.WithSignature(ethSigner, sig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to attach signature: %w", err)
+		}
+		if sender, err := types.Sender(ethSigner, signed); err == nil && sender == from {
+			return signed, nil
+		}
+	}
+	return nil, fmt.Errorf("signature does not recover to %s; the key does not belong to this vault's Ethereum address", from.Hex())
+}
@@ -0,0 +1,166 @@
+// Package evm builds and signs EVM transactions offline
+package evm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Transaction types a template may ask for
+const (
+	TypeLegacy  = "legacy"
+	TypeEIP1559 = "eip1559"
+)
+
+// Template is an unsigned transaction as JSON
+// Quantities may be JSON numbers, decimal strings or 0x hex strings. The
+// type defaults to EIP-1559 when fee caps are given and legacy with gasPrice.
+type Template struct {
+	Type                 string    `json:"type,omitempty"`
+	ChainID              *Quantity `json:"chainId"`
+	Nonce                *Quantity `json:"nonce"`
+	To                   string    `json:"to,omitempty"`
+	Value                *Quantity `json:"value,omitempty"`
+	Data                 string    `json:"data,omitempty"`
+	Gas                  *Quantity `json:"gas"`
+	GasPrice             *Quantity `json:"gasPrice,omitempty"`
+	MaxFeePerGas         *Quantity `json:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas *Quantity `json:"maxPriorityFeePerGas,omitempty"`
+}
+
+// Quantity is a non-negative integer read from a number or a string
+type Quantity struct {
+	big.Int
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (q *Quantity) UnmarshalJSON(data []byte) error {
+	text := string(bytes.TrimSpace(data))
+	if unquoted := strings.Trim(text, `"`); len(unquoted) != len(text) {
+		text = strings.TrimSpace(unquoted)
+	}
+
+	var ok bool
+	if strings.HasPrefix(text, "0x") || strings.HasPrefix(text, "0X") {
+		_, ok = q.SetString(text[2:], 16)
+	} else {
+		_, ok = q.SetString(text, 10)
+	}
+	if !ok || q.Sign() < 0 {
+		return fmt.Errorf("invalid quantity %s", data)
+	}
+	return nil
+}
+
+// ParseTemplate reads a transaction template, rejecting unknown fields so a
+// misspelt fee field is not silently dropped
+func ParseTemplate(data []byte) (*Template, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var t Template
+	if err := decoder.Decode(&t); err != nil {
+		return nil, fmt.Errorf("invalid transaction template: %w", err)
+	}
+	return &t, nil
+}
+
+// Transaction builds the unsigned transaction the template describes
+func (t *Template) Transaction() (*types.Transaction, error) {
+	for name, q := range map[string]*Quantity{"chainId": t.ChainID, "nonce": t.Nonce, "gas": t.Gas} {
+		if q == nil {
+			return nil, fmt.Errorf("transaction template is missing %s", name)
+		}
+	}
+	if t.ChainID.Sign() == 0 {
+		return nil, fmt.Errorf("chainId must not be 0")
+	}
+	if !t.Nonce.IsUint64() || !t.Gas.IsUint64() {
+		return nil, fmt.Errorf("nonce and gas must fit in 64 bits")
+	}
+
+	var to *common.Address
+	if t.To != "" {
+		if !common.IsHexAddress(t.To) {
+			return nil, fmt.Errorf("invalid to address %q", t.To)
+		}
+		addr := common.HexToAddress(t.To)
+		to = &addr
+	}
+	value := new(big.Int)
+	if t.Value != nil {
+		value = &t.Value.Int
+	}
+	var data []byte
+	if t.Data != "" && t.Data != "0x" {
+		var err error
+		if data, err = hexutil.Decode(t.Data); err != nil {
+			return nil, fmt.Errorf("invalid data: %w", err)
+		}
+	}
+	if to == nil && len(data) == 0 {
+		return nil, fmt.Errorf("transaction template needs a to address or contract creation data")
+	}
+
+	txType, err := t.txType()
+	if err != nil {
+		return nil, err
+	}
+	if txType == TypeLegacy {
+		if t.GasPrice == nil {
+			return nil, fmt.Errorf("legacy transactions need gasPrice")
+		}
+		return types.NewTx(&types.LegacyTx{
+			Nonce:    t.Nonce.Uint64(),
+			GasPrice: &t.GasPrice.Int,
+			Gas:      t.Gas.Uint64(),
+			To:       to,
+			Value:    value,
+			Data:     data,
+		}), nil
+	}
+
+	if t.MaxFeePerGas == nil || t.MaxPriorityFeePerGas == nil {
+		return nil, fmt.Errorf("EIP-1559 transactions need maxFeePerGas and maxPriorityFeePerGas")
+	}
+	if t.MaxPriorityFeePerGas.Cmp(&t.MaxFeePerGas.Int) > 0 {
+		return nil, fmt.Errorf("maxPriorityFeePerGas is above maxFeePerGas")
+	}
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:   &t.ChainID.Int,
+		Nonce:     t.Nonce.Uint64(),
+		GasTipCap: &t.MaxPriorityFeePerGas.Int,
+		GasFeeCap: &t.MaxFeePerGas.Int,
+		Gas:       t.Gas.Uint64(),
+		To:        to,
+		Value:     value,
+		Data:      data,
+	}), nil
+}
+
+// txType resolves the explicit or implied transaction type
+func (t *Template) txType() (string, error) {
+	switch strings.ToLower(t.Type) {
+	case "legacy", "0", "0x0":
+		return TypeLegacy, nil
+	case "eip1559", "eip-1559", "2", "0x2":
+		return TypeEIP1559, nil
+	case "":
+		if t.GasPrice != nil && t.MaxFeePerGas == nil && t.MaxPriorityFeePerGas == nil {
+			return TypeLegacy, nil
+		}
+		if t.GasPrice != nil {
+			return "", fmt.Errorf("give either gasPrice (legacy) or maxFeePerGas/maxPriorityFeePerGas (EIP-1559), or set type")
+		}
+		return TypeEIP1559, nil
+	default:
+		return "", fmt.Errorf("unsupported transaction type %q (use legacy or eip1559)", t.Type)
+	}
+}
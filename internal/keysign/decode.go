@@ -0,0 +1,85 @@
+package keysign
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ulikunitz/xz"
+	v1 "github.com/vultisig/commondata/go/vultisig/keysign/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// xzMagic starts every xz stream; the apps compress keysign payloads with it
+var xzMagic = []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}
+
+// Decode parses a keysign message from what a co-signer has at hand: the
+// deeplink or QR text the apps show (vultisig://...?jsonData=...), the
+// base64 or hex of the payload, or the raw KeysignMessage protobuf. Payloads
+// may be xz-compressed the way the apps send them.
+func Decode(input []byte) (*v1.KeysignMessage, error) {
+	data, err := payloadBytes(input)
+	if err != nil {
+		return nil, err
+	}
+
+	if bytes.HasPrefix(data, xzMagic) {
+		reader, err := xz.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress keysign payload: %w", err)
+		}
+		if data, err = io.ReadAll(reader); err != nil {
+			return nil, fmt.Errorf("failed to decompress keysign payload: %w", err)
+		}
+	}
+
+	var msg v1.KeysignMessage
+	if err := proto.Unmarshal(data, &msg); err != nil {
+		return nil, fmt.Errorf("not a keysign message: %w", err)
+	}
+	if msg.GetKeysignPayload().GetCoin() == nil {
+		return nil, fmt.Errorf("keysign message has no payload to sign")
+	}
+	return &msg, nil
+}
+
+// payloadBytes unwraps the text encodings around a keysign payload
+func payloadBytes(input []byte) ([]byte, error) {
+	text := strings.TrimSpace(string(input))
+	if !utf8.Valid(input) || text == "" {
+		// Binary protobuf or xz stream as is
+		return input, nil
+	}
+
+	if strings.Contains(text, "://") || strings.Contains(text, "jsonData=") {
+		u, err := url.Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid keysign link: %w", err)
+		}
+		if kind := u.Query().Get("type"); kind != "" && kind != "SignTransaction" {
+			return nil, fmt.Errorf("link is a %s request, not a keysign", kind)
+		}
+		text = u.Query().Get("jsonData")
+		if text == "" {
+			return nil, fmt.Errorf("keysign link has no jsonData parameter")
+		}
+		// An unescaped '+' in the link reads back as a space
+		text = strings.ReplaceAll(text, " ", "+")
+	}
+
+	if data, err := hex.DecodeString(strings.TrimPrefix(text, "0x")); err == nil {
+		return data, nil
+	}
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if data, err := encoding.DecodeString(text); err == nil {
+			return data, nil
+		}
+	}
+	// Printable bytes that are not an encoding may still be a raw protobuf
+	return input, nil
+}
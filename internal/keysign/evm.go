@@ -0,0 +1,108 @@
+package keysign
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	v1 "github.com/vultisig/commondata/go/vultisig/keysign/v1"
+
+	"github.com/rowbotony/vultool/internal/evm"
+)
+
+// evmChainIDs maps the apps' EVM chain names to their chain IDs. zkSync is
+// left out: its transactions are EIP-712 (type 0x71), not EIP-1559.
+var evmChainIDs = map[string]int64{
+	"Ethereum":    1,
+	"BSC":         56,
+	"Avalanche":   43114,
+	"Polygon":     137,
+	"CronosChain": 25,
+	"Arbitrum":    42161,
+	"Optimism":    10,
+	"Base":        8453,
+	"Blast":       81457,
+}
+
+// erc20TransferSelector is the selector of transfer(address,uint256)
+var erc20TransferSelector = []byte{0xa9, 0x05, 0x9c, 0xbb}
+
+// evmHashes rebuilds the EIP-1559 transaction the apps sign for a native or
+// ERC-20 transfer and returns its signing hash. Swaps and approvals are built
+// from calldata the payload does not pin down, so they get no hash.
+func evmHashes(payload *v1.KeysignPayload) ([]SigningHash, error) {
+	if payload.GetThorchainSwapPayload() != nil || payload.GetMayachainSwapPayload() != nil ||
+		payload.GetOneinchSwapPayload() != nil || payload.GetErc20ApprovePayload() != nil {
+		return nil, nil
+	}
+	specific := payload.GetEthereumSpecific()
+	if specific == nil {
+		return nil, fmt.Errorf("%s keysign payload has no Ethereum fee section", payload.GetCoin().GetChain())
+	}
+
+	fields := map[string]string{
+		"amount":          payload.GetToAmount(),
+		"gas limit":       specific.GetGasLimit(),
+		"max fee per gas": specific.GetMaxFeePerGasWei(),
+		"priority fee":    specific.GetPriorityFee(),
+	}
+	values := make(map[string]*big.Int, len(fields))
+	for name, raw := range fields {
+		value, ok := new(big.Int).SetString(raw, 10)
+		if !ok || value.Sign() < 0 {
+			return nil, fmt.Errorf("invalid %s %q", name, raw)
+		}
+		values[name] = value
+	}
+	if !values["gas limit"].IsUint64() {
+		return nil, fmt.Errorf("invalid gas limit %s", values["gas limit"])
+	}
+	if !common.IsHexAddress(payload.GetToAddress()) {
+		return nil, fmt.Errorf("invalid recipient address %q", payload.GetToAddress())
+	}
+	recipient := common.HexToAddress(payload.GetToAddress())
+
+	chainID := big.NewInt(evmChainIDs[payload.GetCoin().GetChain()])
+	tx := &types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     uint64(specific.GetNonce()),
+		GasTipCap: values["priority fee"],
+		GasFeeCap: values["max fee per gas"],
+		Gas:       values["gas limit"].Uint64(),
+	}
+	description := "EIP-1559 transfer"
+	if payload.GetCoin().GetIsNativeToken() {
+		tx.To = &recipient
+		tx.Value = values["amount"]
+		if memo := payload.GetMemo(); memo != "" {
+			tx.Data = []byte(memo)
+		}
+	} else {
+		contract := payload.GetCoin().GetContractAddress()
+		if !common.IsHexAddress(contract) {
+			return nil, fmt.Errorf("invalid token contract %q", contract)
+		}
+		to := common.HexToAddress(contract)
+		tx.To = &to
+		tx.Value = new(big.Int)
+		tx.Data = erc20TransferData(recipient, values["amount"])
+		description = "EIP-1559 ERC-20 transfer"
+	}
+
+	hash := evm.SigningHash(types.NewTx(tx), chainID)
+	return []SigningHash{{
+		Description: fmt.Sprintf("%s (chain ID %s, nonce %d)", description, chainID, tx.Nonce),
+		KeyType:     "ECDSA",
+		DerivePath:  evm.DerivePath,
+		Hash:        hex.EncodeToString(hash),
+	}}, nil
+}
+
+// erc20TransferData encodes transfer(to, amount)
+func erc20TransferData(to common.Address, amount *big.Int) []byte {
+	data := append([]byte(nil), erc20TransferSelector...)
+	data = append(data, common.LeftPadBytes(to.Bytes(), 32)...)
+	return append(data, common.LeftPadBytes(amount.Bytes(), 32)...)
+}
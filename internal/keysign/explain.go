@@ -0,0 +1,314 @@
+// Package keysign decodes the keysign requests Vultisig apps exchange and
+// explains what a co-signer is about to approve
+package keysign
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	v1 "github.com/vultisig/commondata/go/vultisig/keysign/v1"
+
+	"github.com/rowbotony/vultool/internal/vault"
+)
+
+// Explanation is the human-readable view of a keysign request
+type Explanation struct {
+	SessionID        string        `json:"session_id,omitempty"`
+	ServiceName      string        `json:"service_name,omitempty"`
+	UseVultisigRelay bool          `json:"use_vultisig_relay"`
+	Chain            string        `json:"chain"`
+	Token            Token         `json:"token"`
+	From             string        `json:"from"`
+	To               string        `json:"to"`
+	Amount           string        `json:"amount"`
+	AmountFormatted  string        `json:"amount_formatted"`
+	Memo             string        `json:"memo,omitempty"`
+	Fees             []Detail      `json:"fees,omitempty"`
+	UTXOs            []UTXO        `json:"utxos,omitempty"`
+	Swap             []Detail      `json:"swap,omitempty"`
+	Approval         []Detail      `json:"approval,omitempty"`
+	VaultPublicKey   string        `json:"vault_public_key_ecdsa,omitempty"`
+	LocalPartyID     string        `json:"vault_local_party_id,omitempty"`
+	Hashes           []SigningHash `json:"hashes,omitempty"`
+	Notes            []string      `json:"notes,omitempty"`
+	Vault            *VaultCheck   `json:"vault,omitempty"`
+}
+
+// Token identifies the coin being moved
+type Token struct {
+	Ticker          string `json:"ticker"`
+	ContractAddress string `json:"contract_address,omitempty"`
+	Decimals        int32  `json:"decimals"`
+	IsNative        bool   `json:"is_native"`
+}
+
+// Detail is one labelled value of a chain-specific section
+type Detail struct {
+	Label string `json:"label"`
+	Value string `json:"value"`
+}
+
+// UTXO is one input the request spends
+type UTXO struct {
+	Hash   string `json:"hash"`
+	Index  uint32 `json:"index"`
+	Amount int64  `json:"amount"`
+}
+
+// SigningHash is a message the ceremony will sign; it is what `vultool sign`
+// would be given for the same transaction
+type SigningHash struct {
+	Description string `json:"description"`
+	KeyType     string `json:"key_type"`
+	DerivePath  string `json:"derive_path,omitempty"`
+	Hash        string `json:"hash"`
+}
+
+// VaultCheck reports whether the request spends from a given vault
+type VaultCheck struct {
+	Name              string `json:"name"`
+	Address           string `json:"address,omitempty"`
+	FromMatches       bool   `json:"from_matches"`
+	PublicKeyMatches  bool   `json:"public_key_matches"`
+	PublicKeyMissing  bool   `json:"public_key_missing,omitempty"` // the request names no vault key to check
+	ChainNotSupported bool   `json:"chain_not_supported,omitempty"`
+}
+
+// Explain turns a keysign message into an Explanation and computes the
+// signing hashes for the transaction kinds vultool can rebuild
+func Explain(msg *v1.KeysignMessage) (*Explanation, error) {
+	payload := msg.GetKeysignPayload()
+	coin := payload.GetCoin()
+	if coin == nil {
+		return nil, fmt.Errorf("keysign message has no payload to sign")
+	}
+
+	e := &Explanation{
+		SessionID:        msg.GetSessionId(),
+		ServiceName:      msg.GetServiceName(),
+		UseVultisigRelay: msg.GetUseVultisigRelay(),
+		Chain:            coin.GetChain(),
+		Token: Token{
+			Ticker:          coin.GetTicker(),
+			ContractAddress: coin.GetContractAddress(),
+			Decimals:        coin.GetDecimals(),
+			IsNative:        coin.GetIsNativeToken(),
+		},
+		From:            coin.GetAddress(),
+		To:              payload.GetToAddress(),
+		Amount:          payload.GetToAmount(),
+		AmountFormatted: formatUnits(payload.GetToAmount(), coin.GetDecimals()) + " " + coin.GetTicker(),
+		Memo:            payload.GetMemo(),
+		VaultPublicKey:  payload.GetVaultPublicKeyEcdsa(),
+		LocalPartyID:    payload.GetVaultLocalPartyId(),
+	}
+
+	e.Fees = feeDetails(payload)
+	for _, utxo := range payload.GetUtxoInfo() {
+		e.UTXOs = append(e.UTXOs, UTXO{Hash: utxo.GetHash(), Index: utxo.GetIndex(), Amount: utxo.GetAmount()})
+	}
+	e.Swap = swapDetails(payload)
+	if approve := payload.GetErc20ApprovePayload(); approve != nil {
+		e.Approval = []Detail{
+			{"Spender", approve.GetSpender()},
+			{"Allowance", formatUnits(approve.GetAmount(), coin.GetDecimals()) + " " + coin.GetTicker()},
+		}
+	}
+
+	if _, ok := evmChainIDs[e.Chain]; ok {
+		hashes, err := evmHashes(payload)
+		if err != nil {
+			return nil, err
+		}
+		e.Hashes = hashes
+	}
+	switch {
+	case len(e.Hashes) > 0:
+	case e.Chain == "Zksync":
+		e.Notes = append(e.Notes, "zkSync transactions are EIP-712 (type 0x71) and their signing hash is not computed; compare the fields above with the co-signing device")
+	case payload.GetUtxoSpecific() != nil:
+		e.Notes = append(e.Notes, fmt.Sprintf("UTXO sighashes are not computed: the apps choose inputs and add change when they build the %s transaction, which the payload does not record; one sighash per spent input will be signed", e.Chain))
+	default:
+		e.Notes = append(e.Notes, fmt.Sprintf("vultool cannot rebuild this %s transaction, so its signing hashes are not shown; compare the fields above with the co-signing device", e.Chain))
+	}
+	return e, nil
+}
+
+// CheckVault checks the request's from address and vault key against a vault
+func CheckVault(e *Explanation, info *vault.VaultInfo) *VaultCheck {
+	check := &VaultCheck{
+		Name:             info.Name,
+		PublicKeyMatches: e.VaultPublicKey != "" && strings.EqualFold(e.VaultPublicKey, info.PublicKeyECDSA),
+		PublicKeyMissing: e.VaultPublicKey == "",
+		// Chains vultool does not derive cannot be confirmed
+		ChainNotSupported: true,
+	}
+	for _, addr := range vault.DeriveAddressesFromVault(info) {
+		if chainKey(addr.Chain) != chainKey(e.Chain) {
+			continue
+		}
+		check.ChainNotSupported = false
+		check.Address = addr.Address
+		check.FromMatches = addressEqual(addr.Address, e.From)
+	}
+	return check
+}
+
+// chainKey folds the apps' and vultool's spellings of a chain name together
+// (BitcoinCash vs Bitcoin-Cash, Sui vs SUI)
+func chainKey(chain string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", "_", "", " ", "").Replace(chain))
+}
+
+// addressEqual compares addresses, ignoring case only for hex (EVM) addresses
+func addressEqual(a, b string) bool {
+	if strings.HasPrefix(a, "0x") && strings.HasPrefix(b, "0x") {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
+
+// feeDetails lists the gas and fee fields of the chain-specific section
+func feeDetails(payload *v1.KeysignPayload) []Detail {
+	switch {
+	case payload.GetUtxoSpecific() != nil:
+		s := payload.GetUtxoSpecific()
+		return []Detail{
+			{"Fee rate", s.GetByteFee() + " sat/vbyte"},
+			{"Send max", fmt.Sprint(s.GetSendMaxAmount())},
+		}
+	case payload.GetEthereumSpecific() != nil:
+		s := payload.GetEthereumSpecific()
+		return []Detail{
+			{"Nonce", fmt.Sprint(s.GetNonce())},
+			{"Gas limit", s.GetGasLimit()},
+			{"Max fee per gas", formatUnits(s.GetMaxFeePerGasWei(), 9) + " gwei"},
+			{"Priority fee", formatUnits(s.GetPriorityFee(), 9) + " gwei"},
+			{"Max network fee (native coin)", formatUnits(mulString(s.GetMaxFeePerGasWei(), s.GetGasLimit()), 18)},
+		}
+	case payload.GetThorchainSpecific() != nil:
+		s := payload.GetThorchainSpecific()
+		return []Detail{
+			{"Account number", fmt.Sprint(s.GetAccountNumber())},
+			{"Sequence", fmt.Sprint(s.GetSequence())},
+			{"Fee", fmt.Sprint(s.GetFee())},
+			{"Deposit", fmt.Sprint(s.GetIsDeposit())},
+		}
+	case payload.GetMayaSpecific() != nil:
+		s := payload.GetMayaSpecific()
+		return []Detail{
+			{"Account number", fmt.Sprint(s.GetAccountNumber())},
+			{"Sequence", fmt.Sprint(s.GetSequence())},
+			{"Deposit", fmt.Sprint(s.GetIsDeposit())},
+		}
+	case payload.GetCosmosSpecific() != nil:
+		s := payload.GetCosmosSpecific()
+		return []Detail{
+			{"Account number", fmt.Sprint(s.GetAccountNumber())},
+			{"Sequence", fmt.Sprint(s.GetSequence())},
+			{"Gas", fmt.Sprint(s.GetGas())},
+			{"Transaction type", s.GetTransactionType().String()},
+		}
+	case payload.GetSolanaSpecific() != nil:
+		s := payload.GetSolanaSpecific()
+		details := []Detail{
+			{"Recent block hash", s.GetRecentBlockHash()},
+			{"Priority fee", s.GetPriorityFee() + " microlamports"},
+		}
+		if s.FromTokenAssociatedAddress != nil {
+			details = append(details, Detail{"From token account", s.GetFromTokenAssociatedAddress()})
+		}
+		if s.ToTokenAssociatedAddress != nil {
+			details = append(details, Detail{"To token account", s.GetToTokenAssociatedAddress()})
+		}
+		return details
+	case payload.GetPolkadotSpecific() != nil:
+		s := payload.GetPolkadotSpecific()
+		return []Detail{
+			{"Nonce", fmt.Sprint(s.GetNonce())},
+			{"Recent block hash", s.GetRecentBlockHash()},
+			{"Block number", s.GetCurrentBlockNumber()},
+			{"Spec version", fmt.Sprint(s.GetSpecVersion())},
+			{"Transaction version", fmt.Sprint(s.GetTransactionVersion())},
+			{"Genesis hash", s.GetGenesisHash()},
+		}
+	case payload.GetSuicheSpecific() != nil:
+		s := payload.GetSuicheSpecific()
+		return []Detail{
+			{"Reference gas price", s.GetReferenceGasPrice()},
+			{"Coins", fmt.Sprint(len(s.GetCoins()))},
+		}
+	}
+	return nil
+}
+
+// swapDetails describes a THORChain, MayaChain or 1inch swap attached to the request
+func swapDetails(payload *v1.KeysignPayload) []Detail {
+	thor := payload.GetThorchainSwapPayload()
+	provider := "THORChain"
+	if thor == nil {
+		thor = payload.GetMayachainSwapPayload()
+		provider = "MayaChain"
+	}
+	if thor != nil {
+		details := []Detail{
+			{"Provider", provider},
+			{"Sell", formatUnits(thor.GetFromAmount(), thor.GetFromCoin().GetDecimals()) + " " + thor.GetFromCoin().GetTicker()},
+			{"Buy (expected)", thor.GetToAmountDecimal() + " " + thor.GetToCoin().GetTicker()},
+			{"Buy limit", thor.GetToAmountLimit()},
+			{"Inbound vault", thor.GetVaultAddress()},
+		}
+		if thor.RouterAddress != nil {
+			details = append(details, Detail{"Router", thor.GetRouterAddress()})
+		}
+		return details
+	}
+
+	if oneInch := payload.GetOneinchSwapPayload(); oneInch != nil {
+		tx := oneInch.GetQuote().GetTx()
+		return []Detail{
+			{"Provider", "1inch"},
+			{"Sell", formatUnits(oneInch.GetFromAmount(), oneInch.GetFromCoin().GetDecimals()) + " " + oneInch.GetFromCoin().GetTicker()},
+			{"Buy (expected)", oneInch.GetToAmountDecimal() + " " + oneInch.GetToCoin().GetTicker()},
+			{"Contract", tx.GetTo()},
+			{"Value", tx.GetValue()},
+			{"Call data", tx.GetData()},
+		}
+	}
+	return nil
+}
+
+// formatUnits renders an integer amount of base units with the coin's decimals
+func formatUnits(raw string, decimals int32) string {
+	amount, ok := new(big.Int).SetString(raw, 10)
+	if !ok {
+		return raw
+	}
+	if decimals <= 0 {
+		return amount.String()
+	}
+
+	digits := new(big.Int).Abs(amount).String()
+	if len(digits) <= int(decimals) {
+		digits = strings.Repeat("0", int(decimals)-len(digits)+1) + digits
+	}
+	whole, fraction := digits[:len(digits)-int(decimals)], strings.TrimRight(digits[len(digits)-int(decimals):], "0")
+	if amount.Sign() < 0 {
+		whole = "-" + whole
+	}
+	if fraction == "" {
+		return whole
+	}
+	return whole + "." + fraction
+}
+
+// mulString multiplies two decimal strings, returning "" when either is invalid
+func mulString(a, b string) string {
+	x, okX := new(big.Int).SetString(a, 10)
+	y, okY := new(big.Int).SetString(b, 10)
+	if !okX || !okY {
+		return ""
+	}
+	return new(big.Int).Mul(x, y).String()
+}
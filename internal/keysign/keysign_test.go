@@ -0,0 +1,221 @@
+package keysign
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ulikunitz/xz"
+	v1 "github.com/vultisig/commondata/go/vultisig/keysign/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/rowbotony/vultool/internal/vault"
+)
+
+// testVault is a vault whose ECDSA key is the secp256k1 generator
+var testVault = &vault.VaultInfo{
+	Name:           "test-vault",
+	PublicKeyECDSA: "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798",
+	PublicKeyEDDSA: "3b6a27bcceb6a42d62a3a8d02a6f0d73653215771de243a63ac048a18b59da29",
+	HexChainCode:   strings.Repeat("00", 32),
+}
+
+func vaultAddress(t *testing.T, chain string) string {
+	t.Helper()
+	for _, addr := range vault.DeriveAddressesFromVault(testVault) {
+		if addr.Chain == chain {
+			return addr.Address
+		}
+	}
+	t.Fatalf("no %s address for the test vault", chain)
+	return ""
+}
+
+func erc20Message(t *testing.T) *v1.KeysignMessage {
+	return &v1.KeysignMessage{
+		SessionId:        "3a9c4c2e-session",
+		ServiceName:      "VultisigApp-1234",
+		EncryptionKeyHex: strings.Repeat("ab", 32),
+		KeysignPayload: &v1.KeysignPayload{
+			Coin: &v1.Coin{
+				Chain:           "Ethereum",
+				Ticker:          "USDC",
+				Address:         vaultAddress(t, "Ethereum"),
+				ContractAddress: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48",
+				Decimals:        6,
+			},
+			ToAddress: "0x000000000000000000000000000000000000dEaD",
+			ToAmount:  "1250000",
+			BlockchainSpecific: &v1.KeysignPayload_EthereumSpecific{EthereumSpecific: &v1.EthereumSpecific{
+				MaxFeePerGasWei: "30000000000",
+				PriorityFee:     "1000000000",
+				Nonce:           7,
+				GasLimit:        "120000",
+			}},
+			VaultPublicKeyEcdsa: testVault.PublicKeyECDSA,
+			VaultLocalPartyId:   "iPhone-5C9",
+		},
+	}
+}
+
+// deeplink encodes a message the way the apps put it in a keysign QR code
+func deeplink(t *testing.T, msg *v1.KeysignMessage) string {
+	t.Helper()
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var compressed bytes.Buffer
+	w, err := xz.NewWriter(&compressed)
+	if err != nil {
+		t.Fatalf("xz.NewWriter failed: %v", err)
+	}
+	_, _ = w.Write(data)
+	if err := w.Close(); err != nil {
+		t.Fatalf("xz Close failed: %v", err)
+	}
+	return "vultisig://vultisig.com?type=SignTransaction&vault=" + testVault.PublicKeyECDSA +
+		"&jsonData=" + base64.StdEncoding.EncodeToString(compressed.Bytes())
+}
+
+func TestDecode_Encodings(t *testing.T) {
+	msg := erc20Message(t)
+	raw, _ := proto.Marshal(msg)
+
+	inputs := map[string][]byte{
+		"deeplink": []byte(deeplink(t, msg) + "\n"),
+		"raw":      raw,
+		"hex":      []byte(hex.EncodeToString(raw)),
+		"base64":   []byte(base64.StdEncoding.EncodeToString(raw)),
+	}
+	for name, input := range inputs {
+		decoded, err := Decode(input)
+		if err != nil {
+			t.Errorf("%s: Decode failed: %v", name, err)
+			continue
+		}
+		if !proto.Equal(decoded, msg) {
+			t.Errorf("%s: decoded message differs", name)
+		}
+	}
+
+	if _, err := Decode([]byte("vultisig://vultisig.com?type=NewVault&jsonData=AAAA")); err == nil {
+		t.Error("Expected a keygen link to be rejected")
+	}
+	if _, err := Decode([]byte("not a keysign payload")); err == nil {
+		t.Error("Expected garbage to be rejected")
+	}
+}
+
+func TestExplain_ERC20(t *testing.T) {
+	msg := erc20Message(t)
+	e, err := Explain(msg)
+	if err != nil {
+		t.Fatalf("Explain failed: %v", err)
+	}
+	if e.AmountFormatted != "1.25 USDC" || e.To != "0x000000000000000000000000000000000000dEaD" || e.Token.IsNative {
+		t.Errorf("Unexpected explanation: %+v", e)
+	}
+	if len(e.Hashes) != 1 || e.Hashes[0].DerivePath != "m/44'/60'/0'/0/0" {
+		t.Fatalf("Expected one EVM signing hash, got %+v", e.Hashes)
+	}
+
+	// The hash signs a call to the token contract with transfer(to, amount)
+	contract := common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+	data, _ := hex.DecodeString("a9059cbb" +
+		"000000000000000000000000000000000000000000000000000000000000dead" +
+		"00000000000000000000000000000000000000000000000000000000001312d0")
+	want := types.LatestSignerForChainID(big.NewInt(1)).Hash(types.NewTx(&types.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		Nonce:     7,
+		GasTipCap: big.NewInt(1_000_000_000),
+		GasFeeCap: big.NewInt(30_000_000_000),
+		Gas:       120000,
+		To:        &contract,
+		Value:     new(big.Int),
+		Data:      data,
+	}))
+	if e.Hashes[0].Hash != hex.EncodeToString(want[:]) {
+		t.Errorf("Signing hash %s, want %x", e.Hashes[0].Hash, want)
+	}
+
+	check := CheckVault(e, testVault)
+	if !check.FromMatches || !check.PublicKeyMatches || check.ChainNotSupported {
+		t.Errorf("Expected the request to belong to the vault: %+v", check)
+	}
+}
+
+func TestExplain_Zksync(t *testing.T) {
+	msg := erc20Message(t)
+	msg.KeysignPayload.Coin.Chain = "Zksync"
+	e, err := Explain(msg)
+	if err != nil {
+		t.Fatalf("Explain failed: %v", err)
+	}
+	// zkSync signs EIP-712 transactions, so an EIP-1559 hash would be wrong
+	if len(e.Hashes) != 0 || len(e.Notes) != 1 || !strings.Contains(e.Notes[0], "EIP-712") {
+		t.Errorf("Expected no hash and an EIP-712 note, got %+v %q", e.Hashes, e.Notes)
+	}
+}
+
+func TestExplain_ForeignSender(t *testing.T) {
+	msg := erc20Message(t)
+	msg.KeysignPayload.Coin.Address = "0x1111111111111111111111111111111111111111"
+	e, err := Explain(msg)
+	if err != nil {
+		t.Fatalf("Explain failed: %v", err)
+	}
+	if check := CheckVault(e, testVault); check.FromMatches {
+		t.Errorf("A sender outside the vault must not match: %+v", check)
+	}
+}
+
+func TestExplain_UTXO(t *testing.T) {
+	msg := &v1.KeysignMessage{KeysignPayload: &v1.KeysignPayload{
+		Coin:      &v1.Coin{Chain: "BitcoinCash", Ticker: "BCH", Address: vaultAddress(t, "Bitcoin-Cash"), Decimals: 8, IsNativeToken: true},
+		ToAddress: "bitcoincash:qr6m7j9njldwwzlg9v7v53unlr4jkmx6eylep8ekg2",
+		ToAmount:  "150000000",
+		BlockchainSpecific: &v1.KeysignPayload_UtxoSpecific{UtxoSpecific: &v1.UTXOSpecific{
+			ByteFee: "3",
+		}},
+		UtxoInfo: []*v1.UtxoInfo{{Hash: strings.Repeat("11", 32), Amount: 200000000, Index: 1}},
+	}}
+	e, err := Explain(msg)
+	if err != nil {
+		t.Fatalf("Explain failed: %v", err)
+	}
+	if e.AmountFormatted != "1.5 BCH" || len(e.UTXOs) != 1 || len(e.Hashes) != 0 || len(e.Notes) != 1 {
+		t.Errorf("Unexpected explanation: %+v", e)
+	}
+	if !strings.HasPrefix(e.Notes[0], "UTXO sighashes are not computed") {
+		t.Errorf("Expected the note to say UTXO sighashes are not computed, got %q", e.Notes[0])
+	}
+	check := CheckVault(e, testVault)
+	if !check.FromMatches {
+		t.Errorf("Expected the BCH sender to match the vault: %+v", check)
+	}
+	// The payload names no vault key, so it must not be reported as checked
+	if check.PublicKeyMatches || !check.PublicKeyMissing {
+		t.Errorf("Expected the missing vault key to be reported: %+v", check)
+	}
+}
+
+func TestFormatUnits(t *testing.T) {
+	cases := map[string]string{
+		"0":                   "0",
+		"1":                   "0.000000000000000001",
+		"1000000000000000000": "1",
+		"1234500000000000000": "1.2345",
+		"not a number":        "not a number",
+	}
+	for raw, want := range cases {
+		if got := formatUnits(raw, 18); got != want {
+			t.Errorf("formatUnits(%q) = %q, want %q", raw, got, want)
+		}
+	}
+}
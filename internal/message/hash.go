@@ -0,0 +1,177 @@
+// Package message signs and verifies off-chain messages with vault keys:
+// Bitcoin (BIP-137, BIP-322), Ethereum (EIP-191, EIP-712), Solana off-chain
+// messages and raw Ed25519 for Solana and SUI
+package message
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/btcsuite/btcd/btcutil/base58"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// Message formats
+const (
+	FormatBIP137  = "bip137"
+	FormatBIP322  = "bip322"
+	FormatEIP191  = "eip191"
+	FormatEIP712  = "eip712"
+	FormatEd25519 = "ed25519"
+	FormatSolana  = "solana"
+)
+
+// Formats lists the formats in the order commands present them
+var Formats = []string{FormatBIP137, FormatBIP322, FormatEIP191, FormatEIP712, FormatEd25519, FormatSolana}
+
+const bitcoinMessageMagic = "Bitcoin Signed Message:\n"
+
+// bip322Tag is the BIP-340 tag the BIP-322 message hash is computed with
+var bip322Tag = []byte("BIP0322-signed-message")
+
+// BitcoinMessageHash is the double SHA-256 Bitcoin Core signs messages with
+func BitcoinMessageHash(message []byte) []byte {
+	var b bytes.Buffer
+	_ = wire.WriteVarString(&b, 0, bitcoinMessageMagic)
+	_ = wire.WriteVarBytes(&b, 0, message)
+	return chainhash.DoubleHashB(b.Bytes())
+}
+
+// EIP191Hash is the personal_sign hash of message
+func EIP191Hash(message []byte) []byte {
+	return accounts.TextHash(message)
+}
+
+// EIP712Hash is the hash of EIP-712 typed data given as JSON, as
+// eth_signTypedData_v4 signs it
+func EIP712Hash(typedData []byte) ([]byte, error) {
+	var data apitypes.TypedData
+	if err := json.Unmarshal(typedData, &data); err != nil {
+		return nil, fmt.Errorf("invalid EIP-712 typed data: %w", err)
+	}
+	hash, _, err := apitypes.TypedDataAndHash(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid EIP-712 typed data: %w", err)
+	}
+	return hash, nil
+}
+
+// solanaSigningDomain starts every Solana off-chain message
+var solanaSigningDomain = []byte("\xffsolana offchain")
+
+// Solana off-chain message formats and the lengths they allow; the ledger
+// limit keeps a message within one packet
+const (
+	solanaRestrictedASCII = 0
+	solanaLimitedUTF8     = 1
+	solanaExtendedUTF8    = 2
+
+	solanaHeaderLen    = 16 + 1 + 1 + 2 // signing domain, version, format, length
+	solanaMaxLen       = 0xffff - solanaHeaderLen
+	solanaMaxLedgerLen = 1232 - solanaHeaderLen
+)
+
+// SolanaOffchainMessage serializes message as a version 0 Solana off-chain
+// message, the bytes `solana sign-offchain-message` signs
+func SolanaOffchainMessage(message []byte) ([]byte, error) {
+	if len(message) == 0 {
+		return nil, fmt.Errorf("Solana off-chain messages cannot be empty")
+	}
+	var format byte
+	switch {
+	case len(message) <= solanaMaxLedgerLen && isPrintableASCII(message):
+		format = solanaRestrictedASCII
+	case len(message) <= solanaMaxLedgerLen && utf8.Valid(message):
+		format = solanaLimitedUTF8
+	case len(message) <= solanaMaxLen && utf8.Valid(message):
+		format = solanaExtendedUTF8
+	default:
+		return nil, fmt.Errorf("Solana off-chain messages must be UTF-8 and at most %d bytes", solanaMaxLen)
+	}
+
+	var b bytes.Buffer
+	b.Write(solanaSigningDomain)
+	b.WriteByte(0) // version
+	b.WriteByte(format)
+	_ = binary.Write(&b, binary.LittleEndian, uint16(len(message)))
+	b.Write(message)
+	return b.Bytes(), nil
+}
+
+func isPrintableASCII(message []byte) bool {
+	for _, c := range message {
+		if c < 0x20 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// bip322Hash is the tagged hash BIP-322 commits to in to_spend
+func bip322Hash(message []byte) []byte {
+	tag := sha256.Sum256(bip322Tag)
+	h := sha256.New()
+	h.Write(tag[:])
+	h.Write(tag[:])
+	h.Write(message)
+	return h.Sum(nil)
+}
+
+// bip322Transactions builds the virtual to_spend and to_sign transactions of
+// a BIP-322 signature for an address with output script pkScript
+func bip322Transactions(message, pkScript []byte) (*wire.MsgTx, *wire.MsgTx) {
+	toSpend := wire.NewMsgTx(0)
+	scriptSig, _ := txscript.NewScriptBuilder().AddOp(txscript.OP_0).AddData(bip322Hash(message)).Script()
+	toSpend.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{Index: 0xffffffff},
+		SignatureScript:  scriptSig,
+		Sequence:         0,
+	})
+	toSpend.AddTxOut(wire.NewTxOut(0, pkScript))
+
+	toSign := wire.NewMsgTx(0)
+	toSign.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{Hash: toSpend.TxHash(), Index: 0},
+		Sequence:         0,
+	})
+	toSign.AddTxOut(wire.NewTxOut(0, []byte{txscript.OP_RETURN}))
+	return toSpend, toSign
+}
+
+// DecodeSignature reads a signature given as hex, base64 or base58
+// Base58 text can also read as base64, so when sizes are given the first
+// decoding of one of those sizes wins.
+func DecodeSignature(input string, sizes ...int) ([]byte, error) {
+	text := strings.TrimSpace(input)
+	var candidates [][]byte
+	if sig, err := hex.DecodeString(strings.TrimPrefix(text, "0x")); err == nil && len(sig) > 0 {
+		candidates = append(candidates, sig)
+	}
+	if sig, err := base64.StdEncoding.DecodeString(text); err == nil && len(sig) > 0 {
+		candidates = append(candidates, sig)
+	}
+	if sig := base58.Decode(text); len(sig) > 0 {
+		candidates = append(candidates, sig)
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("signature is neither hex, base64 nor base58")
+	}
+	for _, sig := range candidates {
+		if len(sizes) == 0 || slices.Contains(sizes, len(sig)) {
+			return sig, nil
+		}
+	}
+	return candidates[0], nil
+}
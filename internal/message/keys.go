@@ -0,0 +1,92 @@
+package message
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/txscript"
+
+	"github.com/rowbotony/vultool/internal/network"
+	"github.com/rowbotony/vultool/internal/vault"
+)
+
+// commonIndexes is how many addresses per branch are searched when no path is given
+const commonIndexes = 20
+
+// Script types a single Bitcoin key signs messages for
+const (
+	ScriptP2WPKH     = "p2wpkh"
+	ScriptP2SHP2WPKH = "p2sh-p2wpkh"
+	ScriptP2PKH      = "p2pkh"
+)
+
+// CommonPaths lists where wallets put the keys that sign messages of a
+// format: BIP84, BIP49 and BIP44 receive and change addresses for Bitcoin,
+// and the first accounts of the usual Ethereum layouts for EVM
+func CommonPaths(format string) []string {
+	var paths []string
+	switch format {
+	case FormatBIP137, FormatBIP322:
+		for _, purpose := range []int{84, 49, 44} {
+			for change := 0; change < 2; change++ {
+				for index := 0; index < commonIndexes; index++ {
+					paths = append(paths, fmt.Sprintf("m/%d'/0'/0'/%d/%d", purpose, change, index))
+				}
+			}
+		}
+	case FormatEIP191, FormatEIP712:
+		for index := 0; index < commonIndexes; index++ {
+			paths = append(paths, fmt.Sprintf("m/44'/60'/0'/0/%d", index))
+		}
+		// Ledger Live numbers accounts instead of addresses
+		for account := 1; account < commonIndexes; account++ {
+			paths = append(paths, fmt.Sprintf("m/44'/60'/%d'/0/0", account))
+		}
+	}
+	return paths
+}
+
+// vaultPathOf returns the first path at which the vault's ECDSA key is
+// pubKey, or "" when none is
+func vaultPathOf(info *vault.VaultInfo, pubKey *btcec.PublicKey, paths []string) (string, error) {
+	for _, path := range paths {
+		derived, err := vault.DerivePublicKey(info, path)
+		if err != nil {
+			return "", err
+		}
+		if derived.IsEqual(pubKey) {
+			return path, nil
+		}
+	}
+	return "", nil
+}
+
+// vaultPathOfScript returns the first path at which one of the vault's keys
+// is paid by pkScript, with the key
+func vaultPathOfScript(info *vault.VaultInfo, pkScript []byte, paths []string) (string, *btcec.PublicKey, error) {
+	for _, path := range paths {
+		pubKey, err := vault.DerivePublicKey(info, path)
+		if err != nil {
+			return "", nil, err
+		}
+		for _, addr := range keyAddresses(pubKey) {
+			if script, _ := txscript.PayToAddrScript(addr); bytes.Equal(script, pkScript) {
+				return path, pubKey, nil
+			}
+		}
+	}
+	return "", nil, nil
+}
+
+// keyAddresses returns the Bitcoin addresses a compressed key signs for
+func keyAddresses(pubKey *btcec.PublicKey) map[string]btcutil.Address {
+	net := network.BitcoinParams()
+	hash := btcutil.Hash160(pubKey.SerializeCompressed())
+	p2wpkh, _ := btcutil.NewAddressWitnessPubKeyHash(hash, net)
+	witnessScript, _ := txscript.PayToAddrScript(p2wpkh)
+	p2sh, _ := btcutil.NewAddressScriptHash(witnessScript, net)
+	p2pkh, _ := btcutil.NewAddressPubKeyHash(hash, net)
+	return map[string]btcutil.Address{ScriptP2WPKH: p2wpkh, ScriptP2SHP2WPKH: p2sh, ScriptP2PKH: p2pkh}
+}
@@ -0,0 +1,263 @@
+package message
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil/base58"
+
+	"github.com/rowbotony/vultool/internal/signer"
+	"github.com/rowbotony/vultool/internal/vault"
+)
+
+const (
+	testRootKey = "1b8254db800dcef00fd23060c813b0304430377afc65c3e34c7fd65f42f5aeab"
+	// EdDSA key and public key of a 2-of-2 test vault
+	testScalar = "0fa1aba8a381103f1c689a46f8016f925c0977ea35d218edc2458978ff208c65"
+	testEdDSA  = "db977878c6e106e9a93145dbe52580a06ebb1af733a0ad65ed009ea54b014a5b"
+)
+
+// The EIP-712 specification's example and the signature of keccak256("cow")
+const (
+	mailTypedData = `{"types":{"EIP712Domain":[{"name":"name","type":"string"},{"name":"version","type":"string"},{"name":"chainId","type":"uint256"},{"name":"verifyingContract","type":"address"}],"Person":[{"name":"name","type":"string"},{"name":"wallet","type":"address"}],"Mail":[{"name":"from","type":"Person"},{"name":"to","type":"Person"},{"name":"contents","type":"string"}]},"primaryType":"Mail","domain":{"name":"Ether Mail","version":"1","chainId":1,"verifyingContract":"0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"},"message":{"from":{"name":"Cow","wallet":"0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"},"to":{"name":"Bob","wallet":"0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"},"contents":"Hello, Bob!"}}`
+	mailSignature = "0x4355c47d63924e8a72e509b65029052eb6c299d53a04e167c5775fd466751c9d07299936d304c153f6443dfa05f40ff007d72911b6f72307f996231605b915621c"
+)
+
+func testVault(t *testing.T) *vault.VaultInfo {
+	t.Helper()
+	keyBytes, _ := hex.DecodeString(testRootKey)
+	_, pub := btcec.PrivKeyFromBytes(keyBytes)
+	return &vault.VaultInfo{
+		Name:           "message-test",
+		PublicKeyECDSA: hex.EncodeToString(pub.SerializeCompressed()),
+		PublicKeyEDDSA: testEdDSA,
+		HexChainCode:   strings.Repeat("42", 32),
+	}
+}
+
+// vaultSignature signs hash with the vault key at path as r || s || v
+func vaultSignature(t *testing.T, info *vault.VaultInfo, hash []byte, path string) []byte {
+	t.Helper()
+	source, err := signer.NewPrivateKey(testRootKey, info)
+	if err != nil {
+		t.Fatalf("NewPrivateKey failed: %v", err)
+	}
+	result, err := source.SignECDSA(hash, path)
+	if err != nil {
+		t.Fatalf("SignECDSA failed: %v", err)
+	}
+	sig, _ := hex.DecodeString(result.Signature)
+	return append(sig, byte(*result.V))
+}
+
+// TestVerify_BIP322Vectors - the test vectors of the BIP
+func TestVerify_BIP322Vectors(t *testing.T) {
+	if got := hex.EncodeToString(bip322Hash([]byte("Hello World"))); got != "f0eb03b1a75ac6d9847f55c624a99169b5dccba2a31f5b23bea77ba270de0a7a" {
+		t.Errorf("Unexpected message hash %s", got)
+	}
+
+	info := testVault(t)
+	for message, sig := range map[string]string{
+		"":            "AkcwRAIgM2gBAQqvZX15ZiysmKmQpDrG83avLIT492QBzLnQIxYCIBaTpOaD20qRlEylyxFSeEA2ba9YOixpX8z46TSDtS40ASECx/EgAxlkQpQ9hYjgGu6EBCPMVPwVIVJqO4XCsMvViHI=",
+		"Hello World": "AkcwRAIgZRfIY3p7/DoVTty6YZbWS71bc5Vct9p9Fia83eRmw2QCICK/ENGfwLtptFluMGs2KsqoNSk89pO7F29zJLUx9a/sASECx/EgAxlkQpQ9hYjgGu6EBCPMVPwVIVJqO4XCsMvViHI=",
+	} {
+		req := &Request{Format: FormatBIP322, Message: []byte(message), Signature: sig, Address: "bc1q9vza2e8x573nczrlzms0wvx3gsqjx7vavgkx0l"}
+		result, err := Verify(req, info)
+		if err != nil {
+			t.Fatalf("Verify failed: %v", err)
+		}
+		// Valid, but not the vault's
+		if !result.Valid || result.VaultKey || result.Verified() {
+			t.Errorf("%q: unexpected result %+v", message, result)
+		}
+	}
+
+	// The signature of one message does not verify another
+	req := &Request{Format: FormatBIP322, Message: []byte("Hello World!"), Address: "bc1q9vza2e8x573nczrlzms0wvx3gsqjx7vavgkx0l",
+		Signature: "AkcwRAIgZRfIY3p7/DoVTty6YZbWS71bc5Vct9p9Fia83eRmw2QCICK/ENGfwLtptFluMGs2KsqoNSk89pO7F29zJLUx9a/sASECx/EgAxlkQpQ9hYjgGu6EBCPMVPwVIVJqO4XCsMvViHI="}
+	if result, err := Verify(req, info); err != nil || result.Valid {
+		t.Errorf("Expected the signature to fail for another message, got %+v, %v", result, err)
+	}
+}
+
+func TestVerify_BIP137VaultKey(t *testing.T) {
+	info := testVault(t)
+	message := []byte("proof of reserves 2026-10-18")
+	path := "m/49'/0'/0'/1/4"
+	rsv := vaultSignature(t, info, BitcoinMessageHash(message), path)
+	// P2SH-P2WPKH header range
+	sig := append([]byte{35 + rsv[64]}, rsv[:64]...)
+
+	result, err := Verify(&Request{Format: FormatBIP137, Message: message, Signature: base64.StdEncoding.EncodeToString(sig)}, info)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if !result.Verified() || result.DerivePath != path || result.ScriptType != ScriptP2SHP2WPKH || !strings.HasPrefix(result.Signer, "3") {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	// Electrum-style P2PKH header for the key's SegWit address
+	pubKey, _ := vault.DerivePublicKey(info, path)
+	segwit := keyAddresses(pubKey)[ScriptP2WPKH].EncodeAddress()
+	sig[0] = 31 + rsv[64]
+	result, _ = Verify(&Request{Format: FormatBIP137, Message: message, Signature: base64.StdEncoding.EncodeToString(sig), Address: segwit}, info)
+	if !result.Verified() || result.ScriptType != ScriptP2WPKH {
+		t.Errorf("Unexpected result for %s: %+v", segwit, result)
+	}
+
+	// Outside the searched paths the key is not recognised
+	result, _ = Verify(&Request{Format: FormatBIP137, Message: message, Signature: base64.StdEncoding.EncodeToString(sig), Paths: []string{"m/84'/0'/0'/0/0"}}, info)
+	if !result.Valid || result.VaultKey {
+		t.Errorf("Unexpected result with an explicit path: %+v", result)
+	}
+	result, _ = Verify(&Request{Format: FormatBIP137, Message: message, Signature: base64.StdEncoding.EncodeToString(sig), Address: "1BoatSLRHtKNngkdXEeobR76b53LETtpyT"}, info)
+	if result.Valid || !strings.Contains(result.Reason, "not 1BoatSLRHtKNngkdXEeobR76b53LETtpyT") {
+		t.Errorf("Expected another address to be rejected, got %+v", result)
+	}
+}
+
+func TestVerify_EVM(t *testing.T) {
+	info := testVault(t)
+	result, err := Verify(&Request{Format: FormatEIP712, Message: []byte(mailTypedData), Signature: mailSignature}, info)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if !result.Valid || result.VaultKey || result.Signer != "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826" ||
+		result.Hash != "be609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2" {
+		t.Errorf("Unexpected EIP-712 result: %+v", result)
+	}
+
+	message := []byte("I control this vault")
+	sig := vaultSignature(t, info, EIP191Hash(message), "m/44'/60'/3'/0/0")
+	sig[64] += 27
+	result, err = Verify(&Request{Format: FormatEIP191, Message: message, Signature: hex.EncodeToString(sig)}, info)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if !result.Verified() || result.DerivePath != "m/44'/60'/3'/0/0" {
+		t.Errorf("Unexpected EIP-191 result: %+v", result)
+	}
+	if result, _ := Verify(&Request{Format: FormatEIP191, Message: []byte("something else"), Signature: hex.EncodeToString(sig)}, info); result.Verified() {
+		t.Error("Expected a signature of another message not to be the vault's")
+	}
+}
+
+func TestVerify_Ed25519(t *testing.T) {
+	info := testVault(t)
+	key, err := signer.NewEdDSAKey(testScalar, info)
+	if err != nil {
+		t.Fatalf("NewEdDSAKey failed: %v", err)
+	}
+	message := []byte("solana ownership attestation")
+	signed, _ := key.SignEdDSA(message)
+
+	result, err := Verify(&Request{Format: FormatEd25519, Message: message, Signature: signed.Signature}, info)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if !result.Verified() || result.Signer != "FnCFLUpgWkSDMufP6y4c9LsHvJDpkxi2je4aFAYMWdgE" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	// Base58, as Solana tools print signatures, naming the vault's SUI address
+	sig, _ := hex.DecodeString(signed.Signature)
+	var sui string
+	for _, addr := range vault.DeriveAddressesFromVault(info) {
+		if addr.Chain == "SUI" {
+			sui = addr.Address
+		}
+	}
+	result, err = Verify(&Request{Format: FormatEd25519, Message: message, Signature: base58.Encode(sig), Address: sui}, info)
+	if err != nil || !result.Verified() {
+		t.Errorf("Unexpected result for the SUI address: %+v, %v", result, err)
+	}
+
+	if _, err := Verify(&Request{Format: "pgp", Signature: signed.Signature}, info); err == nil {
+		t.Error("Expected an unknown format to be rejected")
+	}
+}
+
+// TestSign_RoundTrip - every format signs for the address of its path and
+// the signature verifies as the vault's
+func TestSign_RoundTrip(t *testing.T) {
+	info := testVault(t)
+	source, err := signer.NewPrivateKey(testRootKey, info)
+	if err != nil {
+		t.Fatalf("NewPrivateKey failed: %v", err)
+	}
+	message := []byte("I control this address")
+	for _, tc := range []struct {
+		format, path, scriptType, prefix string
+	}{
+		{FormatBIP137, "", ScriptP2WPKH, "bc1q"},
+		{FormatBIP137, "m/44'/0'/0'/0/3", ScriptP2PKH, "1"},
+		{FormatBIP322, "", ScriptP2WPKH, "bc1q"},
+		{FormatBIP322, "m/49'/0'/0'/1/2", ScriptP2SHP2WPKH, "3"},
+		{FormatBIP322, "m/44'/0'/0'/0/0", ScriptP2PKH, "1"},
+		{FormatEIP191, "", "", "0x"},
+		{FormatEIP712, "m/44'/60'/2'/0/0", "", "0x"},
+	} {
+		msg := message
+		if tc.format == FormatEIP712 {
+			msg = []byte(mailTypedData)
+		}
+		signed, err := Sign(tc.format, msg, tc.path, source, info)
+		if err != nil {
+			t.Fatalf("%s %s: Sign failed: %v", tc.format, tc.path, err)
+		}
+		if signed.ScriptType != tc.scriptType || !strings.HasPrefix(signed.Signer, tc.prefix) || signed.DerivePath == "" {
+			t.Errorf("%s %s: unexpected result %+v", tc.format, tc.path, signed)
+		}
+
+		result, err := Verify(&Request{Format: tc.format, Message: msg, Signature: signed.Signature, Address: signed.Signer,
+			Paths: CommonPaths(tc.format)}, info)
+		if err != nil || !result.Verified() || result.DerivePath != signed.DerivePath {
+			t.Errorf("%s %s: signature does not verify: %+v, %v", tc.format, tc.path, result, err)
+		}
+	}
+
+	// EVM signatures end in 27 or 28 as wallets expect
+	signed, _ := Sign(FormatEIP191, message, "", source, info)
+	if v := signed.Signature[len(signed.Signature)-2:]; v != "1b" && v != "1c" {
+		t.Errorf("Unexpected v byte %s", v)
+	}
+
+	// A key of another vault is refused
+	other, _ := signer.NewPrivateKey(strings.Repeat("11", 32), info)
+	if _, err := Sign(FormatEIP191, message, "", other, info); err == nil {
+		t.Error("Expected a foreign key to be refused")
+	}
+}
+
+func TestSolanaOffchainMessage(t *testing.T) {
+	signed, err := SolanaOffchainMessage([]byte("Hello"))
+	if err != nil {
+		t.Fatalf("SolanaOffchainMessage failed: %v", err)
+	}
+	if got := hex.EncodeToString(signed); got != "ff736f6c616e61206f6666636861696e0000050048656c6c6f" {
+		t.Errorf("Unexpected encoding %s", got)
+	}
+	if signed, _ := SolanaOffchainMessage([]byte("héllo")); signed[17] != 1 {
+		t.Errorf("Expected UTF-8 format, got %d", signed[17])
+	}
+	if _, err := SolanaOffchainMessage(nil); err == nil {
+		t.Error("Expected an empty message to be rejected")
+	}
+
+	info := testVault(t)
+	key, _ := signer.NewEdDSAKey(testScalar, info)
+	result, err := SignEdDSA(FormatSolana, []byte("Hello"), key, info)
+	if err != nil {
+		t.Fatalf("SignEdDSA failed: %v", err)
+	}
+	if result.Signer != "FnCFLUpgWkSDMufP6y4c9LsHvJDpkxi2je4aFAYMWdgE" || len(base58.Decode(result.Signature)) != 64 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	// The signature covers the off-chain envelope, not the bare text
+	if v, _ := Verify(&Request{Format: FormatEd25519, Message: []byte("Hello"), Signature: result.Signature}, info); v.Valid {
+		t.Error("Expected the off-chain signature not to verify as a raw message")
+	}
+}
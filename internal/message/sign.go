@@ -0,0 +1,309 @@
+package message
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/btcsuite/btcd/btcutil/base58"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/rowbotony/vultool/internal/signer"
+	"github.com/rowbotony/vultool/internal/vault"
+)
+
+// Signed is a message signature encoded the way its ecosystem expects
+type Signed struct {
+	Format     string `json:"format"`
+	Signer     string `json:"signer"`
+	PublicKey  string `json:"public_key"`
+	ScriptType string `json:"script_type,omitempty"`
+	DerivePath string `json:"derive_path,omitempty"`
+	Hash       string `json:"hash,omitempty"` // what was signed, for hashing formats
+	Signature  string `json:"signature"`
+	Encoding   string `json:"encoding"` // hex, base64 or base58
+}
+
+// DefaultPath is where a format signs when no path is given: the vault's
+// native SegWit Bitcoin address or its Ethereum address
+func DefaultPath(format string) string {
+	switch format {
+	case FormatBIP137, FormatBIP322:
+		return "m/84'/0'/0'/0/0"
+	case FormatEIP191, FormatEIP712:
+		return "m/44'/60'/0'/0/0"
+	}
+	return ""
+}
+
+// ScriptTypeOf returns the Bitcoin address type a path's BIP purpose
+// stands for; paths of other purposes sign for native SegWit
+func ScriptTypeOf(path string) string {
+	parts := strings.Split(path, "/")
+	if len(parts) < 2 {
+		return ScriptP2WPKH
+	}
+	switch strings.TrimRight(parts[1], "'hH") {
+	case "44":
+		return ScriptP2PKH
+	case "49":
+		return ScriptP2SHP2WPKH
+	}
+	return ScriptP2WPKH
+}
+
+// Sign signs message in an ECDSA format (BIP-137, BIP-322, EIP-191 or
+// EIP-712) with source at path. The signature is checked with Verify
+// against the vault before it is returned.
+func Sign(format string, message []byte, path string, source signer.Source, info *vault.VaultInfo) (*Signed, error) {
+	if path == "" {
+		path = DefaultPath(format)
+	}
+	pubKey, err := vault.DerivePublicKey(info, path)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *Signed
+	switch format {
+	case FormatBIP137:
+		result, err = signBIP137(message, path, pubKey, source)
+	case FormatBIP322:
+		result, err = signBIP322(message, path, pubKey, source)
+	case FormatEIP191, FormatEIP712:
+		result, err = signEVM(format, message, path, pubKey, source)
+	case FormatEd25519, FormatSolana:
+		return nil, fmt.Errorf("%s messages are signed with the vault's EdDSA key", format)
+	default:
+		return nil, fmt.Errorf("unsupported format %q (use %s)", format, strings.Join(Formats, ", "))
+	}
+	if err != nil {
+		return nil, err
+	}
+	result.Format = format
+	result.DerivePath = path
+	result.PublicKey = hex.EncodeToString(pubKey.SerializeCompressed())
+	return result, checkSigned(result, message, info)
+}
+
+// SignEdDSA signs message as a Solana off-chain message, or as raw bytes
+// for the Ed25519 format, with the vault's EdDSA key
+func SignEdDSA(format string, message []byte, source signer.EdDSASource, info *vault.VaultInfo) (*Signed, error) {
+	signed := message
+	switch format {
+	case FormatSolana:
+		var err error
+		if signed, err = SolanaOffchainMessage(message); err != nil {
+			return nil, err
+		}
+	case FormatEd25519:
+	default:
+		return nil, fmt.Errorf("%s messages are signed with the vault's ECDSA keys", format)
+	}
+	pubKey, err := hex.DecodeString(info.PublicKeyEDDSA)
+	if err != nil || len(pubKey) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("vault %s has an invalid EdDSA public key", info.Name)
+	}
+
+	sig, err := source.SignEdDSA(signed)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := hex.DecodeString(sig.Signature)
+	if err != nil || !ed25519.Verify(pubKey, signed, raw) {
+		return nil, fmt.Errorf("signature does not verify against the vault's EdDSA public key")
+	}
+	result := &Signed{
+		Format:    format,
+		Signer:    base58.Encode(pubKey),
+		PublicKey: hex.EncodeToString(pubKey),
+		Signature: base58.Encode(raw),
+		Encoding:  "base58",
+	}
+	return result, checkSigned(result, message, info)
+}
+
+// checkSigned verifies a new signature the way a recipient would, so a
+// signature that would be rejected is never handed out
+func checkSigned(result *Signed, message []byte, info *vault.VaultInfo) error {
+	req := &Request{Format: result.Format, Message: message, Signature: result.Signature, Address: result.Signer}
+	if result.DerivePath != "" {
+		req.Paths = []string{result.DerivePath}
+	}
+	verification, err := Verify(req, info)
+	if err != nil {
+		return fmt.Errorf("signature does not verify: %w", err)
+	}
+	if !verification.Verified() {
+		return fmt.Errorf("signature does not verify: %s", verification.Reason)
+	}
+	return nil
+}
+
+// signBIP137 makes a Bitcoin Core style signature whose header byte tells
+// the address type of the path
+func signBIP137(message []byte, path string, pubKey *btcec.PublicKey, source signer.Source) (*Signed, error) {
+	hash := BitcoinMessageHash(message)
+	compact, err := signCompact(hash, path, pubKey, source)
+	if err != nil {
+		return nil, err
+	}
+	scriptType := ScriptTypeOf(path)
+	header := map[string]byte{ScriptP2PKH: 31, ScriptP2SHP2WPKH: 35, ScriptP2WPKH: 39}[scriptType]
+	sig := append([]byte{header + compact[64]}, compact[:64]...)
+	return &Signed{
+		Signer:     keyAddresses(pubKey)[scriptType].EncodeAddress(),
+		ScriptType: scriptType,
+		Hash:       hex.EncodeToString(hash),
+		Signature:  base64.StdEncoding.EncodeToString(sig),
+		Encoding:   "base64",
+	}, nil
+}
+
+// signBIP322 makes a BIP-322 signature: the simple witness for native
+// SegWit, the full to_sign transaction for nested SegWit, whose scriptSig
+// the simple format cannot carry, and BIP-137 for legacy addresses
+func signBIP322(message []byte, path string, pubKey *btcec.PublicKey, source signer.Source) (*Signed, error) {
+	scriptType := ScriptTypeOf(path)
+	if scriptType == ScriptP2PKH {
+		return signBIP137(message, path, pubKey, source)
+	}
+
+	addr := keyAddresses(pubKey)[scriptType]
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return nil, err
+	}
+	witnessProgram, err := txscript.PayToAddrScript(keyAddresses(pubKey)[ScriptP2WPKH])
+	if err != nil {
+		return nil, err
+	}
+	_, toSign := bip322Transactions(message, pkScript)
+	fetcher := txscript.NewCannedPrevOutputFetcher(pkScript, 0)
+	sigHash, err := txscript.CalcWitnessSigHash(witnessProgram, txscript.NewTxSigHashes(toSign, fetcher),
+		txscript.SigHashAll, toSign, 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute BIP-322 sighash: %w", err)
+	}
+	compact, err := signCompact(sigHash, path, pubKey, source)
+	if err != nil {
+		return nil, err
+	}
+	der := append(derSignature(compact), byte(txscript.SigHashAll))
+	toSign.TxIn[0].Witness = wire.TxWitness{der, pubKey.SerializeCompressed()}
+
+	var b bytes.Buffer
+	if scriptType == ScriptP2SHP2WPKH {
+		if toSign.TxIn[0].SignatureScript, err = txscript.NewScriptBuilder().AddData(witnessProgram).Script(); err != nil {
+			return nil, err
+		}
+		err = toSign.Serialize(&b)
+	} else {
+		err = writeWitness(&b, toSign.TxIn[0].Witness)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode BIP-322 signature: %w", err)
+	}
+	return &Signed{
+		Signer:     addr.EncodeAddress(),
+		ScriptType: scriptType,
+		Hash:       hex.EncodeToString(bip322Hash(message)),
+		Signature:  base64.StdEncoding.EncodeToString(b.Bytes()),
+		Encoding:   "base64",
+	}, nil
+}
+
+// writeWitness serializes a witness stack the way BIP-322 simple
+// signatures carry it
+func writeWitness(b *bytes.Buffer, witness wire.TxWitness) error {
+	if err := wire.WriteVarInt(b, 0, uint64(len(witness))); err != nil {
+		return err
+	}
+	for _, item := range witness {
+		if err := wire.WriteVarBytes(b, 0, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// signEVM makes an EIP-191 or EIP-712 signature, r || s || v with v 27 or 28
+func signEVM(format string, message []byte, path string, pubKey *btcec.PublicKey, source signer.Source) (*Signed, error) {
+	hash := EIP191Hash(message)
+	if format == FormatEIP712 {
+		var err error
+		if hash, err = EIP712Hash(message); err != nil {
+			return nil, err
+		}
+	}
+	compact, err := signCompact(hash, path, pubKey, source)
+	if err != nil {
+		return nil, err
+	}
+	compact[64] += 27
+	return &Signed{
+		Signer:    ethcrypto.PubkeyToAddress(*pubKey.ToECDSA()).Hex(),
+		Hash:      hexutil.Encode(hash),
+		Signature: hexutil.Encode(compact),
+		Encoding:  "hex",
+	}, nil
+}
+
+// signCompact signs hash at path and returns r || s || recovery ID with S
+// in the lower half. The recovery ID is worked out against pubKey, which
+// also proves the source holds the vault's key for path.
+func signCompact(hash []byte, path string, pubKey *btcec.PublicKey, source signer.Source) ([]byte, error) {
+	result, err := source.SignECDSA(hash, path)
+	if err != nil {
+		return nil, err
+	}
+	var r, s btcec.ModNScalar
+	if err := setScalar(&r, result.R); err != nil {
+		return nil, fmt.Errorf("invalid signature r")
+	}
+	if err := setScalar(&s, result.S); err != nil {
+		return nil, fmt.Errorf("invalid signature s")
+	}
+	if s.IsOverHalfOrder() {
+		s.Negate()
+	}
+
+	sig := make([]byte, 65)
+	r.PutBytesUnchecked(sig[:32])
+	s.PutBytesUnchecked(sig[32:64])
+	for v := byte(0); v < 2; v++ {
+		recovered, _, err := ecdsa.RecoverCompact(append([]byte{27 + 4 + v}, sig[:64]...), hash)
+		if err == nil && recovered.IsEqual(pubKey) {
+			sig[64] = v
+			return sig, nil
+		}
+	}
+	return nil, fmt.Errorf("signature is not by the vault's key at %s; %s does not hold this vault's key", path, source.Describe())
+}
+
+func setScalar(scalar *btcec.ModNScalar, hexValue string) error {
+	b, err := hex.DecodeString(hexValue)
+	if err != nil || len(b) > 32 || len(b) == 0 {
+		return fmt.Errorf("invalid scalar")
+	}
+	if scalar.SetByteSlice(b) || scalar.IsZero() {
+		return fmt.Errorf("invalid scalar")
+	}
+	return nil
+}
+
+// derSignature encodes the r || s of a compact signature as DER
+func derSignature(compact []byte) []byte {
+	var r, s btcec.ModNScalar
+	r.SetByteSlice(compact[:32])
+	s.SetByteSlice(compact[32:64])
+	return ecdsa.NewSignature(&r, &s).Serialize()
+}
@@ -0,0 +1,344 @@
+package message
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/base58"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/rowbotony/vultool/internal/network"
+	"github.com/rowbotony/vultool/internal/vault"
+)
+
+// Request is a signature to check against a vault
+type Request struct {
+	Format    string
+	Message   []byte   // the signed text, or the EIP-712 typed data JSON
+	Signature string   // hex, base64 or base58
+	Address   string   // the claimed signer; required for BIP-322
+	Paths     []string // where to look for the signer; CommonPaths when empty
+}
+
+// Verification is the outcome of checking a signature against a vault
+type Verification struct {
+	Format     string `json:"format"`
+	Valid      bool   `json:"valid"` // the signature is valid for Signer
+	Signer     string `json:"signer,omitempty"`
+	PublicKey  string `json:"public_key,omitempty"`
+	ScriptType string `json:"script_type,omitempty"`
+	Hash       string `json:"hash,omitempty"` // what was signed, for hashing formats
+	VaultKey   bool   `json:"vault_key"`      // Signer is one of the vault's keys
+	DerivePath string `json:"derive_path,omitempty"`
+	Reason     string `json:"reason,omitempty"` // why verification failed
+}
+
+// Verified reports whether the vault signed the message
+func (v *Verification) Verified() bool {
+	return v.Valid && v.VaultKey
+}
+
+// Verify checks a signature and whether its signer is one of the vault's
+// derived addresses or public keys. Malformed input is an error; a signature
+// that does not verify, or verifies for someone else, is reported in the
+// result with the reason.
+func Verify(req *Request, info *vault.VaultInfo) (*Verification, error) {
+	var sizes []int
+	switch req.Format {
+	case FormatBIP137, FormatEIP191, FormatEIP712:
+		sizes = []int{65}
+	case FormatEd25519, FormatSolana:
+		sizes = []int{ed25519.SignatureSize}
+	}
+	sig, err := DecodeSignature(req.Signature, sizes...)
+	if err != nil {
+		return nil, err
+	}
+	paths := req.Paths
+	if len(paths) == 0 {
+		paths = CommonPaths(req.Format)
+	}
+
+	switch req.Format {
+	case FormatBIP137:
+		return verifyBIP137(req, sig, info, paths)
+	case FormatBIP322:
+		return verifyBIP322(req, sig, info, paths)
+	case FormatEIP191, FormatEIP712:
+		return verifyEVM(req, sig, info, paths)
+	case FormatEd25519:
+		return verifyEd25519(req, req.Message, sig, info)
+	case FormatSolana:
+		signed, err := SolanaOffchainMessage(req.Message)
+		if err != nil {
+			return nil, err
+		}
+		return verifyEd25519(req, signed, sig, info)
+	default:
+		return nil, fmt.Errorf("unsupported format %q (use %s)", req.Format, strings.Join(Formats, ", "))
+	}
+}
+
+// verifyBIP137 checks a Bitcoin Core style compact signature, whose header
+// byte tells the recovery ID and the address type signed for
+func verifyBIP137(req *Request, sig []byte, info *vault.VaultInfo, paths []string) (*Verification, error) {
+	if len(sig) != 65 {
+		return nil, fmt.Errorf("BIP-137 signatures are 65 bytes, got %d", len(sig))
+	}
+	header := sig[0]
+	if header < 27 || header > 42 {
+		return nil, fmt.Errorf("invalid BIP-137 header byte %d", header)
+	}
+	recoveryID := (header - 27) & 3
+	compressed := header >= 31
+	scriptType := ScriptP2PKH
+	switch {
+	case header >= 39:
+		scriptType = ScriptP2WPKH
+	case header >= 35:
+		scriptType = ScriptP2SHP2WPKH
+	}
+
+	hash := BitcoinMessageHash(req.Message)
+	compact := append([]byte{27 + recoveryID}, sig[1:]...)
+	if compressed {
+		compact[0] += 4
+	}
+	result := &Verification{Format: req.Format, Hash: hex.EncodeToString(hash), ScriptType: scriptType}
+	pubKey, _, err := ecdsa.RecoverCompact(compact, hash)
+	if err != nil {
+		result.Reason = "no public key recovers from the signature"
+		return result, nil
+	}
+	result.PublicKey = hex.EncodeToString(pubKey.SerializeCompressed())
+
+	// Uncompressed keys only sign for their own P2PKH address
+	addresses := keyAddresses(pubKey)
+	if !compressed {
+		uncompressed, _ := btcutil.NewAddressPubKey(pubKey.SerializeUncompressed(), network.BitcoinParams())
+		addresses = map[string]btcutil.Address{ScriptP2PKH: uncompressed.AddressPubKeyHash()}
+	}
+	result.Signer = addresses[scriptType].EncodeAddress()
+	if req.Address != "" {
+		// Electrum signs SegWit addresses with the P2PKH header range
+		found := false
+		for kind, addr := range addresses {
+			if addr.EncodeAddress() == req.Address {
+				result.Signer, result.ScriptType, found = req.Address, kind, true
+			}
+		}
+		if !found {
+			result.Reason = fmt.Sprintf("signature was made by %s, not %s", result.Signer, req.Address)
+			return result, nil
+		}
+	}
+	result.Valid = true
+	return matchVaultKey(result, info, pubKey, paths)
+}
+
+// verifyBIP322 checks a BIP-322 simple (witness) or full (to_sign) signature
+// by running the script engine over the virtual to_sign transaction
+func verifyBIP322(req *Request, sig []byte, info *vault.VaultInfo, paths []string) (*Verification, error) {
+	if req.Address == "" {
+		return nil, fmt.Errorf("BIP-322 signatures name no signer; give the address")
+	}
+	addr, err := btcutil.DecodeAddress(req.Address, network.BitcoinParams())
+	if err != nil {
+		return nil, fmt.Errorf("invalid Bitcoin address %q: %w", req.Address, err)
+	}
+	// Legacy addresses sign with BIP-137, which BIP-322 falls back to
+	if _, ok := addr.(*btcutil.AddressPubKeyHash); ok && len(sig) == 65 {
+		legacy := *req
+		legacy.Format = FormatBIP137
+		result, err := verifyBIP137(&legacy, sig, info, paths)
+		if result != nil {
+			result.Format = req.Format
+		}
+		return result, err
+	}
+
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return nil, fmt.Errorf("cannot verify BIP-322 signatures for %s", req.Address)
+	}
+	toSpend, toSign := bip322Transactions(req.Message, pkScript)
+	if witness, ok := parseWitness(sig); ok {
+		toSign.TxIn[0].Witness = witness
+	} else {
+		var full wire.MsgTx
+		if err := full.Deserialize(bytes.NewReader(sig)); err != nil || len(full.TxIn) != 1 ||
+			full.TxIn[0].PreviousOutPoint.Hash != toSpend.TxHash() {
+			return nil, fmt.Errorf("signature is neither a BIP-322 witness nor a to_sign transaction for this message")
+		}
+		toSign = &full
+	}
+
+	result := &Verification{Format: req.Format, Signer: req.Address, Hash: hex.EncodeToString(bip322Hash(req.Message))}
+	fetcher := txscript.NewCannedPrevOutputFetcher(pkScript, 0)
+	engine, err := txscript.NewEngine(pkScript, toSign, 0, txscript.StandardVerifyFlags, nil,
+		txscript.NewTxSigHashes(toSign, fetcher), 0, fetcher)
+	if err == nil {
+		err = engine.Execute()
+	}
+	if err != nil {
+		result.Reason = fmt.Sprintf("signature does not verify: %v", err)
+		return result, nil
+	}
+	result.Valid = true
+
+	path, pubKey, err := vaultPathOfScript(info, pkScript, paths)
+	if err != nil {
+		return nil, err
+	}
+	if pubKey != nil {
+		result.VaultKey, result.DerivePath = true, path
+		result.PublicKey = hex.EncodeToString(pubKey.SerializeCompressed())
+		for kind, addr := range keyAddresses(pubKey) {
+			if addr.EncodeAddress() == req.Address {
+				result.ScriptType = kind
+			}
+		}
+	} else {
+		result.Reason = "address is not one of the vault's at the searched paths"
+	}
+	return result, nil
+}
+
+// parseWitness reads a serialized witness stack that spans all of data
+func parseWitness(data []byte) (wire.TxWitness, bool) {
+	r := bytes.NewReader(data)
+	count, err := wire.ReadVarInt(r, 0)
+	if err != nil || count == 0 || count > 100 {
+		return nil, false
+	}
+	witness := make(wire.TxWitness, count)
+	for i := range witness {
+		if witness[i], err = wire.ReadVarBytes(r, 0, txscript.MaxScriptSize, "witness item"); err != nil {
+			return nil, false
+		}
+	}
+	return witness, r.Len() == 0
+}
+
+// verifyEVM checks an EIP-191 or EIP-712 signature, r || s || v
+func verifyEVM(req *Request, sig []byte, info *vault.VaultInfo, paths []string) (*Verification, error) {
+	if len(sig) != 65 {
+		return nil, fmt.Errorf("Ethereum signatures are 65 bytes, got %d", len(sig))
+	}
+	var hash []byte
+	if req.Format == FormatEIP712 {
+		var err error
+		if hash, err = EIP712Hash(req.Message); err != nil {
+			return nil, err
+		}
+	} else {
+		hash = EIP191Hash(req.Message)
+	}
+
+	v := sig[64]
+	if v >= 27 {
+		v -= 27
+	}
+	result := &Verification{Format: req.Format, Hash: hex.EncodeToString(hash)}
+	if v > 1 {
+		result.Reason = fmt.Sprintf("invalid recovery byte %d", sig[64])
+		return result, nil
+	}
+	pubKey, _, err := ecdsa.RecoverCompact(append([]byte{27 + v + 4}, sig[:64]...), hash)
+	if err != nil {
+		result.Reason = "no public key recovers from the signature"
+		return result, nil
+	}
+	result.PublicKey = hex.EncodeToString(pubKey.SerializeCompressed())
+	result.Signer = ethcrypto.PubkeyToAddress(*pubKey.ToECDSA()).Hex()
+	if req.Address != "" {
+		if !common.IsHexAddress(req.Address) {
+			return nil, fmt.Errorf("invalid Ethereum address %q", req.Address)
+		}
+		if common.HexToAddress(req.Address).Hex() != result.Signer {
+			result.Reason = fmt.Sprintf("signature was made by %s, not %s", result.Signer, req.Address)
+			return result, nil
+		}
+	}
+	result.Valid = true
+	return matchVaultKey(result, info, pubKey, paths)
+}
+
+// verifyEd25519 checks an Ed25519 signature of signed, made by the given
+// Solana address or public key, or by the vault's EdDSA key
+func verifyEd25519(req *Request, signed, sig []byte, info *vault.VaultInfo) (*Verification, error) {
+	if len(sig) != ed25519.SignatureSize {
+		return nil, fmt.Errorf("Ed25519 signatures are 64 bytes, got %d", len(sig))
+	}
+	vaultKey, err := hex.DecodeString(info.PublicKeyEDDSA)
+	if err != nil || len(vaultKey) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("vault %s has an invalid EdDSA public key", info.Name)
+	}
+
+	pubKey := vaultKey
+	if req.Address != "" && !isVaultSUIAddress(info, req.Address) {
+		if pubKey, err = parseEd25519Key(req.Address); err != nil {
+			return nil, err
+		}
+	}
+	result := &Verification{
+		Format:    req.Format,
+		Signer:    base58.Encode(pubKey),
+		PublicKey: hex.EncodeToString(pubKey),
+		VaultKey:  bytes.Equal(pubKey, vaultKey),
+	}
+	if !ed25519.Verify(pubKey, signed, sig) {
+		result.Reason = "signature does not verify"
+		return result, nil
+	}
+	result.Valid = true
+	if !result.VaultKey {
+		result.Reason = "signer is not the vault's EdDSA key"
+	}
+	return result, nil
+}
+
+// parseEd25519Key reads a Solana address or a hex Ed25519 public key
+func parseEd25519Key(input string) ([]byte, error) {
+	if key, err := hex.DecodeString(strings.TrimPrefix(input, "0x")); err == nil && len(key) == ed25519.PublicKeySize {
+		return key, nil
+	}
+	if key := base58.Decode(input); len(key) == ed25519.PublicKeySize {
+		return key, nil
+	}
+	return nil, fmt.Errorf("%q is neither a Solana address nor a hex Ed25519 public key", input)
+}
+
+// isVaultSUIAddress reports whether address is the vault's SUI address,
+// which hashes the EdDSA key and so cannot be turned back into it
+func isVaultSUIAddress(info *vault.VaultInfo, address string) bool {
+	for _, addr := range vault.DeriveAddressesFromVault(info) {
+		if addr.Chain == "SUI" && strings.EqualFold(addr.Address, address) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchVaultKey records where the vault derives the recovered signing key
+func matchVaultKey(result *Verification, info *vault.VaultInfo, pubKey *btcec.PublicKey, paths []string) (*Verification, error) {
+	path, err := vaultPathOf(info, pubKey, paths)
+	if err != nil {
+		return nil, err
+	}
+	if path == "" {
+		result.Reason = "signer is not one of the vault's keys at the searched paths"
+		return result, nil
+	}
+	result.VaultKey, result.DerivePath = true, path
+	return result, nil
+}
@@ -0,0 +1,203 @@
+// Package network selects the network vultool encodes addresses, keys and
+// chain IDs for. Mainnet is the default; the test networks exist so
+// integration tests against regtest nodes and testnet faucets see the
+// vault's addresses as those networks encode them.
+package network
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	bchchaincfg "github.com/gcash/bchd/chaincfg"
+)
+
+// Network is a network name
+type Network string
+
+// Networks
+const (
+	Mainnet Network = "mainnet"
+	Testnet Network = "testnet"
+	Regtest Network = "regtest"
+	Signet  Network = "signet"
+)
+
+// Names are the networks Set accepts
+var Names = []Network{Mainnet, Testnet, Regtest, Signet}
+
+var current = Mainnet
+
+// Set selects the network by name; "main" and "test" are accepted too
+func Set(name string) error {
+	switch Network(strings.ToLower(strings.TrimSpace(name))) {
+	case Mainnet, "main", "":
+		current = Mainnet
+	case Testnet, "test", "testnet3":
+		current = Testnet
+	case Regtest:
+		current = Regtest
+	case Signet:
+		current = Signet
+	default:
+		return fmt.Errorf("unknown network %q (use mainnet, testnet, regtest or signet)", name)
+	}
+	return nil
+}
+
+// Current returns the selected network
+func Current() Network {
+	return current
+}
+
+// IsMainnet reports whether mainnet is selected
+func IsMainnet() bool {
+	return current == Mainnet
+}
+
+// BitcoinParams returns Bitcoin's parameters for the selected network
+func BitcoinParams() *chaincfg.Params {
+	switch current {
+	case Testnet:
+		return &chaincfg.TestNet3Params
+	case Regtest:
+		return &chaincfg.RegressionNetParams
+	case Signet:
+		return &chaincfg.SigNetParams
+	}
+	return &chaincfg.MainNetParams
+}
+
+// altcoinVersions are the address and WIF versions of a Bitcoin-derived
+// chain on one network
+type altcoinVersions struct {
+	name       string
+	hrp        string // bech32 prefix, empty without SegWit
+	pubKeyHash byte
+	scriptHash byte
+	privateKey byte
+}
+
+// Litecoin, Dogecoin and Dash have no signet; it encodes as testnet
+var (
+	litecoinVersions = map[Network]altcoinVersions{
+		Mainnet: {"litecoin", "ltc", 0x30, 0x32, 0xb0},
+		Testnet: {"litecoin-testnet", "tltc", 0x6f, 0x3a, 0xef},
+		Regtest: {"litecoin-regtest", "rltc", 0x6f, 0x3a, 0xef},
+	}
+	dogecoinVersions = map[Network]altcoinVersions{
+		Mainnet: {"dogecoin", "", 0x1e, 0x16, 0x9e},
+		Testnet: {"dogecoin-testnet", "", 0x71, 0xc4, 0xf1},
+		Regtest: {"dogecoin-regtest", "", 0x6f, 0xc4, 0xef},
+	}
+	dashVersions = map[Network]altcoinVersions{
+		Mainnet: {"dash", "", 0x4c, 0x10, 0xcc},
+		Testnet: {"dash-testnet", "", 0x8c, 0x13, 0xef},
+		Regtest: {"dash-regtest", "", 0x8c, 0x13, 0xef},
+	}
+)
+
+// altcoinParams builds parameters for a Bitcoin-derived chain from the
+// selected network's Bitcoin parameters. They only carry what address and
+// key encoding needs and are never registered with chaincfg.
+func altcoinParams(versions map[Network]altcoinVersions, coinType uint32) *chaincfg.Params {
+	v, ok := versions[current]
+	if !ok {
+		v = versions[Testnet]
+	}
+	params := *BitcoinParams()
+	params.Name = v.name
+	params.Bech32HRPSegwit = v.hrp
+	params.PubKeyHashAddrID = v.pubKeyHash
+	params.ScriptHashAddrID = v.scriptHash
+	params.PrivateKeyID = v.privateKey
+	params.HDCoinType = coinType
+	return &params
+}
+
+// LitecoinParams returns Litecoin's parameters for the selected network
+func LitecoinParams() *chaincfg.Params {
+	return altcoinParams(litecoinVersions, 2)
+}
+
+// DogecoinParams returns Dogecoin's parameters for the selected network
+func DogecoinParams() *chaincfg.Params {
+	return altcoinParams(dogecoinVersions, 3)
+}
+
+// DashParams returns Dash's parameters for the selected network
+func DashParams() *chaincfg.Params {
+	return altcoinParams(dashVersions, 5)
+}
+
+// BitcoinCashParams returns Bitcoin Cash's parameters for the selected
+// network; signet encodes as testnet
+func BitcoinCashParams() *bchchaincfg.Params {
+	switch current {
+	case Testnet, Signet:
+		return &bchchaincfg.TestNet3Params
+	case Regtest:
+		return &bchchaincfg.RegressionNetParams
+	}
+	return &bchchaincfg.MainNetParams
+}
+
+// ZcashAddressVersion returns the two-byte version of Zcash transparent
+// P2PKH addresses: t1... on mainnet, tm... on the test networks
+func ZcashAddressVersion() [2]byte {
+	if current == Mainnet {
+		return [2]byte{0x1c, 0xb8}
+	}
+	return [2]byte{0x1d, 0x25}
+}
+
+// evmChainIDs are the chain IDs of each EVM chain's mainnet and its main
+// public testnet. Regtest is a local development node.
+var evmChainIDs = map[string][2]int64{
+	"ethereum":    {1, 11155111},  // Sepolia
+	"bsc":         {56, 97},       // BSC testnet
+	"avalanche":   {43114, 43113}, // Fuji
+	"polygon":     {137, 80002},   // Amoy
+	"cronoschain": {25, 338},
+	"arbitrum":    {42161, 421614},    // Arbitrum Sepolia
+	"optimism":    {10, 11155420},     // OP Sepolia
+	"base":        {8453, 84532},      // Base Sepolia
+	"blast":       {81457, 168587773}, // Blast Sepolia
+	"zksync":      {324, 300},         // zkSync Sepolia
+}
+
+// RegtestChainID is the chain ID of local EVM development nodes
+// (Hardhat, Anvil), which stand in for every EVM chain on regtest
+const RegtestChainID = 31337
+
+// EVMChainID returns an EVM chain's ID on the selected network, by the
+// chain name list-addresses uses; ok is false for chains that are not EVM
+func EVMChainID(chain string) (id *big.Int, ok bool) {
+	ids, ok := evmChainIDs[strings.ToLower(chain)]
+	if !ok {
+		return nil, false
+	}
+	switch current {
+	case Mainnet:
+		return big.NewInt(ids[0]), true
+	case Regtest:
+		return big.NewInt(RegtestChainID), true
+	}
+	return big.NewInt(ids[1]), true
+}
+
+// CheckEVMChainID refuses a known mainnet chain ID while a test network is
+// selected, so a test run never signs a transaction that is valid on a
+// mainnet
+func CheckEVMChainID(id *big.Int) error {
+	if current == Mainnet {
+		return nil
+	}
+	for chain, ids := range evmChainIDs {
+		if id.Cmp(big.NewInt(ids[0])) == 0 {
+			return fmt.Errorf("chain ID %s is %s mainnet, but the selected network is %s", id, chain, current)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package network
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestSet(t *testing.T) {
+	defer Set("mainnet")
+
+	for name, want := range map[string]Network{"mainnet": Mainnet, "TEST": Testnet, "regtest": Regtest, "signet": Signet, "": Mainnet} {
+		if err := Set(name); err != nil {
+			t.Fatalf("Set(%q): %v", name, err)
+		}
+		if Current() != want {
+			t.Errorf("Set(%q) selected %s, want %s", name, Current(), want)
+		}
+	}
+	if err := Set("bogus"); err == nil {
+		t.Error("expected an unknown network to be refused")
+	}
+}
+
+func TestParams(t *testing.T) {
+	defer Set("mainnet")
+
+	for _, tc := range []struct {
+		network Network
+		btcHRP  string
+		ltcHRP  string
+		wif     byte
+		dogeP2P byte
+	}{
+		{Mainnet, "bc", "ltc", 0x80, 0x1e},
+		{Testnet, "tb", "tltc", 0xef, 0x71},
+		{Regtest, "bcrt", "rltc", 0xef, 0x6f},
+		{Signet, "tb", "tltc", 0xef, 0x71},
+	} {
+		if err := Set(string(tc.network)); err != nil {
+			t.Fatal(err)
+		}
+		if got := BitcoinParams().Bech32HRPSegwit; got != tc.btcHRP {
+			t.Errorf("%s: Bitcoin HRP %s, want %s", tc.network, got, tc.btcHRP)
+		}
+		if got := LitecoinParams().Bech32HRPSegwit; got != tc.ltcHRP {
+			t.Errorf("%s: Litecoin HRP %s, want %s", tc.network, got, tc.ltcHRP)
+		}
+		if got := BitcoinParams().PrivateKeyID; got != tc.wif {
+			t.Errorf("%s: WIF version %#x, want %#x", tc.network, got, tc.wif)
+		}
+		if got := DogecoinParams().PubKeyHashAddrID; got != tc.dogeP2P {
+			t.Errorf("%s: Dogecoin P2PKH version %#x, want %#x", tc.network, got, tc.dogeP2P)
+		}
+	}
+}
+
+func TestEVMChainID(t *testing.T) {
+	defer Set("mainnet")
+
+	for network, want := range map[Network]int64{Mainnet: 1, Testnet: 11155111, Regtest: RegtestChainID, Signet: 11155111} {
+		Set(string(network))
+		id, ok := EVMChainID("Ethereum")
+		if !ok || id.Int64() != want {
+			t.Errorf("%s: Ethereum chain ID %v, want %d", network, id, want)
+		}
+	}
+	if _, ok := EVMChainID("THORChain"); ok {
+		t.Error("THORChain is not an EVM chain")
+	}
+
+	Set("testnet")
+	if err := CheckEVMChainID(big.NewInt(1)); err == nil {
+		t.Error("expected mainnet chain ID 1 to be refused on testnet")
+	}
+	if err := CheckEVMChainID(big.NewInt(11155111)); err != nil {
+		t.Errorf("Sepolia refused on testnet: %v", err)
+	}
+	Set("mainnet")
+	if err := CheckEVMChainID(big.NewInt(1)); err != nil {
+		t.Errorf("chain ID 1 refused on mainnet: %v", err)
+	}
+}
@@ -12,6 +12,8 @@ import (
 	"strings"
 
 	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/rowbotony/vultool/internal/network"
+	"github.com/rowbotony/vultool/internal/types"
 	"github.com/rowbotony/vultool/internal/vault"
 )
 
@@ -20,12 +22,13 @@ type SupportedChain string
 
 const (
 	// ECDSA-based chains
-	ChainBitcoin     SupportedChain = "bitcoin"
-	ChainBitcoinCash SupportedChain = "bitcoincash"
-	ChainLitecoin    SupportedChain = "litecoin"
-	ChainDash        SupportedChain = "dash"
-	ChainDogecoin    SupportedChain = "dogecoin"
-	ChainZcash       SupportedChain = "zcash"
+	ChainBitcoin        SupportedChain = "bitcoin"
+	ChainBitcoinTaproot SupportedChain = "bitcoin-taproot"
+	ChainBitcoinCash    SupportedChain = "bitcoincash"
+	ChainLitecoin       SupportedChain = "litecoin"
+	ChainDash           SupportedChain = "dash"
+	ChainDogecoin       SupportedChain = "dogecoin"
+	ChainZcash          SupportedChain = "zcash"
 
 	// Ethereum and EVM-compatible chains
 	ChainEthereum  SupportedChain = "ethereum"
@@ -41,6 +44,17 @@ const (
 
 	// Cosmos-based chains
 	ChainThorChain SupportedChain = "thorchain"
+	ChainMayaChain SupportedChain = "mayachain"
+	ChainCosmos    SupportedChain = "cosmos"
+	ChainOsmosis   SupportedChain = "osmosis"
+	ChainKujira    SupportedChain = "kujira"
+	ChainDydx      SupportedChain = "dydx"
+	ChainNoble     SupportedChain = "noble"
+	ChainTerra     SupportedChain = "terra"
+
+	// TRON and the XRP Ledger
+	ChainTron SupportedChain = "tron"
+	ChainXRP  SupportedChain = "xrp"
 
 	// EdDSA-based chains
 	ChainSolana SupportedChain = "solana"
@@ -55,12 +69,19 @@ type RecoveredKey struct {
 	Base58     string         `json:"base58,omitempty"` // Solana/THOR base58 format
 	Address    string         `json:"address"`
 	DerivePath string         `json:"derive_path,omitempty"`
+	// LegacyAddress is the address in the chain's older format, e.g. the
+	// base58 form of a Bitcoin Cash CashAddr
+	LegacyAddress string `json:"legacy_address,omitempty"`
 
 	// Wallet-compatible formats for EdDSA chains
 	SolanaSeedFormat   string `json:"solana_seed_format,omitempty"`   // 32-byte seed only in base64 (some wallets prefer this)
 	SolanaWalletFormat string `json:"solana_wallet_format,omitempty"` // 64-byte Ed25519 keypair in base64 for Solana
 	SolanaWalletJSON   string `json:"solana_wallet_json,omitempty"`   // JSON array of 64 bytes for Phantom/Solflare
 	SuiWalletFormat    string `json:"sui_wallet_format,omitempty"`    // 33-byte [0x00 + seed] in base64 for SUI
+
+	// XRPLPrivateKey is the secp256k1 key as XRPL libraries and wallets take
+	// it without a family seed: 00 followed by the key in uppercase hex
+	XRPLPrivateKey string `json:"xrpl_private_key,omitempty"`
 }
 
 // DerivationPath represents an HD derivation path
@@ -186,8 +207,9 @@ func RecoverPrivateKeys(vaultFiles []string, threshold int, password string) ([]
 	return recoveredKeys, nil
 }
 
-// DeriveAddress performs read-only HD derivation from a single vault share
-// This is a STUB implementation - will be completed in future iterations
+// DeriveAddress performs read-only HD derivation from a single vault share.
+// Only public keys are involved: the address is derived non-hardened from
+// the vault's root key and chain code, the same way list-addresses does.
 func DeriveAddress(vaultFile string, derivePath string, chain SupportedChain, password string) (*RecoveredKey, error) {
 	// Parse the vault file first
 	vaultInfo, err := vault.ParseVaultFileWithPassword(vaultFile, password)
@@ -200,10 +222,20 @@ func DeriveAddress(vaultFile string, derivePath string, chain SupportedChain, pa
 		return nil, fmt.Errorf("vault missing hex chain code required for HD derivation")
 	}
 
-	// TODO: Implement actual HD derivation logic
-	// For now, return a placeholder structure
-
-	return nil, fmt.Errorf("HD derivation not yet implemented - this is a stub for v0.2 milestone")
+	pathChain := types.SupportedChain(chain)
+	if chain == ChainCronos {
+		pathChain = types.ChainCronosChain
+	}
+	addr, err := vault.DeriveAddressAtPath(vaultInfo, pathChain, derivePath)
+	if err != nil {
+		return nil, err
+	}
+	return &RecoveredKey{
+		Chain:         chain,
+		Address:       addr.Address,
+		DerivePath:    addr.DerivePath,
+		LegacyAddress: addr.LegacyAddress,
+	}, nil
 }
 
 // GetCommonDerivationPaths returns common HD derivation paths for supported chains
@@ -246,11 +278,59 @@ func ValidateDerivationPath(path string) error {
 	return nil
 }
 
+// chainAliases are the tickers and other names chains are given on the
+// command line
+var chainAliases = map[string]SupportedChain{
+	"btc":         ChainBitcoin,
+	"taproot":     ChainBitcoinTaproot,
+	"p2tr":        ChainBitcoinTaproot,
+	"btc-taproot": ChainBitcoinTaproot,
+	"bch":         ChainBitcoinCash,
+	"ltc":         ChainLitecoin,
+	"doge":        ChainDogecoin,
+	"zec":         ChainZcash,
+	"eth":         ChainEthereum,
+	"arb":         ChainArbitrum,
+	"avax":        ChainAvalanche,
+	"binance":     ChainBSC,
+	"cronoschain": ChainCronos,
+	"cro":         ChainCronos,
+	"op":          ChainOptimism,
+	"matic":       ChainPolygon,
+	"thor":        ChainThorChain,
+	"rune":        ChainThorChain,
+	"maya":        ChainMayaChain,
+	"cacao":       ChainMayaChain,
+	"atom":        ChainCosmos,
+	"cosmoshub":   ChainCosmos,
+	"osmo":        ChainOsmosis,
+	"kuji":        ChainKujira,
+	"luna":        ChainTerra,
+	"trx":         ChainTron,
+	"ripple":      ChainXRP,
+	"sol":         ChainSolana,
+}
+
+// LookupChain finds a supported chain by name or alias, ignoring case
+func LookupChain(name string) (SupportedChain, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if chain, ok := chainAliases[name]; ok {
+		return chain, nil
+	}
+	for _, chain := range GetSupportedChains() {
+		if string(chain) == name {
+			return chain, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported chain: %s", name)
+}
+
 // GetSupportedChains returns a list of all supported blockchain chains
 func GetSupportedChains() []SupportedChain {
 	return []SupportedChain{
 		// ECDSA-based chains
 		ChainBitcoin,
+		ChainBitcoinTaproot,
 		ChainBitcoinCash,
 		ChainLitecoin,
 		ChainDash,
@@ -271,6 +351,17 @@ func GetSupportedChains() []SupportedChain {
 
 		// Cosmos-based chains
 		ChainThorChain,
+		ChainMayaChain,
+		ChainCosmos,
+		ChainOsmosis,
+		ChainKujira,
+		ChainDydx,
+		ChainNoble,
+		ChainTerra,
+
+		// TRON and the XRP Ledger
+		ChainTron,
+		ChainXRP,
 
 		// EdDSA-based chains
 		ChainSolana,
@@ -591,13 +682,13 @@ func generateEd25519Addresses(privateKey []byte, chain SupportedChain) (*Address
 // generateWIF creates a Bitcoin Wallet Import Format string
 // This creates a proper Base58Check-encoded WIF that can be imported into Bitcoin wallets
 func generateWIF(privateKeyBytes []byte) string {
-	return "WIF:" + generateWIFManual(privateKeyBytes, &chaincfg.MainNetParams, true)
+	return "WIF:" + generateWIFManual(privateKeyBytes, network.BitcoinParams(), true)
 }
 
 // generateWIFManual creates WIF manually using Base58Check encoding
 func generateWIFManual(privateKeyBytes []byte, net *chaincfg.Params, compressed bool) string {
-	// Add version byte (0x80 for mainnet)
-	versioned := append([]byte{0x80}, privateKeyBytes...)
+	// Add version byte (0x80 for mainnet, 0xef for the test networks)
+	versioned := append([]byte{net.PrivateKeyID}, privateKeyBytes...)
 
 	// Add compression flag if requested
 	if compressed {
@@ -813,25 +904,35 @@ func convertTSSToRecoveredKeys(tssResult *TSSRecoveryResult, keyType TssKeyType,
 	// Convert to RecoveredKey format, using the proper addresses from centralized derivation
 	// IMPORTANT: These chain names must match the EXACT names used in DeriveAddressesFromVault
 	chainMappings := map[string]SupportedChain{
-		"bitcoin":      ChainBitcoin,     // "Bitcoin" from derivation
-		"bitcoin-cash": ChainBitcoinCash, // "Bitcoin-Cash" from derivation
-		"litecoin":     ChainLitecoin,    // "Litecoin" from derivation
-		"dogecoin":     ChainDogecoin,    // "Dogecoin" from derivation
-		"dash":         ChainDash,        // "Dash" from derivation
-		"zcash":        ChainZcash,       // "Zcash" from derivation
-		"ethereum":     ChainEthereum,    // "Ethereum" from derivation
-		"bsc":          ChainBSC,         // "BSC" from derivation
-		"avalanche":    ChainAvalanche,   // "Avalanche" from derivation
-		"polygon":      ChainPolygon,     // "Polygon" from derivation
-		"cronoschain":  ChainCronos,      // "CronosChain" from derivation
-		"arbitrum":     ChainArbitrum,    // "Arbitrum" from derivation
-		"optimism":     ChainOptimism,    // "Optimism" from derivation
-		"base":         ChainBase,        // "Base" from derivation
-		"blast":        ChainBlast,       // "Blast" from derivation
-		"zksync":       ChainZkSync,      // "Zksync" from derivation
-		"thorchain":    ChainThorChain,   // "THORChain" from derivation
-		"solana":       ChainSolana,      // "Solana" from derivation (EdDSA)
-		"sui":          ChainSUI,         // "SUI" from derivation (EdDSA)
+		"bitcoin":         ChainBitcoin,        // "Bitcoin" from derivation
+		"bitcoin-taproot": ChainBitcoinTaproot, // "Bitcoin-Taproot" from derivation
+		"bitcoin-cash":    ChainBitcoinCash,    // "Bitcoin-Cash" from derivation
+		"litecoin":        ChainLitecoin,       // "Litecoin" from derivation
+		"dogecoin":        ChainDogecoin,       // "Dogecoin" from derivation
+		"dash":            ChainDash,           // "Dash" from derivation
+		"zcash":           ChainZcash,          // "Zcash" from derivation
+		"ethereum":        ChainEthereum,       // "Ethereum" from derivation
+		"bsc":             ChainBSC,            // "BSC" from derivation
+		"avalanche":       ChainAvalanche,      // "Avalanche" from derivation
+		"polygon":         ChainPolygon,        // "Polygon" from derivation
+		"cronoschain":     ChainCronos,         // "CronosChain" from derivation
+		"arbitrum":        ChainArbitrum,       // "Arbitrum" from derivation
+		"optimism":        ChainOptimism,       // "Optimism" from derivation
+		"base":            ChainBase,           // "Base" from derivation
+		"blast":           ChainBlast,          // "Blast" from derivation
+		"zksync":          ChainZkSync,         // "Zksync" from derivation
+		"thorchain":       ChainThorChain,      // "THORChain" from derivation
+		"mayachain":       ChainMayaChain,      // "MayaChain" from derivation
+		"cosmos":          ChainCosmos,         // "Cosmos" from derivation
+		"osmosis":         ChainOsmosis,        // "Osmosis" from derivation
+		"kujira":          ChainKujira,         // "Kujira" from derivation
+		"dydx":            ChainDydx,           // "Dydx" from derivation
+		"noble":           ChainNoble,          // "Noble" from derivation
+		"terra":           ChainTerra,          // "Terra" from derivation
+		"tron":            ChainTron,           // "TRON" from derivation
+		"xrp":             ChainXRP,            // "XRP" from derivation
+		"solana":          ChainSolana,         // "Solana" from derivation (EdDSA)
+		"sui":             ChainSUI,            // "SUI" from derivation (EdDSA)
 	}
 
 	for chainKey, addr := range expectedByChain {
@@ -848,13 +949,41 @@ func convertTSSToRecoveredKeys(tssResult *TSSRecoveryResult, keyType TssKeyType,
 			// Derive private key for this specific chain using the same derivation path
 			privateKeyHex := derivePrivateKeyForPath(tssResult.PrivateKeyHex, tssResult.ChainCode, addr.DerivePath)
 
+			// A taproot output commits to a tweaked key, so the key that spends
+			// it is the tweaked child key rather than the root key
+			if supportedChain == ChainBitcoinTaproot {
+				taprootKey, err := deriveTaprootKey(tssResult.PrivateKeyHex, tssResult.ChainCode, addr.DerivePath)
+				if err != nil {
+					log.Printf("⚠️ Taproot key derivation failed: %v", err)
+					continue
+				}
+				keys = append(keys, *taprootKey)
+				continue
+			}
+
+			// Keplr, TronLink and XRPL wallets import the raw key of the
+			// account itself, so those chains get the child key at the
+			// address's path
+			if importsAccountKey(supportedChain) {
+				childKey, err := deriveChildPrivateKey(tssResult.PrivateKeyHex, tssResult.ChainCode, addr.DerivePath)
+				if err != nil {
+					log.Printf("⚠️ %s key derivation failed: %v", addr.Chain, err)
+					continue
+				}
+				privateKeyHex = childKey
+			}
+
 			// CRITICAL: Use the original chain key (lowercase) to match validation expectations
 			// This ensures the recovered chain name matches exactly what the validation expects
 			recoveredKey := RecoveredKey{
-				Chain:      SupportedChain(chainKey), // Use chainKey instead of supportedChain constant
-				PrivateKey: privateKeyHex,
-				Address:    addr.Address,
-				DerivePath: addr.DerivePath,
+				Chain:         SupportedChain(chainKey), // Use chainKey instead of supportedChain constant
+				PrivateKey:    privateKeyHex,
+				Address:       addr.Address,
+				DerivePath:    addr.DerivePath,
+				LegacyAddress: addr.LegacyAddress,
+			}
+			if supportedChain == ChainXRP {
+				recoveredKey.XRPLPrivateKey = xrplPrivateKey(privateKeyHex)
 			}
 
 			// Generate wallet-compatible formats for EdDSA chains
@@ -917,12 +1046,39 @@ func generateWIFFromPrivateKey(privateKeyHex string) string {
 
 // isECDSAChain determines if a chain uses ECDSA cryptography
 func isECDSAChain(chain SupportedChain) bool {
-	return chain == ChainBitcoin || chain == ChainBitcoinCash || chain == ChainLitecoin ||
+	return chain == ChainBitcoin || chain == ChainBitcoinTaproot || chain == ChainBitcoinCash || chain == ChainLitecoin ||
 		chain == ChainDash || chain == ChainDogecoin || chain == ChainZcash ||
 		chain == ChainEthereum || chain == ChainArbitrum || chain == ChainAvalanche ||
 		chain == ChainBase || chain == ChainBlast || chain == ChainBSC ||
 		chain == ChainCronos || chain == ChainOptimism || chain == ChainPolygon ||
-		chain == ChainZkSync || chain == ChainThorChain
+		chain == ChainZkSync || IsCosmosChain(chain) || chain == ChainTron || chain == ChainXRP
+}
+
+// xrplPrivateKey formats a hex secp256k1 key the way ripple-keypairs and
+// xrpl.js do, which tell secp256k1 keys from ED-prefixed Ed25519 ones by
+// their 00 prefix
+func xrplPrivateKey(privateKeyHex string) string {
+	return "00" + strings.ToUpper(privateKeyHex)
+}
+
+// importsAccountKey reports whether recover outputs the key of the account
+// at its path, as the chain's wallets import it, rather than the root key.
+func importsAccountKey(chain SupportedChain) bool {
+	// THORChain and MayaChain share m/44'/931'/0'/0/0, yet only MayaChain
+	// gets the account key: THORChain has always been recovered as the root
+	// key, which existing recoveries re-derive from, so switching it needs its
+	// own release note rather than a change in passing.
+	// TestConvertTSSToRecoveredKeys_ThorMaya pins both.
+	return (IsCosmosChain(chain) && chain != ChainThorChain) || chain == ChainTron || chain == ChainXRP
+}
+
+// IsCosmosChain reports whether a chain is a Cosmos SDK chain
+func IsCosmosChain(chain SupportedChain) bool {
+	switch chain {
+	case ChainThorChain, ChainMayaChain, ChainCosmos, ChainOsmosis, ChainKujira, ChainDydx, ChainNoble, ChainTerra:
+		return true
+	}
+	return false
 }
 
 // isEdDSAChain determines if a chain uses EdDSA cryptography
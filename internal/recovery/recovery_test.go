@@ -1,9 +1,14 @@
 package recovery
 
 import (
+	"encoding/hex"
 	"os"
 	"strings"
 	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/rowbotony/vultool/internal/types"
+	"github.com/rowbotony/vultool/internal/vault"
 )
 
 // TestRecoverPrivateKeys_GG20Integration - The main test that actually matters
@@ -108,3 +113,127 @@ func fileExists(filename string) bool {
 	_, err := os.Stat(filename)
 	return err == nil
 }
+
+// TestDeriveTaprootKey checks the tweaked taproot key spends the vault's
+// taproot address: the address of its own x-only key must be the one
+// derived from the vault's public key
+func TestDeriveTaprootKey(t *testing.T) {
+	rootKey := "e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35"
+	chainCode := "873dff81c02f525623fd1fe5167eac3a55a049de3d314bb42ee227ffed37d508"
+	rootBytes, _ := hex.DecodeString(rootKey)
+	pubKey := secp256k1.PrivKeyFromBytes(rootBytes).PubKey()
+	info := &vault.VaultInfo{PublicKeyECDSA: hex.EncodeToString(pubKey.SerializeCompressed()), HexChainCode: chainCode}
+
+	for _, path := range []string{"m/86'/0'/0'/0/0", "m/86'/0'/0'/1/3"} {
+		key, err := deriveTaprootKey(rootKey, chainCode, path)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		want, err := vault.DeriveAddressAtPath(info, types.ChainBitcoinTaproot, path)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		if key.Address != want.Address {
+			t.Errorf("%s: tweaked key pays %s, vault address is %s", path, key.Address, want.Address)
+		}
+		if key.WIF == "" || key.PrivateKey == rootKey {
+			t.Errorf("%s: expected a tweaked key with a WIF, got %+v", path, key)
+		}
+	}
+}
+
+func TestDeriveChildPrivateKey(t *testing.T) {
+	rootKey := "e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35"
+	chainCode := "873dff81c02f525623fd1fe5167eac3a55a049de3d314bb42ee227ffed37d508"
+	rootBytes, _ := hex.DecodeString(rootKey)
+	pubKey := secp256k1.PrivKeyFromBytes(rootBytes).PubKey()
+	info := &vault.VaultInfo{PublicKeyECDSA: hex.EncodeToString(pubKey.SerializeCompressed()), HexChainCode: chainCode}
+
+	for _, path := range []string{"m/44'/118'/0'/0/0", "m/44'/330'/0'/0/0", "m/44'/931'/0'/0/0", "m/44'/195'/0'/0/0", "m/44'/144'/0'/0/0"} {
+		childKey, err := deriveChildPrivateKey(rootKey, chainCode, path)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		childBytes, _ := hex.DecodeString(childKey)
+		want, err := vault.DerivePublicKey(info, path)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		if !secp256k1.PrivKeyFromBytes(childBytes).PubKey().IsEqual(want) {
+			t.Errorf("%s: child key %s does not match the vault's public key there", path, childKey)
+		}
+	}
+}
+
+func TestIsCosmosChain(t *testing.T) {
+	for _, chain := range []SupportedChain{ChainThorChain, ChainMayaChain, ChainCosmos, ChainOsmosis, ChainKujira, ChainDydx, ChainNoble, ChainTerra} {
+		if !IsCosmosChain(chain) || !isECDSAChain(chain) {
+			t.Errorf("%s should be an ECDSA Cosmos chain", chain)
+		}
+		if got, err := LookupChain(string(chain)); err != nil || got != chain {
+			t.Errorf("LookupChain(%s) = %s, %v", chain, got, err)
+		}
+	}
+	if got, err := LookupChain("TRX"); err != nil || got != ChainTron || !isECDSAChain(ChainTron) {
+		t.Errorf("LookupChain(TRX) = %s, %v; want the ECDSA chain tron", got, err)
+	}
+	if IsCosmosChain(ChainEthereum) || IsCosmosChain(ChainTron) {
+		t.Error("Ethereum and TRON are not Cosmos chains")
+	}
+}
+
+func TestXRPLPrivateKey(t *testing.T) {
+	got := xrplPrivateKey("9b51bbe5e93763c5abe42aec4e30b268ebe5f699620a1bebbdc70263e11a8f7d")
+	want := "009B51BBE5E93763C5ABE42AEC4E30B268EBE5F699620A1BEBBDC70263E11A8F7D"
+	if got != want {
+		t.Errorf("xrplPrivateKey = %s, want %s", got, want)
+	}
+	if got, err := LookupChain("ripple"); err != nil || got != ChainXRP || !isECDSAChain(ChainXRP) {
+		t.Errorf("LookupChain(ripple) = %s, %v; want the ECDSA chain xrp", got, err)
+	}
+}
+
+// TestConvertTSSToRecoveredKeys_AccountKeys checks which chains recover the
+// key of the account at their path: MayaChain gets its m/44'/931'/0'/0/0
+// child key, while THORChain, on the same path, keeps the root key
+func TestConvertTSSToRecoveredKeys_AccountKeys(t *testing.T) {
+	rootKey := "e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35"
+	chainCode := "873dff81c02f525623fd1fe5167eac3a55a049de3d314bb42ee227ffed37d508"
+	rootBytes, _ := hex.DecodeString(rootKey)
+	pubKey := secp256k1.PrivKeyFromBytes(rootBytes).PubKey()
+	info := &vault.VaultInfo{PublicKeyECDSA: hex.EncodeToString(pubKey.SerializeCompressed()), HexChainCode: chainCode}
+
+	keys := make(map[SupportedChain]RecoveredKey)
+	for _, key := range convertTSSToRecoveredKeys(&TSSRecoveryResult{PrivateKeyHex: rootKey, ChainCode: chainCode}, ECDSA, info) {
+		keys[key.Chain] = key
+	}
+
+	if keys[ChainCosmos].PrivateKey == rootKey || keys[ChainTron].PrivateKey == rootKey || keys[ChainXRP].PrivateKey == rootKey {
+		t.Error("Cosmos, TRON and XRP should recover their account keys, not the root key")
+	}
+}
+
+// TestConvertTSSToRecoveredKeys_ThorMaya - THORChain and MayaChain share a
+// path but not a recovered key; see importsAccountKey before changing either
+func TestConvertTSSToRecoveredKeys_ThorMaya(t *testing.T) {
+	rootKey := "e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35"
+	chainCode := "873dff81c02f525623fd1fe5167eac3a55a049de3d314bb42ee227ffed37d508"
+	rootBytes, _ := hex.DecodeString(rootKey)
+	pubKey := secp256k1.PrivKeyFromBytes(rootBytes).PubKey()
+	info := &vault.VaultInfo{PublicKeyECDSA: hex.EncodeToString(pubKey.SerializeCompressed()), HexChainCode: chainCode}
+
+	keys := make(map[SupportedChain]RecoveredKey)
+	for _, key := range convertTSSToRecoveredKeys(&TSSRecoveryResult{PrivateKeyHex: rootKey, ChainCode: chainCode}, ECDSA, info) {
+		keys[key.Chain] = key
+	}
+	thor, maya := keys[ChainThorChain], keys[ChainMayaChain]
+	if thor.DerivePath != "m/44'/931'/0'/0/0" || maya.DerivePath != thor.DerivePath {
+		t.Fatalf("Expected both chains at m/44'/931'/0'/0/0, got %q and %q", thor.DerivePath, maya.DerivePath)
+	}
+	if thor.PrivateKey != rootKey {
+		t.Errorf("THORChain key = %s, want the root key %s", thor.PrivateKey, rootKey)
+	}
+	if want := "330cdbce0325f32a8f2e064c856cf7717368f581d1c6c00047e947d770534dd7"; maya.PrivateKey != want {
+		t.Errorf("MayaChain key = %s, want the m/44'/931'/0'/0/0 child key %s", maya.PrivateKey, want)
+	}
+}
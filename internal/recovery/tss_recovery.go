@@ -15,12 +15,15 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
 	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/btcutil/base58"
 	"github.com/btcsuite/btcd/btcutil/hdkeychain"
 	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
 	"github.com/decred/dcrd/dcrec/secp256k1/v4"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/rowbotony/vultool/internal/network"
 	v1 "github.com/vultisig/commondata/go/vultisig/vault/v1"
 	"github.com/vultisig/mobile-tss-lib/tss"
 	"golang.org/x/crypto/ripemd160"
@@ -653,7 +656,7 @@ func deriveBitcoinAddress(rootKey *hdkeychain.ExtendedKey, path string) (string,
 		return "", "", "", err
 	}
 
-	net := &chaincfg.MainNetParams
+	net := network.BitcoinParams()
 
 	// Generate WIF
 	wif, err := btcutil.NewWIF(privKey, net, true)
@@ -673,6 +676,77 @@ func deriveBitcoinAddress(rootKey *hdkeychain.ExtendedKey, path string) (string,
 	return addressPubKey.EncodeAddress(), wif.String(), hex.EncodeToString(privKey.Serialize()), nil
 }
 
+// deriveTaprootKey derives the BIP86 taproot key at path from the root
+// private key, non-hardened like the vault's addresses, and tweaks it per
+// BIP341 with no script tree. The tweaked key signs key-path spends
+// directly and imports into Bitcoin Core as rawtr(WIF).
+func deriveTaprootKey(rootKeyHex, chainCodeHex, path string) (*RecoveredKey, error) {
+	rootKeyBytes, err := hex.DecodeString(rootKeyHex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid root private key: %w", err)
+	}
+	chainCode, err := hex.DecodeString(chainCodeHex)
+	if err != nil || len(chainCode) != 32 {
+		return nil, fmt.Errorf("invalid chain code")
+	}
+	net := network.BitcoinParams()
+	rootKey := hdkeychain.NewExtendedKey(net.HDPrivateKeyID[:], rootKeyBytes, chainCode,
+		[]byte{0x00, 0x00, 0x00, 0x00}, 0, 0, true)
+
+	key, err := deriveHDKey(rootKey, strings.ReplaceAll(path, "'", ""))
+	if err != nil {
+		return nil, err
+	}
+	privKey, err := key.ECPrivKey()
+	if err != nil {
+		return nil, err
+	}
+	tweaked := txscript.TweakTaprootPrivKey(*privKey, nil)
+
+	// The tweaked key's own x-only public key is the output key, so the
+	// address is encoded from it without tweaking again
+	address, err := btcutil.NewAddressTaproot(schnorr.SerializePubKey(tweaked.PubKey()), net)
+	if err != nil {
+		return nil, err
+	}
+	wif, err := btcutil.NewWIF(tweaked, net, true)
+	if err != nil {
+		return nil, err
+	}
+	return &RecoveredKey{
+		Chain:      ChainBitcoinTaproot,
+		PrivateKey: hex.EncodeToString(tweaked.Serialize()),
+		WIF:        wif.String(),
+		Address:    address.EncodeAddress(),
+		DerivePath: path,
+	}, nil
+}
+
+// deriveChildPrivateKey derives the hex private key at path, non-hardened
+// from the root key the way the vault's addresses are derived
+func deriveChildPrivateKey(rootKeyHex, chainCodeHex, path string) (string, error) {
+	rootKeyBytes, err := hex.DecodeString(rootKeyHex)
+	if err != nil {
+		return "", fmt.Errorf("invalid root private key: %w", err)
+	}
+	chainCode, err := hex.DecodeString(chainCodeHex)
+	if err != nil || len(chainCode) != 32 {
+		return "", fmt.Errorf("invalid chain code")
+	}
+	rootKey := hdkeychain.NewExtendedKey(network.BitcoinParams().HDPrivateKeyID[:], rootKeyBytes, chainCode,
+		[]byte{0x00, 0x00, 0x00, 0x00}, 0, 0, true)
+
+	key, err := deriveHDKey(rootKey, strings.ReplaceAll(path, "'", ""))
+	if err != nil {
+		return "", err
+	}
+	privKey, err := key.ECPrivKey()
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(privKey.Serialize()), nil
+}
+
 // deriveBitcoinCashAddress derives a Bitcoin Cash address
 func deriveBitcoinCashAddress(rootKey *hdkeychain.ExtendedKey, path string) (string, string, string, error) {
 	key, err := deriveHDKey(rootKey, path)
@@ -690,7 +764,7 @@ func deriveBitcoinCashAddress(rootKey *hdkeychain.ExtendedKey, path string) (str
 		return "", "", "", err
 	}
 
-	net := &chaincfg.MainNetParams
+	net := network.BitcoinParams()
 
 	// Generate WIF for Bitcoin Cash (same as Bitcoin)
 	wif, err := btcutil.NewWIF(privKey, net, true)
@@ -716,9 +790,8 @@ func deriveLitecoinAddress(rootKey *hdkeychain.ExtendedKey, path string) (string
 		return "", "", "", err
 	}
 
-	// For Litecoin, we need to use Litecoin mainnet params
 	// For now, return a placeholder - proper LTC address would need Litecoin params
-	net := &chaincfg.MainNetParams
+	net := network.LitecoinParams()
 	wif, err := btcutil.NewWIF(privKey, net, true)
 	if err != nil {
 		return "", "", "", err
@@ -741,7 +814,7 @@ func deriveDashAddress(rootKey *hdkeychain.ExtendedKey, path string) (string, st
 		return "", "", "", err
 	}
 
-	net := &chaincfg.MainNetParams
+	net := network.DashParams()
 	wif, err := btcutil.NewWIF(privKey, net, true)
 	if err != nil {
 		return "", "", "", err
@@ -764,7 +837,7 @@ func deriveDogecoinAddress(rootKey *hdkeychain.ExtendedKey, path string) (string
 		return "", "", "", err
 	}
 
-	net := &chaincfg.MainNetParams
+	net := network.DogecoinParams()
 	wif, err := btcutil.NewWIF(privKey, net, true)
 	if err != nil {
 		return "", "", "", err
@@ -787,7 +860,7 @@ func deriveZcashAddress(rootKey *hdkeychain.ExtendedKey, path string) (string, s
 		return "", "", "", err
 	}
 
-	net := &chaincfg.MainNetParams
+	net := network.BitcoinParams()
 	wif, err := btcutil.NewWIF(privKey, net, true)
 	if err != nil {
 		return "", "", "", err
@@ -0,0 +1,381 @@
+// Package relay implements the Vultisig relay protocol: a small HTTP message
+// router that the apps and mobile-tss-lib use to find each other and exchange
+// keygen, reshare and keysign messages. The server keeps all state in memory
+// and forgets idle sessions after a TTL, so it can run on an isolated LAN
+// without the public relay.
+package relay
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultTTL is how long an idle session is kept, matching the public relay
+const DefaultTTL = 5 * time.Minute
+
+// maxBodySize bounds request bodies; ceremony messages are a few hundred KB at most
+const maxBodySize = 16 << 20
+
+// Message is a relayed ceremony message, in the relay's JSON format
+type Message struct {
+	SessionID  string   `json:"session_id,omitempty"`
+	From       string   `json:"from,omitempty"`
+	To         []string `json:"to,omitempty"`
+	Body       string   `json:"body,omitempty"`
+	Hash       string   `json:"hash,omitempty"`
+	SequenceNo int64    `json:"sequence_no,omitempty"`
+}
+
+// sessionState is everything the relay remembers about one session
+type sessionState struct {
+	participants []string
+	started      []string
+	completed    []string
+	keysign      map[string]string    // message ID -> signature reported on completion
+	messages     map[string][]Message // recipient + message ID -> pending messages
+	expires      time.Time
+}
+
+// Server is an in-memory Vultisig relay
+type Server struct {
+	mu       sync.Mutex
+	sessions map[string]*sessionState
+	ttl      time.Duration
+	now      func() time.Time
+}
+
+// NewServer creates a relay whose sessions expire after ttl without activity
+func NewServer(ttl time.Duration) *Server {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Server{
+		sessions: make(map[string]*sessionState),
+		ttl:      ttl,
+		now:      time.Now,
+	}
+}
+
+// Handler returns the relay's HTTP routes
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /ping", s.handlePing)
+
+	mux.HandleFunc("POST /{sessionID}", s.handleRegister)
+	mux.HandleFunc("GET /{sessionID}", s.handleParticipants)
+	mux.HandleFunc("DELETE /{sessionID}", s.handleDeleteSession)
+
+	mux.HandleFunc("POST /start/{sessionID}", s.handleStart)
+	mux.HandleFunc("GET /start/{sessionID}", s.handleStarted)
+
+	mux.HandleFunc("POST /complete/{sessionID}", s.handleComplete)
+	mux.HandleFunc("GET /complete/{sessionID}", s.handleCompleted)
+	mux.HandleFunc("POST /complete/{sessionID}/keysign", s.handleKeysignComplete)
+	mux.HandleFunc("GET /complete/{sessionID}/keysign", s.handleKeysignCompleted)
+
+	mux.HandleFunc("POST /message/{sessionID}", s.handlePostMessage)
+	mux.HandleFunc("GET /message/{sessionID}/{participantID}", s.handleGetMessages)
+	mux.HandleFunc("DELETE /message/{sessionID}/{participantID}/{hash}", s.handleDeleteMessage)
+	return mux
+}
+
+// Sweep drops every session whose TTL has passed and returns how many were removed
+func (s *Server) Sweep() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := 0
+	now := s.now()
+	for id, session := range s.sessions {
+		if now.After(session.expires) {
+			delete(s.sessions, id)
+			removed++
+		}
+	}
+	return removed
+}
+
+// RunSweeper sweeps expired sessions every interval until stop is closed
+func (s *Server) RunSweeper(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.Sweep()
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (s *Server) handlePing(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	_, _ = io.WriteString(w, "Vultisig relay is running")
+}
+
+// handleRegister adds the posted parties to the session, creating it if needed
+func (s *Server) handleRegister(w http.ResponseWriter, r *http.Request) {
+	var parties []string
+	if !decodeBody(w, r, &parties) {
+		return
+	}
+
+	s.mu.Lock()
+	session := s.session(r.PathValue("sessionID"), true)
+	session.participants = appendUnique(session.participants, parties...)
+	s.mu.Unlock()
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *Server) handleParticipants(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	session := s.session(r.PathValue("sessionID"), false)
+	var parties []string
+	if session != nil {
+		parties = append(parties, session.participants...)
+	}
+	s.mu.Unlock()
+
+	if session == nil {
+		http.Error(w, "session not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, parties)
+}
+
+func (s *Server) handleDeleteSession(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	delete(s.sessions, r.PathValue("sessionID"))
+	s.mu.Unlock()
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleStart records the committee the initiating device chose
+func (s *Server) handleStart(w http.ResponseWriter, r *http.Request) {
+	var parties []string
+	if !decodeBody(w, r, &parties) {
+		return
+	}
+
+	s.mu.Lock()
+	session := s.session(r.PathValue("sessionID"), true)
+	session.started = append([]string(nil), parties...)
+	s.mu.Unlock()
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) handleStarted(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	session := s.session(r.PathValue("sessionID"), false)
+	var parties []string
+	if session != nil {
+		parties = append(parties, session.started...)
+	}
+	s.mu.Unlock()
+
+	if len(parties) == 0 {
+		http.Error(w, "session not started", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, parties)
+}
+
+func (s *Server) handleComplete(w http.ResponseWriter, r *http.Request) {
+	var parties []string
+	if !decodeBody(w, r, &parties) {
+		return
+	}
+
+	s.mu.Lock()
+	session := s.session(r.PathValue("sessionID"), true)
+	session.completed = appendUnique(session.completed, parties...)
+	s.mu.Unlock()
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) handleCompleted(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	session := s.session(r.PathValue("sessionID"), false)
+	var parties []string
+	if session != nil {
+		parties = append(parties, session.completed...)
+	}
+	s.mu.Unlock()
+
+	if len(parties) == 0 {
+		http.Error(w, "session not completed", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, parties)
+}
+
+// handleKeysignComplete stores the signature a party reports for a message ID
+func (s *Server) handleKeysignComplete(w http.ResponseWriter, r *http.Request) {
+	body, ok := readBody(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	session := s.session(r.PathValue("sessionID"), true)
+	session.keysign[r.Header.Get("message_id")] = string(body)
+	s.mu.Unlock()
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) handleKeysignCompleted(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	session := s.session(r.PathValue("sessionID"), false)
+	var signature string
+	found := false
+	if session != nil {
+		signature, found = session.keysign[r.Header.Get("message_id")]
+	}
+	s.mu.Unlock()
+
+	if !found {
+		http.Error(w, "keysign not completed", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = io.WriteString(w, signature)
+}
+
+// handlePostMessage queues a message for each of its recipients
+func (s *Server) handlePostMessage(w http.ResponseWriter, r *http.Request) {
+	var msg Message
+	if !decodeBody(w, r, &msg) {
+		return
+	}
+	if msg.From == "" || len(msg.To) == 0 {
+		http.Error(w, "message needs from and to", http.StatusBadRequest)
+		return
+	}
+
+	messageID := r.Header.Get("message_id")
+	s.mu.Lock()
+	session := s.session(r.PathValue("sessionID"), true)
+	for _, to := range msg.To {
+		key := mailbox(to, messageID)
+		session.messages[key] = append(session.messages[key], msg)
+	}
+	s.mu.Unlock()
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// handleGetMessages returns the pending messages for a participant; they stay
+// queued until the participant deletes them by hash
+func (s *Server) handleGetMessages(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	session := s.session(r.PathValue("sessionID"), false)
+	messages := []Message{}
+	if session != nil {
+		messages = append(messages, session.messages[mailbox(r.PathValue("participantID"), r.Header.Get("message_id"))]...)
+	}
+	s.mu.Unlock()
+
+	writeJSON(w, messages)
+}
+
+func (s *Server) handleDeleteMessage(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	if session := s.session(r.PathValue("sessionID"), false); session != nil {
+		key := mailbox(r.PathValue("participantID"), r.Header.Get("message_id"))
+		pending := session.messages[key][:0]
+		for _, msg := range session.messages[key] {
+			if msg.Hash != r.PathValue("hash") {
+				pending = append(pending, msg)
+			}
+		}
+		session.messages[key] = pending
+	}
+	s.mu.Unlock()
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// session looks up a live session and extends its TTL, creating it when asked
+// The caller must hold s.mu.
+func (s *Server) session(id string, create bool) *sessionState {
+	now := s.now()
+	session, ok := s.sessions[id]
+	if ok && now.After(session.expires) {
+		delete(s.sessions, id)
+		ok = false
+	}
+	if !ok {
+		if !create {
+			return nil
+		}
+		session = &sessionState{
+			keysign:  make(map[string]string),
+			messages: make(map[string][]Message),
+		}
+		s.sessions[id] = session
+	}
+	session.expires = now.Add(s.ttl)
+	return session
+}
+
+// mailbox keys a participant's queue; keysign runs one queue per message ID
+func mailbox(participant, messageID string) string {
+	if messageID == "" {
+		return participant
+	}
+	return participant + "/" + messageID
+}
+
+func appendUnique(list []string, items ...string) []string {
+	for _, item := range items {
+		found := false
+		for _, existing := range list {
+			if existing == item {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func readBody(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to read body: %v", err), http.StatusBadRequest)
+		return nil, false
+	}
+	return body, true
+}
+
+func decodeBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	body, ok := readBody(w, r)
+	if !ok {
+		return false
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		http.Error(w, fmt.Sprintf("invalid JSON body: %v", err), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, fmt.Sprintf("failed to encode response: %v", err), http.StatusInternalServerError)
+	}
+}
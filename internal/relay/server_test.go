@@ -0,0 +1,239 @@
+package relay
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testClient speaks the relay protocol the way a device does
+type testClient struct {
+	t       *testing.T
+	baseURL string
+	party   string
+}
+
+// do sends a request; it is used from client goroutines, so it never calls t.Fatal
+func (c *testClient) do(method, path string, body interface{}, messageID string) (int, []byte) {
+	c.t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			c.t.Errorf("Failed to encode body: %v", err)
+			return 0, nil
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, c.baseURL+path, reader)
+	if err != nil {
+		c.t.Errorf("Failed to build request: %v", err)
+		return 0, nil
+	}
+	if messageID != "" {
+		req.Header.Set("message_id", messageID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		c.t.Errorf("%s %s failed: %v", method, path, err)
+		return 0, nil
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, data
+}
+
+// waitFor polls path until it returns 200 with at least n parties
+func (c *testClient) waitFor(path string, n int) ([]string, error) {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		status, data := c.do(http.MethodGet, path, nil, "")
+		if status == http.StatusOK {
+			var parties []string
+			if err := json.Unmarshal(data, &parties); err != nil {
+				return nil, fmt.Errorf("invalid party list: %w", err)
+			}
+			if len(parties) >= n {
+				sort.Strings(parties)
+				return parties, nil
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return nil, fmt.Errorf("%s: timed out waiting for %d parties on %s", c.party, n, path)
+}
+
+// exchange sends one message to peer and waits for the peer's message
+func (c *testClient) exchange(session, peer string) (Message, error) {
+	out := Message{SessionID: session, From: c.party, To: []string{peer}, Body: "hello from " + c.party, Hash: "hash-" + c.party, SequenceNo: 1}
+	if status, _ := c.do(http.MethodPost, "/message/"+session, out, ""); status != http.StatusAccepted {
+		c.t.Errorf("%s: post message returned %d", c.party, status)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		_, data := c.do(http.MethodGet, "/message/"+session+"/"+c.party, nil, "")
+		var messages []Message
+		if err := json.Unmarshal(data, &messages); err != nil {
+			return Message{}, fmt.Errorf("invalid message list: %w", err)
+		}
+		if len(messages) > 0 {
+			c.do(http.MethodDelete, "/message/"+session+"/"+c.party+"/"+messages[0].Hash, nil, "")
+			return messages[0], nil
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return Message{}, fmt.Errorf("%s: no message received", c.party)
+}
+
+// TestRelay_TwoClients - two devices join, start, exchange messages and complete
+func TestRelay_TwoClients(t *testing.T) {
+	server := httptest.NewServer(NewServer(0).Handler())
+	defer server.Close()
+
+	const session = "2f8a1c3e-session"
+	received := make(map[string]Message)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, party := range []string{"alice", "bob"} {
+		wg.Add(1)
+		go func(party string) {
+			defer wg.Done()
+			c := &testClient{t: t, baseURL: server.URL, party: party}
+			peer := map[string]string{"alice": "bob", "bob": "alice"}[party]
+
+			if status, _ := c.do(http.MethodPost, "/"+session, []string{party}, ""); status != http.StatusCreated {
+				t.Errorf("%s: register returned %d", party, status)
+			}
+
+			// alice initiates, as the device showing the QR code does
+			if party == "alice" {
+				parties, err := c.waitFor("/"+session, 2)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				c.do(http.MethodPost, "/start/"+session, parties, "")
+			}
+			started, err := c.waitFor("/start/"+session, 2)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if fmt.Sprint(started) != "[alice bob]" {
+				t.Errorf("%s: unexpected committee %v", party, started)
+			}
+
+			msg, err := c.exchange(session, peer)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			mu.Lock()
+			received[party] = msg
+			mu.Unlock()
+
+			c.do(http.MethodPost, "/complete/"+session, []string{party}, "")
+			if _, err := c.waitFor("/complete/"+session, 2); err != nil {
+				t.Error(err)
+			}
+		}(party)
+	}
+	wg.Wait()
+
+	if received["alice"].Body != "hello from bob" || received["bob"].Body != "hello from alice" {
+		t.Errorf("Unexpected messages: %+v", received)
+	}
+
+	// Deleted messages are gone
+	c := &testClient{t: t, baseURL: server.URL, party: "alice"}
+	if _, data := c.do(http.MethodGet, "/message/"+session+"/alice", nil, ""); string(bytes.TrimSpace(data)) != "[]" {
+		t.Errorf("Expected an empty queue after delete, got %s", data)
+	}
+}
+
+func TestRelay_KeysignMessageIDs(t *testing.T) {
+	server := httptest.NewServer(NewServer(0).Handler())
+	defer server.Close()
+	c := &testClient{t: t, baseURL: server.URL, party: "alice"}
+
+	msg := Message{From: "bob", To: []string{"alice"}, Body: "round1", Hash: "h1"}
+	c.do(http.MethodPost, "/message/s1", msg, "msg-a")
+
+	var messages []Message
+	_, data := c.do(http.MethodGet, "/message/s1/alice", nil, "")
+	_ = json.Unmarshal(data, &messages)
+	if len(messages) != 0 {
+		t.Error("Messages for a message ID must not show up in the default queue")
+	}
+	_, data = c.do(http.MethodGet, "/message/s1/alice", nil, "msg-a")
+	_ = json.Unmarshal(data, &messages)
+	if len(messages) != 1 || messages[0].Body != "round1" {
+		t.Errorf("Expected the message in its message ID queue, got %s", data)
+	}
+
+	if status, _ := c.do(http.MethodGet, "/complete/s1/keysign", nil, "msg-a"); status != http.StatusNotFound {
+		t.Errorf("Expected 404 before keysign completes, got %d", status)
+	}
+	c.do(http.MethodPost, "/complete/s1/keysign", map[string]string{"r": "01", "s": "02"}, "msg-a")
+	status, data := c.do(http.MethodGet, "/complete/s1/keysign", nil, "msg-a")
+	if status != http.StatusOK || !bytes.Contains(data, []byte(`"r":"01"`)) {
+		t.Errorf("Unexpected keysign result %d %s", status, data)
+	}
+}
+
+func TestRelay_SessionTTL(t *testing.T) {
+	relay := NewServer(time.Minute)
+	now := time.Unix(1700000000, 0)
+	relay.now = func() time.Time { return now }
+	server := httptest.NewServer(relay.Handler())
+	defer server.Close()
+	c := &testClient{t: t, baseURL: server.URL, party: "alice"}
+
+	c.do(http.MethodPost, "/s1", []string{"alice"}, "")
+	c.do(http.MethodPost, "/s2", []string{"alice"}, "")
+
+	// Activity extends the TTL
+	now = now.Add(50 * time.Second)
+	if status, _ := c.do(http.MethodGet, "/s1", nil, ""); status != http.StatusOK {
+		t.Errorf("Expected live session, got %d", status)
+	}
+
+	now = now.Add(30 * time.Second)
+	if status, _ := c.do(http.MethodGet, "/s2", nil, ""); status != http.StatusNotFound {
+		t.Errorf("Expected idle session to expire, got %d", status)
+	}
+	if status, _ := c.do(http.MethodGet, "/s1", nil, ""); status != http.StatusOK {
+		t.Errorf("Expected refreshed session to survive, got %d", status)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if removed := relay.Sweep(); removed != 1 {
+		t.Errorf("Expected sweep to remove 1 session, removed %d", removed)
+	}
+}
+
+func TestRelay_RejectsBadInput(t *testing.T) {
+	server := httptest.NewServer(NewServer(0).Handler())
+	defer server.Close()
+	c := &testClient{t: t, baseURL: server.URL, party: "alice"}
+
+	if status, _ := c.do(http.MethodPost, "/message/s1", Message{Body: "no routing"}, ""); status != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a message without from/to, got %d", status)
+	}
+	if status, _ := c.do(http.MethodPost, "/s1", "not a list", ""); status != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid party list, got %d", status)
+	}
+	if status, _ := c.do(http.MethodGet, "/ping", nil, ""); status != http.StatusOK {
+		t.Errorf("Expected ping to answer, got %d", status)
+	}
+}
@@ -0,0 +1,133 @@
+package signer
+
+import (
+	"crypto/ed25519"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+
+	"filippo.io/edwards25519"
+
+	"github.com/rowbotony/vultool/internal/ceremony"
+	"github.com/rowbotony/vultool/internal/recovery"
+	"github.com/rowbotony/vultool/internal/vault"
+)
+
+// EdDSASource signs messages with a vault's EdDSA key, which Solana and SUI
+// use without derivation
+type EdDSASource interface {
+	// SignEdDSA returns an Ed25519 signature of message
+	SignEdDSA(message []byte) (*ceremony.SignatureResult, error)
+	// Describe names the source for progress output
+	Describe() string
+}
+
+// SignEdDSA implements EdDSASource
+func (q *Quorum) SignEdDSA(message []byte) (*ceremony.SignatureResult, error) {
+	return ceremony.Sign(q.shares, recovery.EdDSA, message, "")
+}
+
+// nonceDomain separates EdDSAKey nonces from any other use of the scalar
+const nonceDomain = "vultool ed25519 scalar nonce"
+
+// EdDSAKey signs with the EdDSA key printed by `recover`
+// A TSS EdDSA key is a bare scalar, not an RFC 8032 seed: no seed hashes to
+// it, so wallets that expand seeds derive a different key. EdDSAKey signs
+// with the scalar directly and produces standard Ed25519 signatures.
+type EdDSAKey struct {
+	scalar *edwards25519.Scalar
+	pubKey []byte
+}
+
+// NewEdDSAKey creates an EdDSASource from the scalar `recover` prints: the
+// big-endian hex private key, or the base64 Solana seed or keypair formats.
+// info, when given, must be the vault the key belongs to.
+func NewEdDSAKey(input string, info *vault.VaultInfo) (*EdDSAKey, error) {
+	raw, err := parseEdDSAKey(strings.TrimSpace(input))
+	if err != nil {
+		return nil, err
+	}
+	// Scalars are printed big-endian; edwards25519 reads them little-endian
+	le := make([]byte, 32)
+	copy(le[32-len(raw):], raw)
+	slices.Reverse(le)
+	scalar, err := edwards25519.NewScalar().SetCanonicalBytes(le)
+	if err != nil {
+		return nil, fmt.Errorf("invalid EdDSA key: not a scalar below the group order")
+	}
+
+	key := &EdDSAKey{scalar: scalar, pubKey: new(edwards25519.Point).ScalarBaseMult(scalar).Bytes()}
+	if info != nil && !strings.EqualFold(hex.EncodeToString(key.pubKey), info.PublicKeyEDDSA) {
+		return nil, fmt.Errorf("key does not match the EdDSA public key of vault %s", info.Name)
+	}
+	return key, nil
+}
+
+// parseEdDSAKey decodes the key formats `recover` prints for Solana
+func parseEdDSAKey(input string) ([]byte, error) {
+	if raw, err := hex.DecodeString(strings.TrimPrefix(input, "0x")); err == nil && len(raw) > 0 && len(raw) <= 32 {
+		return raw, nil
+	}
+	if raw, err := base64.StdEncoding.DecodeString(input); err == nil {
+		switch len(raw) {
+		case 32:
+			return raw, nil
+		case 64:
+			// Keypair format: the scalar followed by the public key
+			return raw[:32], nil
+		}
+	}
+	return nil, fmt.Errorf("invalid EdDSA key: expected the hex scalar or base64 key printed by 'recover'")
+}
+
+// PublicKey returns the Ed25519 public key of the scalar
+func (k *EdDSAKey) PublicKey() ed25519.PublicKey {
+	return k.pubKey
+}
+
+// SignEdDSA implements EdDSASource
+// It follows RFC 8032 with the nonce prefix hashed from the scalar, since
+// there is no seed to take it from; signatures are deterministic.
+func (k *EdDSAKey) SignEdDSA(message []byte) (*ceremony.SignatureResult, error) {
+	prefix := sha512.Sum512(append([]byte(nonceDomain), k.scalar.Bytes()...))
+
+	h := sha512.New()
+	h.Write(prefix[32:])
+	h.Write(message)
+	r, err := edwards25519.NewScalar().SetUniformBytes(h.Sum(nil))
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute nonce: %w", err)
+	}
+	R := new(edwards25519.Point).ScalarBaseMult(r).Bytes()
+
+	h.Reset()
+	h.Write(R)
+	h.Write(k.pubKey)
+	h.Write(message)
+	challenge, err := edwards25519.NewScalar().SetUniformBytes(h.Sum(nil))
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute challenge: %w", err)
+	}
+	S := edwards25519.NewScalar().MultiplyAdd(challenge, k.scalar, r).Bytes()
+
+	sig := append(R, S...)
+	if !ed25519.Verify(k.pubKey, message, sig) {
+		return nil, fmt.Errorf("signature does not verify")
+	}
+	return &ceremony.SignatureResult{
+		KeyType:   recovery.EdDSA.String(),
+		PublicKey: hex.EncodeToString(k.pubKey),
+		Message:   hex.EncodeToString(message),
+		R:         hex.EncodeToString(R),
+		S:         hex.EncodeToString(S),
+		Signature: hex.EncodeToString(sig),
+	}, nil
+}
+
+// Describe implements EdDSASource
+func (k *EdDSAKey) Describe() string {
+	return "a recovered EdDSA key"
+}
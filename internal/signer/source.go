@@ -0,0 +1,158 @@
+// Package signer provides the key sources offline signing commands sign with
+package signer
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	tsslib "github.com/bnb-chain/tss-lib/v2/tss"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/vultisig/mobile-tss-lib/tss"
+
+	"github.com/rowbotony/vultool/internal/ceremony"
+	"github.com/rowbotony/vultool/internal/recovery"
+	"github.com/rowbotony/vultool/internal/vault"
+)
+
+// Source signs 32-byte hashes with a vault's ECDSA keys
+// Transaction builders take a Source so the same code signs with a key
+// recovered by `recover` or with a threshold quorum of local shares.
+type Source interface {
+	// SignECDSA signs hash with the key derived at derivePath
+	SignECDSA(hash []byte, derivePath string) (*ceremony.SignatureResult, error)
+	// Describe names the source for progress output
+	Describe() string
+}
+
+// Quorum signs by running a local threshold keysign between ≥t shares; the
+// private key is never assembled
+type Quorum struct {
+	shares []*ceremony.PartyShare
+}
+
+// NewQuorum creates a Source from local shares of one vault
+func NewQuorum(shares []*ceremony.PartyShare) (*Quorum, error) {
+	if len(shares) == 0 {
+		return nil, fmt.Errorf("no shares given")
+	}
+	return &Quorum{shares: shares}, nil
+}
+
+// SignECDSA implements Source
+func (q *Quorum) SignECDSA(hash []byte, derivePath string) (*ceremony.SignatureResult, error) {
+	return ceremony.Sign(q.shares, recovery.ECDSA, hash, derivePath)
+}
+
+// Describe implements Source
+func (q *Quorum) Describe() string {
+	return fmt.Sprintf("a threshold keysign of %d local shares", len(q.shares))
+}
+
+// PrivateKey signs with a key printed by `recover`
+// `recover` prints the vault's root key, which is derived along each path
+// the way the vault's public key is; a key that is not the root is taken to
+// be already derived and is used for every path.
+type PrivateKey struct {
+	key       *ecdsa.PrivateKey
+	chainCode []byte // set when key is the vault root
+}
+
+// NewPrivateKey creates a Source from a secp256k1 private key in hex or WIF;
+// info, when given, tells whether it is the vault's root key
+func NewPrivateKey(input string, info *vault.VaultInfo) (*PrivateKey, error) {
+	d, err := ceremony.ParseECDSAKey(input)
+	if err != nil {
+		return nil, err
+	}
+	key, err := ethcrypto.ToECDSA(d.FillBytes(make([]byte, 32)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ECDSA key: %w", err)
+	}
+
+	source := &PrivateKey{key: key}
+	if info != nil && strings.EqualFold(compressedHex(&key.PublicKey), info.PublicKeyECDSA) {
+		if source.chainCode, err = hex.DecodeString(info.HexChainCode); err != nil || len(source.chainCode) != 32 {
+			return nil, fmt.Errorf("vault %s has an invalid chain code", info.Name)
+		}
+	}
+	return source, nil
+}
+
+// derive returns the key for derivePath: the root key derived non-hardened
+// (Vultisig semantics), or the key itself when it is not the root
+func (k *PrivateKey) derive(derivePath string) (*ecdsa.PrivateKey, bool, error) {
+	if k.chainCode == nil {
+		return k.key, false, nil
+	}
+	path, err := tss.GetDerivePathBytes(derivePath)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid derivation path %q: %w", derivePath, err)
+	}
+
+	extended := hdkeychain.NewExtendedKey(chaincfg.MainNetParams.HDPrivateKeyID[:],
+		ethcrypto.FromECDSA(k.key), k.chainCode, []byte{0x00, 0x00, 0x00, 0x00}, 0, 0, true)
+	for _, index := range path {
+		if extended, err = extended.Derive(index); err != nil {
+			return nil, false, fmt.Errorf("failed to derive key for %s: %w", derivePath, err)
+		}
+	}
+	child, err := extended.ECPrivKey()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to derive key for %s: %w", derivePath, err)
+	}
+	return child.ToECDSA(), true, nil
+}
+
+// SignECDSA implements Source
+func (k *PrivateKey) SignECDSA(hash []byte, derivePath string) (*ceremony.SignatureResult, error) {
+	if len(hash) != 32 {
+		return nil, fmt.Errorf("ECDSA signing needs a 32-byte hash, got %d bytes", len(hash))
+	}
+	key, derived, err := k.derive(derivePath)
+	if err != nil {
+		return nil, err
+	}
+	// RFC 6979 deterministic, low-S, with the recovery ID in the last byte
+	sig, err := ethcrypto.Sign(hash, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign: %w", err)
+	}
+
+	r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:64])
+	derSig, err := tss.GetDERSignature(r, s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode DER signature: %w", err)
+	}
+	recoveryID := int(sig[64])
+
+	result := &ceremony.SignatureResult{
+		KeyType:      recovery.ECDSA.String(),
+		PublicKey:    compressedHex(&key.PublicKey),
+		Message:      hex.EncodeToString(hash),
+		R:            hex.EncodeToString(sig[:32]),
+		S:            hex.EncodeToString(sig[32:64]),
+		V:            &recoveryID,
+		Signature:    hex.EncodeToString(sig[:64]),
+		DERSignature: hex.EncodeToString(derSig),
+	}
+	if derived {
+		result.DerivePath = derivePath
+	}
+	return result, nil
+}
+
+// Describe implements Source
+func (k *PrivateKey) Describe() string {
+	return "a recovered private key"
+}
+
+// compressedHex encodes a secp256k1 public key the way vaults store it
+func compressedHex(pub *ecdsa.PublicKey) string {
+	return hex.EncodeToString(elliptic.MarshalCompressed(tsslib.S256(), pub.X, pub.Y))
+}
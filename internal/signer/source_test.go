@@ -0,0 +1,135 @@
+package signer
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"slices"
+	"strings"
+	"testing"
+
+	"filippo.io/edwards25519"
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/rowbotony/vultool/internal/vault"
+)
+
+const rootKey = "1b8254db800dcef00fd23060c813b0304430377afc65c3e34c7fd65f42f5aeab"
+
+func rootVault(t *testing.T) *vault.VaultInfo {
+	t.Helper()
+	key, err := ethcrypto.HexToECDSA(rootKey)
+	if err != nil {
+		t.Fatalf("HexToECDSA failed: %v", err)
+	}
+	return &vault.VaultInfo{
+		Name:           "root-test",
+		PublicKeyECDSA: compressedHex(&key.PublicKey),
+		HexChainCode:   strings.Repeat("42", 32),
+	}
+}
+
+func signedBy(t *testing.T, source Source, path string) common.Address {
+	t.Helper()
+	hash := ethcrypto.Keccak256([]byte("vultool"))
+	result, err := source.SignECDSA(hash, path)
+	if err != nil {
+		t.Fatalf("SignECDSA failed: %v", err)
+	}
+	sig, _ := hex.DecodeString(result.Signature)
+	pub, err := ethcrypto.SigToPub(hash, append(sig, byte(*result.V)))
+	if err != nil {
+		t.Fatalf("SigToPub failed: %v", err)
+	}
+	return ethcrypto.PubkeyToAddress(*pub)
+}
+
+// TestPrivateKey_RootIsDerived - the root key 'recover' prints signs for the vault's derived addresses
+func TestPrivateKey_RootIsDerived(t *testing.T) {
+	info := rootVault(t)
+	var ethAddress string
+	for _, addr := range vault.DeriveAddressesFromVault(info) {
+		if addr.Chain == "Ethereum" {
+			ethAddress = addr.Address
+		}
+	}
+
+	source, err := NewPrivateKey(rootKey, info)
+	if err != nil {
+		t.Fatalf("NewPrivateKey failed: %v", err)
+	}
+	if got := signedBy(t, source, "m/44'/60'/0'/0/0"); !strings.EqualFold(got.Hex(), ethAddress) {
+		t.Errorf("Root key signed as %s, want the vault's Ethereum address %s", got.Hex(), ethAddress)
+	}
+
+	// Without the vault the key is used as given
+	plain, _ := NewPrivateKey(rootKey, nil)
+	key, _ := ethcrypto.HexToECDSA(rootKey)
+	if got := signedBy(t, plain, "m/44'/60'/0'/0/0"); got != ethcrypto.PubkeyToAddress(key.PublicKey) {
+		t.Errorf("Plain key signed as %s", got.Hex())
+	}
+}
+
+func TestNewPrivateKey_Invalid(t *testing.T) {
+	for _, input := range []string{"", "abcd", strings.Repeat("00", 32), strings.Repeat("zz", 32)} {
+		if _, err := NewPrivateKey(input, nil); err == nil {
+			t.Errorf("Expected %q to be rejected", input)
+		}
+	}
+}
+
+// TestEdDSAKey_StandardSignatures - signing with the expanded scalar of an
+// RFC 8032 seed gives signatures standard Ed25519 verifies
+func TestEdDSAKey_StandardSignatures(t *testing.T) {
+	seed := bytes.Repeat([]byte{3}, 32)
+	expanded := sha512.Sum512(seed)
+	scalar, err := edwards25519.NewScalar().SetBytesWithClamping(expanded[:32])
+	if err != nil {
+		t.Fatalf("SetBytesWithClamping failed: %v", err)
+	}
+	le := scalar.Bytes()
+	slices.Reverse(le)
+
+	key, err := NewEdDSAKey(hex.EncodeToString(le), nil)
+	if err != nil {
+		t.Fatalf("NewEdDSAKey failed: %v", err)
+	}
+	pubKey := ed25519.NewKeyFromSeed(seed).Public().(ed25519.PublicKey)
+	if !bytes.Equal(key.PublicKey(), pubKey) {
+		t.Fatal("Scalar does not give the seed's public key")
+	}
+	message := []byte("solana message")
+	result, err := key.SignEdDSA(message)
+	if err != nil {
+		t.Fatalf("SignEdDSA failed: %v", err)
+	}
+	sig, _ := hex.DecodeString(result.Signature)
+	if !ed25519.Verify(pubKey, message, sig) {
+		t.Error("Signature does not verify")
+	}
+	again, _ := key.SignEdDSA(message)
+	if again.Signature != result.Signature {
+		t.Error("Expected deterministic signatures")
+	}
+
+	// The base64 keypair format 'recover' prints carries the same scalar
+	keypair := base64.StdEncoding.EncodeToString(append(le, pubKey...))
+	if fromKeypair, err := NewEdDSAKey(keypair, nil); err != nil || !bytes.Equal(fromKeypair.PublicKey(), pubKey) {
+		t.Errorf("Keypair format gave %v", err)
+	}
+}
+
+func TestNewEdDSAKey_Invalid(t *testing.T) {
+	info := &vault.VaultInfo{Name: "eddsa", PublicKeyEDDSA: strings.Repeat("00", 32)}
+	if _, err := NewEdDSAKey(strings.Repeat("01", 32), info); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Errorf("Expected a key of another vault to be rejected, got: %v", err)
+	}
+	for _, input := range []string{"", "zz", strings.Repeat("ff", 32), strings.Repeat("01", 33)} {
+		if _, err := NewEdDSAKey(input, nil); err == nil {
+			t.Errorf("Expected %q to be rejected", input)
+		}
+	}
+}
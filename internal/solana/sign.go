@@ -0,0 +1,81 @@
+package solana
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcutil/base58"
+
+	"github.com/rowbotony/vultool/internal/signer"
+	"github.com/rowbotony/vultool/internal/vault"
+)
+
+// SignedTransaction is a transaction after the vault signed its slot
+type SignedTransaction struct {
+	Signer       string     `json:"signer"`
+	FeePayer     string     `json:"fee_payer"`
+	SignerIndex  int        `json:"signer_index"`
+	Signature    string     `json:"signature"` // base58; the transaction ID when the vault pays the fee
+	Complete     bool       `json:"complete"`
+	Version      string     `json:"version"`
+	Blockhash    string     `json:"recent_blockhash"`
+	Instructions int        `json:"instructions"`
+	Transfers    []Transfer `json:"transfers,omitempty"`
+	Base64       string     `json:"base64"`
+	Base58       string     `json:"base58"`
+}
+
+// VaultPublicKey returns the vault's Ed25519 public key, which is its
+// Solana address without derivation
+func VaultPublicKey(info *vault.VaultInfo) (ed25519.PublicKey, error) {
+	pubKey, err := hex.DecodeString(info.PublicKeyEDDSA)
+	if err != nil || len(pubKey) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("vault %s has an invalid EdDSA public key", info.Name)
+	}
+	return pubKey, nil
+}
+
+// Sign signs the transaction's message with source for the vault and puts
+// the signature in the vault's slot. The signature must verify against the
+// vault's EdDSA public key; other signers' slots are left as they are.
+func Sign(tx *Transaction, source signer.EdDSASource, info *vault.VaultInfo) (*SignedTransaction, error) {
+	pubKey, err := VaultPublicKey(info)
+	if err != nil {
+		return nil, err
+	}
+	index := tx.Message.SignerIndex(pubKey)
+	if index < 0 {
+		return nil, fmt.Errorf("vault %s (%s) is not a signer of this transaction", info.Name, base58.Encode(pubKey))
+	}
+
+	result, err := source.SignEdDSA(tx.Message.Raw)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := hex.DecodeString(result.Signature)
+	if err != nil || !ed25519.Verify(pubKey, tx.Message.Raw, sig) {
+		return nil, fmt.Errorf("signature does not verify against the vault's EdDSA public key")
+	}
+	tx.Signatures[index] = sig
+
+	version := "legacy"
+	if tx.Message.Version >= 0 {
+		version = fmt.Sprintf("v%d", tx.Message.Version)
+	}
+	raw := tx.Serialize()
+	return &SignedTransaction{
+		Signer:       base58.Encode(pubKey),
+		FeePayer:     base58.Encode(tx.Message.AccountKeys[0]),
+		SignerIndex:  index,
+		Signature:    base58.Encode(sig),
+		Complete:     tx.Complete(),
+		Version:      version,
+		Blockhash:    base58.Encode(tx.Message.RecentBlockhash),
+		Instructions: len(tx.Message.Instructions),
+		Transfers:    tx.Message.Transfers(),
+		Base64:       base64.StdEncoding.EncodeToString(raw),
+		Base58:       base58.Encode(raw),
+	}, nil
+}
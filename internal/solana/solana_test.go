@@ -0,0 +1,154 @@
+package solana
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/base58"
+
+	"github.com/rowbotony/vultool/internal/signer"
+	"github.com/rowbotony/vultool/internal/vault"
+)
+
+// A 2-of-2 test vault's EdDSA key as `recover` prints it, and its public key
+const (
+	testScalar = "0fa1aba8a381103f1c689a46f8016f925c0977ea35d218edc2458978ff208c65"
+	testPubKey = "db977878c6e106e9a93145dbe52580a06ebb1af733a0ad65ed009ea54b014a5b"
+)
+
+func testVault() *vault.VaultInfo {
+	return &vault.VaultInfo{Name: "solana-test", PublicKeyEDDSA: testPubKey}
+}
+
+// transferMessage builds a legacy message in which signers pay to, the
+// first signer paying the fee
+func transferMessage(signers [][]byte, to []byte, lamports uint64, v0 bool) []byte {
+	var b bytes.Buffer
+	if v0 {
+		b.WriteByte(versionPrefix)
+	}
+	b.Write([]byte{byte(len(signers)), 0, 1})
+	b.Write(compactU16(len(signers) + 2))
+	for _, s := range signers {
+		b.Write(s)
+	}
+	b.Write(to)
+	b.Write(systemProgram)
+	b.Write(bytes.Repeat([]byte{0xbb}, 32))
+
+	data := make([]byte, 12)
+	binary.LittleEndian.PutUint32(data, 2)
+	binary.LittleEndian.PutUint64(data[4:], lamports)
+	b.Write(compactU16(1))
+	b.WriteByte(byte(len(signers) + 1))
+	b.Write(compactU16(2))
+	b.Write([]byte{0, byte(len(signers))})
+	b.Write(compactU16(len(data)))
+	b.Write(data)
+	if v0 {
+		b.Write(compactU16(0))
+	}
+	return b.Bytes()
+}
+
+func vaultKey(t *testing.T) []byte {
+	t.Helper()
+	pubKey, err := VaultPublicKey(testVault())
+	if err != nil {
+		t.Fatalf("VaultPublicKey failed: %v", err)
+	}
+	return pubKey
+}
+
+func TestSign_RecoveredScalar(t *testing.T) {
+	info := testVault()
+	key, err := signer.NewEdDSAKey(testScalar, info)
+	if err != nil {
+		t.Fatalf("NewEdDSAKey failed: %v", err)
+	}
+	to := bytes.Repeat([]byte{7}, 32)
+	message := transferMessage([][]byte{vaultKey(t)}, to, 1500000, false)
+
+	tx, err := Decode([]byte(base64.StdEncoding.EncodeToString(message)))
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	signed, err := Sign(tx, key, info)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	if !signed.Complete || signed.Signer != "FnCFLUpgWkSDMufP6y4c9LsHvJDpkxi2je4aFAYMWdgE" || signed.Version != "legacy" {
+		t.Errorf("Unexpected result: %+v", signed)
+	}
+	if len(signed.Transfers) != 1 || signed.Transfers[0].Lamports != 1500000 || signed.Transfers[0].To != base58.Encode(to) {
+		t.Errorf("Unexpected transfers: %+v", signed.Transfers)
+	}
+
+	// The wire transaction reads back the same in both encodings and verifies
+	for _, encoded := range []string{signed.Base64, signed.Base58} {
+		again, err := Decode([]byte(encoded))
+		if err != nil {
+			t.Fatalf("Decode of the signed transaction failed: %v", err)
+		}
+		if !bytes.Equal(again.Message.Raw, message) || !ed25519.Verify(vaultKey(t), message, again.Signatures[0]) {
+			t.Error("Signed transaction does not carry a valid vault signature")
+		}
+		if base58.Encode(again.Signatures[0]) != signed.Signature {
+			t.Error("Signature differs from the reported one")
+		}
+	}
+}
+
+// TestSign_SecondSigner - a v0 transaction the vault co-signs stays incomplete
+func TestSign_SecondSigner(t *testing.T) {
+	info := testVault()
+	key, _ := signer.NewEdDSAKey(testScalar, info)
+	payer := bytes.Repeat([]byte{9}, 32)
+	message := transferMessage([][]byte{payer, vaultKey(t)}, bytes.Repeat([]byte{7}, 32), 42, true)
+
+	wire := append(append(compactU16(2), make([]byte, 128)...), message...)
+	tx, err := Decode(wire)
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	signed, err := Sign(tx, key, info)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	if signed.Complete || signed.SignerIndex != 1 || signed.Version != "v0" || signed.FeePayer != base58.Encode(payer) {
+		t.Errorf("Unexpected result: %+v", signed)
+	}
+	if !bytes.Equal(tx.Signatures[0], make([]byte, 64)) {
+		t.Error("The fee payer's slot must be left empty")
+	}
+}
+
+func TestSign_Errors(t *testing.T) {
+	info := testVault()
+	key, _ := signer.NewEdDSAKey(testScalar, info)
+	other := bytes.Repeat([]byte{9}, 32)
+	tx, err := Decode(transferMessage([][]byte{other}, vaultKey(t), 1, false))
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	// Receiving funds does not make the vault a signer
+	if _, err := Sign(tx, key, info); err == nil || !strings.Contains(err.Error(), "not a signer") {
+		t.Errorf("Expected a transaction without the vault as signer to be rejected, got: %v", err)
+	}
+
+	message := transferMessage([][]byte{other}, vaultKey(t), 1, false)
+	for name, input := range map[string][]byte{
+		"truncated": message[:len(message)-3],
+		"trailing":  append(append([]byte(nil), message...), 0),
+		"garbage":   []byte("not a transaction"),
+		"version 1": append([]byte{versionPrefix | 1}, message...),
+	} {
+		if _, err := Decode(input); err == nil {
+			t.Errorf("%s: expected Decode to fail", name)
+		}
+	}
+}
@@ -0,0 +1,320 @@
+// Package solana parses and signs Solana transactions offline
+package solana
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil/base58"
+)
+
+const (
+	signatureSize = 64
+	pubKeySize    = 32
+
+	// versionPrefix marks a versioned message; legacy messages start with
+	// their signature count, which never has the top bit set
+	versionPrefix = 0x80
+)
+
+// systemProgram is the System Program's address, all zero bytes
+var systemProgram = make([]byte, pubKeySize)
+
+// Transaction is a Solana transaction: signature slots and the message they sign
+type Transaction struct {
+	Signatures [][]byte
+	Message    *Message
+}
+
+// Message is the signed part of a transaction
+type Message struct {
+	Raw []byte // exactly the bytes signers sign
+
+	Version           int // -1 for legacy messages
+	RequiredSigs      int
+	ReadonlySigned    int
+	ReadonlyUnsigned  int
+	AccountKeys       [][]byte
+	RecentBlockhash   []byte
+	Instructions      []Instruction
+	LookupTableLoaded bool // a v0 message loads accounts from lookup tables
+}
+
+// Instruction is one compiled instruction of a message
+type Instruction struct {
+	ProgramIndex int
+	Accounts     []int
+	Data         []byte
+}
+
+// Decode reads a transaction given as base64, base58, hex or raw bytes.
+// A bare message is accepted too and gets empty signature slots.
+func Decode(input []byte) (*Transaction, error) {
+	candidates := [][]byte{input}
+	text := strings.TrimSpace(string(input))
+	if raw, err := hex.DecodeString(strings.TrimPrefix(text, "0x")); err == nil {
+		candidates = append([][]byte{raw}, candidates...)
+	}
+	if raw, err := base64.StdEncoding.DecodeString(text); err == nil {
+		candidates = append(candidates, raw)
+	}
+	if raw := base58.Decode(text); len(raw) > 0 {
+		candidates = append(candidates, raw)
+	}
+
+	var firstErr error
+	for _, data := range candidates {
+		tx, err := parse(data)
+		if err == nil {
+			return tx, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return nil, fmt.Errorf("not a Solana transaction or message: %w", firstErr)
+}
+
+// parse reads a wire transaction, or a bare message when data is not one
+func parse(data []byte) (*Transaction, error) {
+	r := bytes.NewReader(data)
+	if count, err := readCompactU16(r); err == nil && count > 0 && r.Len() >= count*signatureSize {
+		sigs := make([][]byte, count)
+		for i := range sigs {
+			sigs[i] = make([]byte, signatureSize)
+			_, _ = r.Read(sigs[i])
+		}
+		msg, err := ParseMessage(data[len(data)-r.Len():])
+		if err == nil && msg.RequiredSigs == count {
+			return &Transaction{Signatures: sigs, Message: msg}, nil
+		}
+	}
+
+	msg, err := ParseMessage(data)
+	if err != nil {
+		return nil, err
+	}
+	sigs := make([][]byte, msg.RequiredSigs)
+	for i := range sigs {
+		sigs[i] = make([]byte, signatureSize)
+	}
+	return &Transaction{Signatures: sigs, Message: msg}, nil
+}
+
+// ParseMessage reads a legacy or v0 message and checks it is well formed
+func ParseMessage(data []byte) (*Message, error) {
+	r := bytes.NewReader(data)
+	msg := &Message{Raw: data, Version: -1}
+
+	first, err := r.ReadByte()
+	if err != nil {
+		return nil, fmt.Errorf("empty message")
+	}
+	if first&versionPrefix != 0 {
+		msg.Version = int(first &^ versionPrefix)
+		if msg.Version != 0 {
+			return nil, fmt.Errorf("unsupported message version %d", msg.Version)
+		}
+		if first, err = r.ReadByte(); err != nil {
+			return nil, fmt.Errorf("truncated message header")
+		}
+	}
+	header := []int{int(first), 0, 0}
+	for i := 1; i < 3; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("truncated message header")
+		}
+		header[i] = int(b)
+	}
+	msg.RequiredSigs, msg.ReadonlySigned, msg.ReadonlyUnsigned = header[0], header[1], header[2]
+
+	keyCount, err := readCompactU16(r)
+	if err != nil {
+		return nil, err
+	}
+	if msg.RequiredSigs == 0 || keyCount < msg.RequiredSigs || msg.ReadonlySigned >= msg.RequiredSigs ||
+		msg.ReadonlyUnsigned > keyCount-msg.RequiredSigs {
+		return nil, fmt.Errorf("invalid message header")
+	}
+	for i := 0; i < keyCount; i++ {
+		key, err := readBytes(r, pubKeySize)
+		if err != nil {
+			return nil, fmt.Errorf("truncated account keys")
+		}
+		msg.AccountKeys = append(msg.AccountKeys, key)
+	}
+	if msg.RecentBlockhash, err = readBytes(r, 32); err != nil {
+		return nil, fmt.Errorf("truncated recent blockhash")
+	}
+
+	instructionCount, err := readCompactU16(r)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < instructionCount; i++ {
+		var ix Instruction
+		program, err := r.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("truncated instruction %d", i)
+		}
+		ix.ProgramIndex = int(program)
+		accounts, err := readCompactArray(r)
+		if err != nil {
+			return nil, fmt.Errorf("instruction %d: %w", i, err)
+		}
+		for _, account := range accounts {
+			ix.Accounts = append(ix.Accounts, int(account))
+		}
+		if ix.Data, err = readCompactArray(r); err != nil {
+			return nil, fmt.Errorf("instruction %d: %w", i, err)
+		}
+		msg.Instructions = append(msg.Instructions, ix)
+	}
+
+	if msg.Version == 0 {
+		tables, err := readCompactU16(r)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < tables; i++ {
+			if _, err := readBytes(r, pubKeySize); err != nil {
+				return nil, fmt.Errorf("truncated address table lookup %d", i)
+			}
+			for j := 0; j < 2; j++ {
+				indexes, err := readCompactArray(r)
+				if err != nil {
+					return nil, fmt.Errorf("address table lookup %d: %w", i, err)
+				}
+				msg.LookupTableLoaded = msg.LookupTableLoaded || len(indexes) > 0
+			}
+		}
+	}
+	if r.Len() != 0 {
+		return nil, fmt.Errorf("%d unexpected trailing bytes", r.Len())
+	}
+	return msg, nil
+}
+
+// Serialize encodes the transaction in wire format
+func (t *Transaction) Serialize() []byte {
+	var b bytes.Buffer
+	b.Write(compactU16(len(t.Signatures)))
+	for _, sig := range t.Signatures {
+		b.Write(sig)
+	}
+	b.Write(t.Message.Raw)
+	return b.Bytes()
+}
+
+// Complete reports whether every required signature is present
+func (t *Transaction) Complete() bool {
+	for _, sig := range t.Signatures {
+		if bytes.Equal(sig, make([]byte, signatureSize)) {
+			return false
+		}
+	}
+	return true
+}
+
+// SignerIndex returns the signature slot of pubKey, or -1 when the message
+// does not require its signature
+func (m *Message) SignerIndex(pubKey []byte) int {
+	for i := 0; i < m.RequiredSigs; i++ {
+		if bytes.Equal(m.AccountKeys[i], pubKey) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Transfer is a System Program transfer found in a message
+type Transfer struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Lamports uint64 `json:"lamports"`
+}
+
+// Transfers decodes the message's native SOL transfers
+func (m *Message) Transfers() []Transfer {
+	var transfers []Transfer
+	for _, ix := range m.Instructions {
+		if ix.ProgramIndex >= len(m.AccountKeys) || !bytes.Equal(m.AccountKeys[ix.ProgramIndex], systemProgram) {
+			continue
+		}
+		// SystemInstruction::Transfer is index 2 followed by the u64 amount
+		if len(ix.Data) != 12 || binary.LittleEndian.Uint32(ix.Data) != 2 || len(ix.Accounts) < 2 {
+			continue
+		}
+		transfers = append(transfers, Transfer{
+			From:     m.accountAddress(ix.Accounts[0]),
+			To:       m.accountAddress(ix.Accounts[1]),
+			Lamports: binary.LittleEndian.Uint64(ix.Data[4:]),
+		})
+	}
+	return transfers
+}
+
+// accountAddress names an account by index; v0 messages can index accounts
+// loaded from lookup tables, which the message itself does not list
+func (m *Message) accountAddress(index int) string {
+	if index < len(m.AccountKeys) {
+		return base58.Encode(m.AccountKeys[index])
+	}
+	return fmt.Sprintf("(lookup table account %d)", index)
+}
+
+// readCompactU16 reads Solana's shortvec length encoding
+func readCompactU16(r *bytes.Reader) (int, error) {
+	value := 0
+	for i := 0; i < 3; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, fmt.Errorf("truncated length")
+		}
+		value |= int(b&0x7f) << (7 * i)
+		if b&0x80 == 0 {
+			return value, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid length encoding")
+}
+
+// readCompactArray reads a shortvec-prefixed byte array
+func readCompactArray(r *bytes.Reader) ([]byte, error) {
+	n, err := readCompactU16(r)
+	if err != nil {
+		return nil, err
+	}
+	data, err := readBytes(r, n)
+	if err != nil {
+		return nil, fmt.Errorf("truncated array of %d bytes", n)
+	}
+	return data, nil
+}
+
+func readBytes(r *bytes.Reader, n int) ([]byte, error) {
+	if r.Len() < n {
+		return nil, fmt.Errorf("truncated")
+	}
+	data := make([]byte, n)
+	_, _ = r.Read(data)
+	return data, nil
+}
+
+// compactU16 encodes n in Solana's shortvec length encoding
+func compactU16(n int) []byte {
+	var out []byte
+	for {
+		b := byte(n & 0x7f)
+		n >>= 7
+		if n == 0 {
+			return append(out, b)
+		}
+		out = append(out, b|0x80)
+	}
+}
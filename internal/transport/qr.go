@@ -0,0 +1,239 @@
+package transport
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// QROptions configures a QRTransport
+type QROptions struct {
+	// Show displays the frames of one outbound message; caption names its
+	// recipient. A display that loops until ctx is done is stopped once the
+	// operator presses Enter or scans the next frame.
+	Show func(ctx context.Context, caption string, frames []string) error
+	// Scanned yields the text of scanned frames, one frame per line
+	Scanned io.Reader
+	// Status receives progress notes for the operator; may be nil
+	Status io.Writer
+	// ChunkSize is the payload bytes per frame, DefaultChunkSize when 0
+	ChunkSize int
+}
+
+// QRTransport is an Interface for devices that are never networked
+// Outbound messages are shown as multi-part QR frames and inbound messages
+// are read back from the frame text a scanner produces, so an operator
+// carries every round between machines with a camera. Bodies are encrypted
+// with the shared key because the codes are visible to anyone nearby.
+type QRTransport struct {
+	cipher    *messageCipher
+	show      func(ctx context.Context, caption string, frames []string) error
+	scanned   io.Reader
+	status    io.Writer
+	chunkSize int
+
+	readOnce sync.Once
+	lines    chan string
+	readErr  error
+
+	mu      sync.Mutex
+	decoder *FrameDecoder
+	pending map[string][]string // channel + recipient -> decrypted bodies
+}
+
+// qrEnvelope is the payload one QR message carries
+type qrEnvelope struct {
+	Channel string `json:"c"`
+	From    string `json:"f"`
+	To      string `json:"t"`
+	Body    string `json:"b"`
+}
+
+// NewQRTransport creates a QR transport encrypting bodies with the shared hex key
+func NewQRTransport(encryptionKeyHex string, opts QROptions) (*QRTransport, error) {
+	if opts.Show == nil || opts.Scanned == nil {
+		return nil, fmt.Errorf("QR transport needs a display and a scanner input")
+	}
+	messages, err := newMessageCipher(encryptionKeyHex)
+	if err != nil {
+		return nil, err
+	}
+	status := opts.Status
+	if status == nil {
+		status = io.Discard
+	}
+
+	return &QRTransport{
+		cipher:    messages,
+		show:      opts.Show,
+		scanned:   opts.Scanned,
+		status:    status,
+		chunkSize: opts.ChunkSize,
+		decoder:   NewFrameDecoder(),
+		pending:   make(map[string][]string),
+	}, nil
+}
+
+// Join implements Interface. Without a network there is no one to ask, so the
+// committee is whatever every operator typed in.
+func (t *QRTransport) Join(ctx context.Context, party string, parties []string) ([]string, error) {
+	committee := append([]string(nil), parties...)
+	sort.Strings(committee)
+	if !containsString(committee, party) {
+		return nil, fmt.Errorf("party %s is not in the committee %v", party, parties)
+	}
+	return committee, nil
+}
+
+// Channel implements Interface
+func (t *QRTransport) Channel(id string) Channel {
+	return &qrChannel{transport: t, id: id}
+}
+
+// Complete implements Interface
+func (t *QRTransport) Complete(ctx context.Context, party string) error {
+	_, _ = fmt.Fprintf(t.status, "✅ %s is done; keep showing any remaining codes until every device finished\n", party)
+	return nil
+}
+
+// startReading starts the scanner reader on first use
+func (t *QRTransport) startReading() {
+	t.readOnce.Do(func() {
+		t.lines = make(chan string)
+		go func() {
+			scanner := bufio.NewScanner(t.scanned)
+			scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+			for scanner.Scan() {
+				t.lines <- scanner.Text()
+			}
+			t.readErr = scanner.Err()
+			close(t.lines)
+		}()
+	})
+}
+
+// next returns the next scanned line
+func (t *QRTransport) next(ctx context.Context) (string, error) {
+	t.startReading()
+	select {
+	case line, ok := <-t.lines:
+		if !ok {
+			if t.readErr != nil {
+				return "", fmt.Errorf("failed to read scanned frames: %w", t.readErr)
+			}
+			return "", fmt.Errorf("scanner input closed")
+		}
+		return line, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// qrChannel is one protocol run carried over QR codes
+type qrChannel struct {
+	transport *QRTransport
+	id        string
+}
+
+// Send implements Channel
+func (ch *qrChannel) Send(from, to, body string) error {
+	t := ch.transport
+	encrypted, err := t.cipher.encrypt(body, route("", ch.id, from, to))
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(qrEnvelope{Channel: ch.id, From: from, To: to, Body: encrypted})
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+	frames, err := EncodeFrames(payload, t.chunkSize)
+	if err != nil {
+		return err
+	}
+	return t.display(fmt.Sprintf("%s → %s (%s)", from, to, ch.id), frames)
+}
+
+// display shows one message until the display returns on its own or the
+// operator moves on: an empty line just stops it, a scanned frame is kept
+func (t *QRTransport) display(caption string, frames []string) error {
+	t.startReading()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- t.show(ctx, caption, frames) }()
+
+	select {
+	case err := <-done:
+		return err
+	case line, ok := <-t.lines:
+		cancel()
+		err := <-done
+		if ok && strings.TrimSpace(line) != "" {
+			if addErr := t.add(line); addErr != nil {
+				_, _ = fmt.Fprintf(t.status, "⚠️  Ignoring frame: %v\n", addErr)
+			}
+		}
+		return err
+	}
+}
+
+// Receive implements Channel. Frames of other channels or recipients are kept
+// for later, as an operator may scan a whole batch of codes at once.
+func (ch *qrChannel) Receive(ctx context.Context, party string) ([]string, error) {
+	t := ch.transport
+	key := ch.id + "/" + party
+	prompted := false
+	for {
+		t.mu.Lock()
+		bodies := t.pending[key]
+		delete(t.pending, key)
+		t.mu.Unlock()
+		if len(bodies) > 0 {
+			return bodies, nil
+		}
+
+		if !prompted {
+			_, _ = fmt.Fprintf(t.status, "📷 Scan the codes for %s (%s), one frame per line\n", party, ch.id)
+			prompted = true
+		}
+		line, err := t.next(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if err := t.add(line); err != nil {
+			// A misread frame is rescanned on the next loop of the animation
+			_, _ = fmt.Fprintf(t.status, "⚠️  Ignoring frame: %v\n", err)
+		}
+	}
+}
+
+// add feeds a scanned frame to the decoder and files completed messages
+func (t *QRTransport) add(frame string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	payload, err := t.decoder.Add(frame)
+	if err != nil || payload == nil {
+		return err
+	}
+
+	var envelope qrEnvelope
+	if err := json.Unmarshal(payload, &envelope); err != nil {
+		return fmt.Errorf("invalid message: %w", err)
+	}
+	body, err := t.cipher.decrypt(envelope.Body, route("", envelope.Channel, envelope.From, envelope.To))
+	if err != nil {
+		return fmt.Errorf("message from %s: %w", envelope.From, err)
+	}
+	key := envelope.Channel + "/" + envelope.To
+	t.pending[key] = append(t.pending[key], body)
+	return nil
+}
@@ -0,0 +1,243 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestFrames_ReassembleInAnyOrder(t *testing.T) {
+	payload := bytes.Repeat([]byte("vultool keysign round message "), 40)
+	frames, err := EncodeFrames(payload, 100)
+	if err != nil {
+		t.Fatalf("EncodeFrames failed: %v", err)
+	}
+	if len(frames) != 12 {
+		t.Fatalf("Expected 12 frames, got %d", len(frames))
+	}
+
+	// Shuffle and repeat frames the way a looping animation is scanned
+	scanned := append(append([]string(nil), frames...), frames[3], frames[7])
+	rand.New(rand.NewSource(1)).Shuffle(len(scanned), func(i, j int) { scanned[i], scanned[j] = scanned[j], scanned[i] })
+
+	decoder := NewFrameDecoder()
+	var result []byte
+	for _, frame := range scanned {
+		out, err := decoder.Add(frame)
+		if err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+		if out != nil {
+			if result != nil {
+				t.Fatal("Message completed twice")
+			}
+			result = out
+		}
+	}
+	if !bytes.Equal(result, payload) {
+		t.Fatal("Reassembled payload differs")
+	}
+	if len(decoder.Progress()) != 0 {
+		t.Errorf("Expected no unfinished messages, got %+v", decoder.Progress())
+	}
+}
+
+func TestFrames_Interleaved(t *testing.T) {
+	a, _ := EncodeFrames([]byte(strings.Repeat("a", 50)), 20)
+	b, _ := EncodeFrames([]byte(strings.Repeat("b", 50)), 20)
+
+	decoder := NewFrameDecoder()
+	var done []string
+	for i := range a {
+		for _, frame := range []string{a[i], b[i]} {
+			out, err := decoder.Add(frame)
+			if err != nil {
+				t.Fatalf("Add failed: %v", err)
+			}
+			if out != nil {
+				done = append(done, string(out[:1]))
+			}
+		}
+		if i == 0 {
+			if progress := decoder.Progress(); len(progress) != 2 || progress[0].Have != 1 || progress[0].Total != 3 {
+				t.Errorf("Unexpected progress %+v", progress)
+			}
+		}
+	}
+	if strings.Join(done, "") != "ab" {
+		t.Errorf("Expected both messages, got %v", done)
+	}
+}
+
+func TestFrames_RejectCorruption(t *testing.T) {
+	frames, _ := EncodeFrames([]byte("the quick brown fox jumps over the lazy dog"), 16)
+	decoder := NewFrameDecoder()
+
+	fields := strings.Split(frames[0], "/")
+	fields[5] = fields[5][:len(fields[5])-2] + "AA"
+	if _, err := decoder.Add(strings.Join(fields, "/")); err == nil || !strings.Contains(err.Error(), "checksum") {
+		t.Errorf("Expected a checksum error, got: %v", err)
+	}
+	if _, err := decoder.Add("https://vultisig.com/?type=SignTransaction"); err == nil {
+		t.Error("Expected a foreign QR code to be rejected")
+	}
+
+	// A chunk that passes its CRC but belongs to another payload fails the message check
+	other, _ := EncodeFrames([]byte("THE QUICK BROWN FOX JUMPS OVER THE LAZY DOG"), 16)
+	swapped := strings.Split(other[1], "/")
+	swapped[1] = strings.Split(frames[0], "/")[1]
+	for _, frame := range []string{frames[0], strings.Join(swapped, "/")} {
+		if _, err := decoder.Add(frame); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+	if _, err := decoder.Add(frames[2]); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Errorf("Expected the reassembled payload to fail its checksum, got: %v", err)
+	}
+}
+
+func TestRender(t *testing.T) {
+	frames, _ := EncodeFrames([]byte("render me"), 0)
+
+	ascii, err := RenderASCII(frames[0])
+	if err != nil || !strings.Contains(ascii, "█") {
+		t.Errorf("RenderASCII returned %q, %v", ascii, err)
+	}
+	png, err := RenderPNG(frames[0], 256)
+	if err != nil || !bytes.HasPrefix(png, []byte("\x89PNG")) {
+		t.Errorf("RenderPNG returned %d bytes, %v", len(png), err)
+	}
+}
+
+// TestQRTransport_Exchange - what one device shows, the other scans
+func TestQRTransport_Exchange(t *testing.T) {
+	bobScanner, toBob := io.Pipe()
+	var shown []string
+
+	alice, err := NewQRTransport(testKey, QROptions{
+		Show: func(_ context.Context, caption string, frames []string) error {
+			shown = append(shown, caption)
+			go func() {
+				// Scanned out of order, with one misread frame
+				_, _ = fmt.Fprintln(toBob, "VQR1/garbage")
+				for i := len(frames) - 1; i >= 0; i-- {
+					_, _ = fmt.Fprintln(toBob, frames[i])
+				}
+			}()
+			return nil
+		},
+		Scanned:   strings.NewReader(""),
+		ChunkSize: 64,
+	})
+	if err != nil {
+		t.Fatalf("NewQRTransport failed: %v", err)
+	}
+	var status bytes.Buffer
+	bob, err := NewQRTransport(testKey, QROptions{
+		Show:    func(context.Context, string, []string) error { return nil },
+		Scanned: bobScanner,
+		Status:  &status,
+	})
+	if err != nil {
+		t.Fatalf("NewQRTransport failed: %v", err)
+	}
+
+	committee, err := bob.Join(context.Background(), "bob", []string{"bob", "alice"})
+	if err != nil || strings.Join(committee, ",") != "alice,bob" {
+		t.Fatalf("Join returned %v, %v", committee, err)
+	}
+
+	if err := alice.Channel("keygen-eddsa").Send("alice", "bob", "early eddsa message"); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if err := alice.Channel("keygen-ecdsa").Send("alice", "bob", strings.Repeat("ecdsa round 1 ", 20)); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	ctx := testContext(t)
+	bodies, err := bob.Channel("keygen-ecdsa").Receive(ctx, "bob")
+	if err != nil || len(bodies) != 1 || !strings.HasPrefix(bodies[0], "ecdsa round 1") {
+		t.Fatalf("Receive returned %v, %v", bodies, err)
+	}
+	// The EdDSA message was scanned first and kept for its own channel
+	bodies, err = bob.Channel("keygen-eddsa").Receive(ctx, "bob")
+	if err != nil || len(bodies) != 1 || bodies[0] != "early eddsa message" {
+		t.Fatalf("Receive returned %v, %v", bodies, err)
+	}
+
+	if len(shown) != 2 || shown[0] != "alice → bob (keygen-eddsa)" {
+		t.Errorf("Unexpected captions %v", shown)
+	}
+	if !strings.Contains(status.String(), "Ignoring frame") {
+		t.Errorf("Expected the misread frame to be reported, got %q", status.String())
+	}
+}
+
+// TestQRTransport_StopAnimation - an animated code runs until the operator
+// presses Enter or starts scanning the other device's codes
+func TestQRTransport_StopAnimation(t *testing.T) {
+	var frames []string
+	alice, _ := NewQRTransport(testKey, QROptions{
+		Show:    func(_ context.Context, _ string, f []string) error { frames = f; return nil },
+		Scanned: strings.NewReader(""),
+	})
+	if err := alice.Channel("keysign").Send("alice", "bob", "reply"); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	scanner, input := io.Pipe()
+	bob, _ := NewQRTransport(testKey, QROptions{
+		Show: func(ctx context.Context, _ string, _ []string) error {
+			<-ctx.Done()
+			return nil
+		},
+		Scanned: scanner,
+	})
+	go func() { _, _ = fmt.Fprintln(input) }()
+	if err := bob.Channel("keysign").Send("bob", "alice", "first"); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	go func() { _, _ = fmt.Fprintln(input, strings.Join(frames, "\n")) }()
+	if err := bob.Channel("keysign").Send("bob", "alice", "second"); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	// The frame that stopped the animation was kept
+	bodies, err := bob.Channel("keysign").Receive(testContext(t), "bob")
+	if err != nil || len(bodies) != 1 || bodies[0] != "reply" {
+		t.Fatalf("Receive returned %v, %v", bodies, err)
+	}
+}
+
+func TestQRTransport_WrongKey(t *testing.T) {
+	var frames []string
+	alice, _ := NewQRTransport(testKey, QROptions{
+		Show:    func(_ context.Context, _ string, f []string) error { frames = f; return nil },
+		Scanned: strings.NewReader(""),
+	})
+	if err := alice.Channel("").Send("alice", "bob", "secret"); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	for _, frame := range frames {
+		if strings.Contains(frame, "secret") {
+			t.Fatal("Frames must not carry the plaintext body")
+		}
+	}
+
+	var status bytes.Buffer
+	bob, _ := NewQRTransport(otherKey, QROptions{
+		Show:    func(context.Context, string, []string) error { return nil },
+		Scanned: strings.NewReader(strings.Join(frames, "\n")),
+		Status:  &status,
+	})
+	if _, err := bob.Channel("").Receive(testContext(t), "bob"); err == nil || !strings.Contains(err.Error(), "closed") {
+		t.Errorf("Expected the input to run dry without a message, got: %v", err)
+	}
+	if !strings.Contains(status.String(), "decrypt") {
+		t.Errorf("Expected a decryption warning, got %q", status.String())
+	}
+}
@@ -0,0 +1,192 @@
+package transport
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// DefaultChunkSize is the payload bytes per QR frame; it keeps every frame
+// small enough for a terminal QR code that phone cameras scan reliably
+const DefaultChunkSize = 256
+
+// framePrefix marks vultool QR frames and versions their layout
+const framePrefix = "VQR1"
+
+// EncodeFrames splits a payload into multi-part QR frame texts
+// Each frame reads VQR1/<message id>/<index>/<total>/<crc32>/<base64url chunk>.
+// The message id is a SHA-256 prefix of the whole payload, so the decoder can
+// tell messages apart and check the reassembled payload; the CRC32 guards
+// each chunk against misreads.
+func EncodeFrames(payload []byte, chunkSize int) ([]string, error) {
+	if len(payload) == 0 {
+		return nil, fmt.Errorf("nothing to encode")
+	}
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+
+	id := messageID(payload)
+	total := (len(payload) + chunkSize - 1) / chunkSize
+	frames := make([]string, 0, total)
+	for i := 0; i < total; i++ {
+		chunk := payload[i*chunkSize : min((i+1)*chunkSize, len(payload))]
+		frames = append(frames, fmt.Sprintf("%s/%s/%d/%d/%08x/%s", framePrefix, id, i+1, total,
+			crc32.ChecksumIEEE(chunk), base64.RawURLEncoding.EncodeToString(chunk)))
+	}
+	return frames, nil
+}
+
+// FrameDecoder reassembles payloads from scanned frames
+// Frames may arrive in any order, repeatedly (animated codes loop) and
+// interleaved across messages.
+type FrameDecoder struct {
+	partial  map[string]*partialMessage
+	finished map[string]bool
+}
+
+type partialMessage struct {
+	total  int
+	chunks map[int][]byte
+}
+
+// FrameProgress reports how much of an unfinished message has been scanned
+type FrameProgress struct {
+	ID    string
+	Have  int
+	Total int
+}
+
+// NewFrameDecoder creates an empty decoder
+func NewFrameDecoder() *FrameDecoder {
+	return &FrameDecoder{
+		partial:  make(map[string]*partialMessage),
+		finished: make(map[string]bool),
+	}
+}
+
+// Add decodes one scanned frame and returns the payload once its message is
+// complete. Frames of messages already returned are ignored.
+func (d *FrameDecoder) Add(frame string) ([]byte, error) {
+	fields := strings.Split(strings.TrimSpace(frame), "/")
+	if len(fields) != 6 || fields[0] != framePrefix {
+		return nil, fmt.Errorf("not a vultool QR frame")
+	}
+	id := fields[1]
+	index, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid frame index %q", fields[2])
+	}
+	total, err := strconv.Atoi(fields[3])
+	if err != nil || total < 1 || index < 1 || index > total {
+		return nil, fmt.Errorf("invalid frame position %s/%s", fields[2], fields[3])
+	}
+	chunk, err := base64.RawURLEncoding.DecodeString(fields[5])
+	if err != nil {
+		return nil, fmt.Errorf("frame %d/%d: invalid data: %w", index, total, err)
+	}
+	if fmt.Sprintf("%08x", crc32.ChecksumIEEE(chunk)) != strings.ToLower(fields[4]) {
+		return nil, fmt.Errorf("frame %d/%d: checksum mismatch", index, total)
+	}
+
+	if d.finished[id] {
+		return nil, nil
+	}
+	msg, ok := d.partial[id]
+	if !ok {
+		msg = &partialMessage{total: total, chunks: make(map[int][]byte, total)}
+		d.partial[id] = msg
+	}
+	if msg.total != total {
+		return nil, fmt.Errorf("frame %d/%d: message %s has %d frames", index, total, id, msg.total)
+	}
+	msg.chunks[index] = chunk
+	if len(msg.chunks) < msg.total {
+		return nil, nil
+	}
+
+	var payload []byte
+	for i := 1; i <= msg.total; i++ {
+		payload = append(payload, msg.chunks[i]...)
+	}
+	delete(d.partial, id)
+	if messageID(payload) != id {
+		return nil, fmt.Errorf("message %s: reassembled payload does not match its checksum", id)
+	}
+	d.finished[id] = true
+	return payload, nil
+}
+
+// Progress lists the messages that are still missing frames
+func (d *FrameDecoder) Progress() []FrameProgress {
+	progress := make([]FrameProgress, 0, len(d.partial))
+	for id, msg := range d.partial {
+		progress = append(progress, FrameProgress{ID: id, Have: len(msg.chunks), Total: msg.total})
+	}
+	sort.Slice(progress, func(i, j int) bool { return progress[i].ID < progress[j].ID })
+	return progress
+}
+
+// RenderASCII draws a frame as a terminal QR code using Unicode half blocks
+func RenderASCII(frame string) (string, error) {
+	code, err := qrcode.New(frame, qrcode.Low)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode QR frame: %w", err)
+	}
+	return code.ToSmallString(false), nil
+}
+
+// RenderPNG draws a frame as a PNG image of size x size pixels
+func RenderPNG(frame string, size int) ([]byte, error) {
+	png, err := qrcode.Encode(frame, qrcode.Low, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode QR frame: %w", err)
+	}
+	return png, nil
+}
+
+// Animate loops the frames in place on a terminal until ctx is done, the way
+// the apps show animated multi-part codes
+func Animate(ctx context.Context, w io.Writer, frames []string, interval time.Duration, caption string) error {
+	if len(frames) == 0 {
+		return fmt.Errorf("no frames to show")
+	}
+	rendered := make([]string, len(frames))
+	for i, frame := range frames {
+		code, err := RenderASCII(frame)
+		if err != nil {
+			return err
+		}
+		rendered[i] = code
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for i := 0; ; i = (i + 1) % len(rendered) {
+		// Clear the screen and draw from the top left
+		if _, err := fmt.Fprintf(w, "\033[H\033[2J%s%s  frame %d/%d\n", rendered[i], caption, i+1, len(rendered)); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// messageID names a payload by the first 8 bytes of its SHA-256
+func messageID(payload []byte) string {
+	digest := sha256.Sum256(payload)
+	return hex.EncodeToString(digest[:8])
+}
@@ -0,0 +1,287 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5" // #nosec G501 - message hash used as an identifier, as the relay protocol defines it
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rowbotony/vultool/internal/relay"
+)
+
+// pollInterval is how often the relay is polled while waiting
+const pollInterval = 100 * time.Millisecond
+
+// RelayClient is an Interface backed by a Vultisig relay server
+// Message bodies are encrypted end to end under the shared hex encryption
+// key, so the relay only ever sees ciphertext.
+type RelayClient struct {
+	baseURL   string
+	sessionID string
+	cipher    *messageCipher
+	http      *http.Client
+
+	mu       sync.Mutex
+	sequence map[string]int64 // next sequence number per channel
+}
+
+// NewRelayClient connects to the relay at baseURL for one session
+func NewRelayClient(baseURL, sessionID, encryptionKeyHex string) (*RelayClient, error) {
+	if baseURL == "" {
+		return nil, fmt.Errorf("relay URL is required")
+	}
+	if sessionID == "" || strings.ContainsAny(sessionID, "/?#") {
+		return nil, fmt.Errorf("invalid session ID %q", sessionID)
+	}
+
+	messages, err := newMessageCipher(encryptionKeyHex)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RelayClient{
+		baseURL:   strings.TrimSuffix(baseURL, "/"),
+		sessionID: sessionID,
+		cipher:    messages,
+		http:      &http.Client{Timeout: 30 * time.Second},
+		sequence:  make(map[string]int64),
+	}, nil
+}
+
+// Join implements Interface. The first party in sorted order starts the
+// session once everyone registered, so no device needs to be told it leads.
+func (c *RelayClient) Join(ctx context.Context, party string, parties []string) ([]string, error) {
+	expected := append([]string(nil), parties...)
+	sort.Strings(expected)
+	if !containsString(expected, party) {
+		return nil, fmt.Errorf("party %s is not in the committee %v", party, parties)
+	}
+
+	if err := c.post(ctx, "/"+c.sessionID, "", []string{party}, http.StatusCreated); err != nil {
+		return nil, fmt.Errorf("failed to register with relay: %w", err)
+	}
+
+	if party == expected[0] {
+		if _, err := c.waitForParties(ctx, "/"+c.sessionID, expected); err != nil {
+			return nil, err
+		}
+		if err := c.post(ctx, "/start/"+c.sessionID, "", expected, http.StatusOK); err != nil {
+			return nil, fmt.Errorf("failed to start session: %w", err)
+		}
+	}
+
+	started, err := c.waitForParties(ctx, "/start/"+c.sessionID, expected)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(started)
+	if strings.Join(started, ",") != strings.Join(expected, ",") {
+		return nil, fmt.Errorf("session started with %v, expected %v", started, expected)
+	}
+	return started, nil
+}
+
+// Channel implements Interface
+func (c *RelayClient) Channel(id string) Channel {
+	return &relayChannel{client: c, id: id}
+}
+
+// Complete implements Interface
+func (c *RelayClient) Complete(ctx context.Context, party string) error {
+	return c.post(ctx, "/complete/"+c.sessionID, "", []string{party}, http.StatusOK)
+}
+
+// waitForParties polls a party list endpoint until it contains every expected party
+func (c *RelayClient) waitForParties(ctx context.Context, path string, expected []string) ([]string, error) {
+	for {
+		var parties []string
+		found, err := c.get(ctx, path, "", &parties)
+		if err != nil {
+			return nil, err
+		}
+		if found && containsAll(parties, expected) {
+			return parties, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("waiting for %v: %w", expected, ctx.Err())
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// relayChannel is one message_id queue of the session
+type relayChannel struct {
+	client *RelayClient
+	id     string
+}
+
+// Send implements Channel
+func (ch *relayChannel) Send(from, to, body string) error {
+	c := ch.client
+	encrypted, err := c.cipher.encrypt(body, route(c.sessionID, ch.id, from, to))
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.sequence[ch.id]++
+	sequenceNo := c.sequence[ch.id]
+	c.mu.Unlock()
+
+	digest := md5.Sum([]byte(encrypted)) // #nosec G401 - identifier, not a security boundary
+	msg := relay.Message{
+		SessionID:  c.sessionID,
+		From:       from,
+		To:         []string{to},
+		Body:       encrypted,
+		Hash:       hex.EncodeToString(digest[:]),
+		SequenceNo: sequenceNo,
+	}
+	return c.post(context.Background(), "/message/"+c.sessionID, ch.id, msg, http.StatusAccepted)
+}
+
+// Receive implements Channel
+func (ch *relayChannel) Receive(ctx context.Context, party string) ([]string, error) {
+	c := ch.client
+	path := "/message/" + c.sessionID + "/" + party
+	for {
+		var messages []relay.Message
+		if _, err := c.get(ctx, path, ch.id, &messages); err != nil {
+			return nil, err
+		}
+
+		if len(messages) > 0 {
+			sort.SliceStable(messages, func(i, j int) bool {
+				if messages[i].From != messages[j].From {
+					return messages[i].From < messages[j].From
+				}
+				return messages[i].SequenceNo < messages[j].SequenceNo
+			})
+
+			bodies := make([]string, 0, len(messages))
+			for _, msg := range messages {
+				if err := c.delete(ctx, path+"/"+msg.Hash, ch.id); err != nil {
+					return nil, err
+				}
+				// Anyone who can post to the session can queue a message, so
+				// one that does not open is dropped rather than failing the
+				// ceremony
+				body, err := c.cipher.decrypt(msg.Body, route(c.sessionID, ch.id, msg.From, party))
+				if err != nil {
+					log.Printf("⚠️ Dropped relay message %s from %s: %v", msg.Hash, msg.From, err)
+					continue
+				}
+				bodies = append(bodies, body)
+			}
+			if len(bodies) > 0 {
+				return bodies, nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+func (c *RelayClient) post(ctx context.Context, path, messageID string, payload interface{}, wantStatus int) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+	resp, err := c.do(ctx, http.MethodPost, path, messageID, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != wantStatus {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("relay returned %s for POST %s: %s", resp.Status, path, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// get decodes a JSON response into v; a 404 is reported as not found rather than an error
+func (c *RelayClient) get(ctx context.Context, path, messageID string, v interface{}) (bool, error) {
+	resp, err := c.do(ctx, http.MethodGet, path, messageID, nil)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			return false, fmt.Errorf("invalid relay response for %s: %w", path, err)
+		}
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("relay returned %s for GET %s", resp.Status, path)
+	}
+}
+
+func (c *RelayClient) delete(ctx context.Context, path, messageID string) error {
+	resp, err := c.do(ctx, http.MethodDelete, path, messageID, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("relay returned %s for DELETE %s", resp.Status, path)
+	}
+	return nil
+}
+
+func (c *RelayClient) do(ctx context.Context, method, path, messageID string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if messageID != "" {
+		req.Header.Set("message_id", messageID)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("relay request failed: %w", err)
+	}
+	return resp, nil
+}
+
+func containsString(list []string, item string) bool {
+	for _, s := range list {
+		if s == item {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAll(list, items []string) bool {
+	for _, item := range items {
+		if !containsString(list, item) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,237 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rowbotony/vultool/internal/relay"
+)
+
+const (
+	testKey  = "2b7e151628aed2a6abf7158809cf4f3c762e7160f38b4da56a784d9045190cfe"
+	otherKey = "603deb1015ca71be2b73aef0857d77811f352c073b6108d72d9810a30914dff4"
+)
+
+func newTestRelay(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(relay.NewServer(0).Handler())
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestClient(t *testing.T, url, key string) *RelayClient {
+	t.Helper()
+	c, err := NewRelayClient(url, "session-1", key)
+	if err != nil {
+		t.Fatalf("NewRelayClient failed: %v", err)
+	}
+	return c
+}
+
+func testContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+func TestRelayClient_JoinAndExchange(t *testing.T) {
+	server := newTestRelay(t)
+	ctx := testContext(t)
+
+	committees := make(map[string][]string)
+	received := make(map[string][]string)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, party := range []string{"bob", "alice"} {
+		wg.Add(1)
+		go func(party string) {
+			defer wg.Done()
+			c := newTestClient(t, server.URL, testKey)
+			committee, err := c.Join(ctx, party, []string{"bob", "alice"})
+			if err != nil {
+				t.Errorf("%s: Join failed: %v", party, err)
+				return
+			}
+
+			peer := map[string]string{"alice": "bob", "bob": "alice"}[party]
+			ch := c.Channel("keygen-ecdsa")
+			for _, body := range []string{"round1 from " + party, "round2 from " + party} {
+				if err := ch.Send(party, peer, body); err != nil {
+					t.Errorf("%s: Send failed: %v", party, err)
+					return
+				}
+			}
+
+			var bodies []string
+			for len(bodies) < 2 {
+				batch, err := ch.Receive(ctx, party)
+				if err != nil {
+					t.Errorf("%s: Receive failed: %v", party, err)
+					return
+				}
+				bodies = append(bodies, batch...)
+			}
+			if err := c.Complete(ctx, party); err != nil {
+				t.Errorf("%s: Complete failed: %v", party, err)
+			}
+
+			mu.Lock()
+			committees[party] = committee
+			received[party] = bodies
+			mu.Unlock()
+		}(party)
+	}
+	wg.Wait()
+
+	for _, party := range []string{"alice", "bob"} {
+		if strings.Join(committees[party], ",") != "alice,bob" {
+			t.Errorf("%s: unexpected committee %v", party, committees[party])
+		}
+	}
+	if strings.Join(received["alice"], "|") != "round1 from bob|round2 from bob" {
+		t.Errorf("alice received %v", received["alice"])
+	}
+	if strings.Join(received["bob"], "|") != "round1 from alice|round2 from alice" {
+		t.Errorf("bob received %v", received["bob"])
+	}
+}
+
+// TestRelayClient_EncryptsBodies - the relay only ever sees ciphertext
+func TestRelayClient_EncryptsBodies(t *testing.T) {
+	server := newTestRelay(t)
+	ctx := testContext(t)
+	c := newTestClient(t, server.URL, testKey)
+
+	if err := c.Channel("").Send("alice", "bob", "secret round message"); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	resp, err := http.Get(server.URL + "/message/session-1/bob")
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	defer resp.Body.Close()
+	var messages []relay.Message
+	if err := json.NewDecoder(resp.Body).Decode(&messages); err != nil {
+		t.Fatalf("Invalid relay response: %v", err)
+	}
+	if len(messages) != 1 || strings.Contains(messages[0].Body, "secret") || messages[0].Hash == "" || messages[0].SequenceNo != 1 {
+		t.Fatalf("Unexpected relayed message: %+v", messages)
+	}
+
+	bodies, err := c.Channel("").Receive(ctx, "bob")
+	if err != nil || len(bodies) != 1 || bodies[0] != "secret round message" {
+		t.Fatalf("Receive returned %v, %v", bodies, err)
+	}
+}
+
+// TestRelayClient_WrongKey - a message that does not decrypt is dropped and
+// the ceremony keeps receiving, so a stray poster cannot stop it
+func TestRelayClient_WrongKey(t *testing.T) {
+	server := newTestRelay(t)
+	ctx := testContext(t)
+
+	stranger := newTestClient(t, server.URL, otherKey)
+	if err := stranger.Channel("").Send("alice", "bob", "forged"); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	sender := newTestClient(t, server.URL, testKey)
+	if err := sender.Channel("").Send("alice", "bob", "hello"); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	receiver := newTestClient(t, server.URL, testKey)
+	bodies, err := receiver.Channel("").Receive(ctx, "bob")
+	if err != nil || strings.Join(bodies, "|") != "hello" {
+		t.Fatalf("Receive returned %v, %v; want only the genuine message", bodies, err)
+	}
+	if queued := relayedMessages(t, server.URL+"/message/session-1/bob"); len(queued) != 0 {
+		t.Errorf("Dropped message left on the relay: %+v", queued)
+	}
+}
+
+// TestRelayClient_ReplayedMessage - a body is sealed to its route, so it does
+// not open when reposted to another recipient
+func TestRelayClient_ReplayedMessage(t *testing.T) {
+	server := newTestRelay(t)
+	c := newTestClient(t, server.URL, testKey)
+	if err := c.Channel("").Send("alice", "bob", "for bob only"); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	msg := relayedMessages(t, server.URL+"/message/session-1/bob")[0]
+	msg.To = []string{"carol"}
+	data, _ := json.Marshal(msg)
+	resp, err := http.Post(server.URL+"/message/session-1", "application/json", strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatalf("POST failed: %v", err)
+	}
+	resp.Body.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	if bodies, err := c.Channel("").Receive(ctx, "carol"); err == nil {
+		t.Errorf("Replayed message was accepted: %v", bodies)
+	}
+}
+
+// relayedMessages lists the messages queued on the relay at url
+func relayedMessages(t *testing.T, url string) []relay.Message {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	defer resp.Body.Close()
+	var messages []relay.Message
+	if err := json.NewDecoder(resp.Body).Decode(&messages); err != nil {
+		t.Fatalf("Invalid relay response: %v", err)
+	}
+	return messages
+}
+
+func TestRelayClient_ChannelsAreIsolated(t *testing.T) {
+	server := newTestRelay(t)
+	c := newTestClient(t, server.URL, testKey)
+
+	if err := c.Channel("keygen-eddsa").Send("alice", "bob", "eddsa"); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	if bodies, err := c.Channel("keygen-ecdsa").Receive(ctx, "bob"); err == nil {
+		t.Errorf("Expected no ECDSA messages, got %v", bodies)
+	}
+
+	bodies, err := c.Channel("keygen-eddsa").Receive(testContext(t), "bob")
+	if err != nil || len(bodies) != 1 || bodies[0] != "eddsa" {
+		t.Errorf("Receive returned %v, %v", bodies, err)
+	}
+}
+
+func TestNewRelayClient_Validation(t *testing.T) {
+	if _, err := NewRelayClient("http://localhost:18080", "s1", "abcd"); err == nil {
+		t.Error("Expected a short encryption key to be rejected")
+	}
+	if _, err := NewRelayClient("http://localhost:18080", "s1", strings.Repeat("zz", 32)); err == nil {
+		t.Error("Expected a non-hex encryption key to be rejected")
+	}
+	if _, err := NewRelayClient("http://localhost:18080", "a/b", testKey); err == nil {
+		t.Error("Expected a session ID with a slash to be rejected")
+	}
+	if _, err := NewRelayClient("", "s1", testKey); err == nil {
+		t.Error("Expected a missing relay URL to be rejected")
+	}
+
+	c := newTestClient(t, "http://localhost:18080", testKey)
+	if _, err := c.Join(context.Background(), "mallory", []string{"alice", "bob"}); err == nil {
+		t.Error("Expected a party outside the committee to be rejected")
+	}
+}
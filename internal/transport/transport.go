@@ -0,0 +1,32 @@
+// Package transport moves ceremony messages between vultool processes that
+// each hold one party of a vault. A transport only carries opaque payloads;
+// the ceremony code decides what is sent to whom.
+package transport
+
+import (
+	"context"
+)
+
+// Interface is a session shared by the devices taking part in one ceremony
+type Interface interface {
+	// Join registers party with the session and waits until every one of
+	// parties has joined and the session started. It returns the committee.
+	Join(ctx context.Context, party string, parties []string) ([]string, error)
+
+	// Channel returns the message stream for one protocol run of the session.
+	// Runs are isolated by id, so e.g. ECDSA and EdDSA keygen messages never mix.
+	Channel(id string) Channel
+
+	// Complete tells the other devices that party has finished
+	Complete(ctx context.Context, party string) error
+}
+
+// Channel carries the payloads of a single protocol run
+// Send has the signature of mobile-tss-lib's tss.Messenger.
+type Channel interface {
+	Send(from, to, body string) error
+
+	// Receive blocks until at least one payload for party is available and
+	// returns the pending payloads in the order their senders sent them
+	Receive(ctx context.Context, party string) ([]string, error)
+}
@@ -7,23 +7,33 @@ type SupportedChain string
 
 const (
 	// ECDSA-based chains
-	ChainBitcoin     SupportedChain = "bitcoin"
-	ChainBitcoinCash SupportedChain = "bitcoincash"
-	ChainLitecoin    SupportedChain = "litecoin"
-	ChainDogecoin    SupportedChain = "dogecoin"
-	ChainDash        SupportedChain = "dash"
-	ChainZcash       SupportedChain = "zcash"
-	ChainEthereum    SupportedChain = "ethereum"
-	ChainBSC         SupportedChain = "bsc"
-	ChainAvalanche   SupportedChain = "avalanche"
-	ChainPolygon     SupportedChain = "polygon"
-	ChainCronosChain SupportedChain = "cronoschain"
-	ChainArbitrum    SupportedChain = "arbitrum"
-	ChainOptimism    SupportedChain = "optimism"
-	ChainBase        SupportedChain = "base"
-	ChainBlast       SupportedChain = "blast"
-	ChainZksync      SupportedChain = "zksync"
-	ChainThorChain   SupportedChain = "thorchain"
+	ChainBitcoin        SupportedChain = "bitcoin"
+	ChainBitcoinTaproot SupportedChain = "bitcoin-taproot"
+	ChainBitcoinCash    SupportedChain = "bitcoincash"
+	ChainLitecoin       SupportedChain = "litecoin"
+	ChainDogecoin       SupportedChain = "dogecoin"
+	ChainDash           SupportedChain = "dash"
+	ChainZcash          SupportedChain = "zcash"
+	ChainEthereum       SupportedChain = "ethereum"
+	ChainBSC            SupportedChain = "bsc"
+	ChainAvalanche      SupportedChain = "avalanche"
+	ChainPolygon        SupportedChain = "polygon"
+	ChainCronosChain    SupportedChain = "cronoschain"
+	ChainArbitrum       SupportedChain = "arbitrum"
+	ChainOptimism       SupportedChain = "optimism"
+	ChainBase           SupportedChain = "base"
+	ChainBlast          SupportedChain = "blast"
+	ChainZksync         SupportedChain = "zksync"
+	ChainThorChain      SupportedChain = "thorchain"
+	ChainMayaChain      SupportedChain = "mayachain"
+	ChainCosmos         SupportedChain = "cosmos"
+	ChainOsmosis        SupportedChain = "osmosis"
+	ChainKujira         SupportedChain = "kujira"
+	ChainDydx           SupportedChain = "dydx"
+	ChainNoble          SupportedChain = "noble"
+	ChainTerra          SupportedChain = "terra"
+	ChainTron           SupportedChain = "tron"
+	ChainXRP            SupportedChain = "xrp"
 	// EdDSA-based chains
 	ChainSolana SupportedChain = "solana"
 	ChainSUI    SupportedChain = "sui"
@@ -48,6 +58,12 @@ func GetCommonDerivationPaths() map[SupportedChain][]DerivationPath {
 			{Path: "m/84'/0'/0'/0/0", Chain: ChainBitcoin, Description: "P2WPKH (Native SegWit)", Purpose: "native_segwit"},
 			{Path: "m/84'/0'/0'/0/1", Chain: ChainBitcoin, Description: "Second Native SegWit address", Purpose: "receiving"},
 		},
+		// Bitcoin Taproot (BIP86 key-path-only P2TR)
+		ChainBitcoinTaproot: {
+			{Path: "m/86'/0'/0'/0/0", Chain: ChainBitcoinTaproot, Description: "First Taproot address (P2TR)", Purpose: "taproot"},
+			{Path: "m/86'/0'/0'/0/1", Chain: ChainBitcoinTaproot, Description: "Second Taproot address (P2TR)", Purpose: "receiving"},
+			{Path: "m/86'/0'/0'/1/0", Chain: ChainBitcoinTaproot, Description: "First Taproot change address (P2TR)", Purpose: "change"},
+		},
 		// Bitcoin Cash
 		ChainBitcoinCash: {
 			{Path: "m/44'/145'/0'/0/0", Chain: ChainBitcoinCash, Description: "Bitcoin Cash main address", Purpose: "receiving"},
@@ -139,6 +155,45 @@ func GetCommonDerivationPaths() map[SupportedChain][]DerivationPath {
 			{Path: "m/44'/931'/0'/0/0", Chain: ChainThorChain, Description: "THORChain main address", Purpose: "receiving"},
 			{Path: "m/44'/931'/0'/0/1", Chain: ChainThorChain, Description: "THORChain second address", Purpose: "receiving"},
 		},
+		// Cosmos SDK chains
+		ChainMayaChain: {
+			{Path: "m/44'/931'/0'/0/0", Chain: ChainMayaChain, Description: "MayaChain main address", Purpose: "receiving"},
+			{Path: "m/44'/931'/0'/0/1", Chain: ChainMayaChain, Description: "MayaChain second address", Purpose: "receiving"},
+		},
+		ChainCosmos: {
+			{Path: "m/44'/118'/0'/0/0", Chain: ChainCosmos, Description: "Cosmos Hub main address", Purpose: "receiving"},
+			{Path: "m/44'/118'/0'/0/1", Chain: ChainCosmos, Description: "Cosmos Hub second address", Purpose: "receiving"},
+		},
+		ChainOsmosis: {
+			{Path: "m/44'/118'/0'/0/0", Chain: ChainOsmosis, Description: "Osmosis main address", Purpose: "receiving"},
+			{Path: "m/44'/118'/0'/0/1", Chain: ChainOsmosis, Description: "Osmosis second address", Purpose: "receiving"},
+		},
+		ChainKujira: {
+			{Path: "m/44'/118'/0'/0/0", Chain: ChainKujira, Description: "Kujira main address", Purpose: "receiving"},
+			{Path: "m/44'/118'/0'/0/1", Chain: ChainKujira, Description: "Kujira second address", Purpose: "receiving"},
+		},
+		ChainDydx: {
+			{Path: "m/44'/118'/0'/0/0", Chain: ChainDydx, Description: "dYdX main address", Purpose: "receiving"},
+			{Path: "m/44'/118'/0'/0/1", Chain: ChainDydx, Description: "dYdX second address", Purpose: "receiving"},
+		},
+		ChainNoble: {
+			{Path: "m/44'/118'/0'/0/0", Chain: ChainNoble, Description: "Noble main address", Purpose: "receiving"},
+			{Path: "m/44'/118'/0'/0/1", Chain: ChainNoble, Description: "Noble second address", Purpose: "receiving"},
+		},
+		ChainTerra: {
+			{Path: "m/44'/330'/0'/0/0", Chain: ChainTerra, Description: "Terra main address", Purpose: "receiving"},
+			{Path: "m/44'/330'/0'/0/1", Chain: ChainTerra, Description: "Terra second address", Purpose: "receiving"},
+		},
+		// TRON
+		ChainTron: {
+			{Path: "m/44'/195'/0'/0/0", Chain: ChainTron, Description: "TRON main address", Purpose: "receiving"},
+			{Path: "m/44'/195'/0'/0/1", Chain: ChainTron, Description: "TRON second address", Purpose: "receiving"},
+		},
+		// XRP Ledger
+		ChainXRP: {
+			{Path: "m/44'/144'/0'/0/0", Chain: ChainXRP, Description: "XRP main account", Purpose: "receiving"},
+			{Path: "m/44'/144'/0'/0/1", Chain: ChainXRP, Description: "XRP second account", Purpose: "receiving"},
+		},
 		// Solana
 		ChainSolana: {
 			{Path: "m/44'/501'/0'/0'", Chain: ChainSolana, Description: "Solana main account", Purpose: "receiving"},
@@ -182,6 +237,8 @@ func getBasePath(chain SupportedChain) string {
 	// ECDSA-based chains
 	case ChainBitcoin:
 		return "m/84'/0'/0'/0/" // Native SegWit is most common modern standard
+	case ChainBitcoinTaproot:
+		return "m/86'/0'/0'/0/" // BIP86
 	case ChainBitcoinCash:
 		return "m/44'/145'/0'/0/"
 	case ChainLitecoin:
@@ -194,8 +251,16 @@ func getBasePath(chain SupportedChain) string {
 		return "m/44'/133'/0'/0/"
 	case ChainEthereum, ChainBSC, ChainAvalanche, ChainPolygon, ChainCronosChain, ChainArbitrum, ChainOptimism, ChainBase, ChainBlast, ChainZksync:
 		return "m/44'/60'/0'/0/" // All EVM chains use same path
-	case ChainThorChain:
+	case ChainThorChain, ChainMayaChain:
 		return "m/44'/931'/0'/0/"
+	case ChainCosmos, ChainOsmosis, ChainKujira, ChainDydx, ChainNoble:
+		return "m/44'/118'/0'/0/"
+	case ChainTerra:
+		return "m/44'/330'/0'/0/"
+	case ChainTron:
+		return "m/44'/195'/0'/0/"
+	case ChainXRP:
+		return "m/44'/144'/0'/0/"
 	// EdDSA-based chains
 	case ChainSolana:
 		return "m/44'/501'/" // Solana uses different format: m/44'/501'/0'/0', m/44'/501'/1'/0', etc.
@@ -0,0 +1,132 @@
+package utxo
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/txscript"
+
+	"github.com/rowbotony/vultool/internal/vault"
+)
+
+// AnalyzedInput is a PSBT input and whether the vault spends it
+type AnalyzedInput struct {
+	Index      int    `json:"index"`
+	Outpoint   string `json:"outpoint"`
+	Address    string `json:"address,omitempty"`
+	Amount     int64  `json:"amount"`
+	Unknown    bool   `json:"unknown,omitempty"` // the PSBT does not carry the spent output
+	Vault      bool   `json:"vault"`
+	ScriptType string `json:"script_type,omitempty"`
+	DerivePath string `json:"derive_path,omitempty"`
+	Signed     bool   `json:"signed"` // carries partial or final signatures
+}
+
+// AnalyzedOutput is a PSBT output and whether it pays the vault
+type AnalyzedOutput struct {
+	Index      int    `json:"index"`
+	Address    string `json:"address"`
+	Amount     int64  `json:"amount"`
+	Vault      bool   `json:"vault"`
+	Change     bool   `json:"change"` // pays the vault back in a transaction the vault funds
+	ScriptType string `json:"script_type,omitempty"`
+	DerivePath string `json:"derive_path,omitempty"`
+}
+
+// Analysis is what a PSBT does to the vault's funds
+type Analysis struct {
+	Chain            string           `json:"chain"`
+	Vault            string           `json:"vault"`
+	TxID             string           `json:"txid"`
+	Inputs           []AnalyzedInput  `json:"inputs"`
+	Outputs          []AnalyzedOutput `json:"outputs"`
+	InputTotal       int64            `json:"input_total"`
+	OutputTotal      int64            `json:"output_total"`
+	Fee              int64            `json:"fee"`
+	FeeKnown         bool             `json:"fee_known"`
+	VaultInputTotal  int64            `json:"vault_input_total"`
+	VaultOutputTotal int64            `json:"vault_output_total"`
+	NetAmount        int64            `json:"net_amount"` // leaves the vault: vault inputs minus vault outputs
+	ExternalTotal    int64            `json:"external_total"`
+	Warnings         []string         `json:"warnings,omitempty"`
+}
+
+// AnalyzePSBT reports which inputs and outputs of a PSBT belong to the
+// vault, what leaves the vault and the fee, without signing anything.
+// Vault keys are derived from the vault's ECDSA public key and chain code the
+// same way vault.DeriveAddressesFromVault derives its addresses, at the
+// paths the PSBT records and at the chain's common receive and change paths.
+func AnalyzePSBT(p *psbt.Packet, chain *Chain, info *vault.VaultInfo) (*Analysis, error) {
+	keys, err := newVaultKeys(info)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &Analysis{Chain: chain.Name, Vault: info.Name, TxID: p.UnsignedTx.TxHash().String(), FeeKnown: true}
+	for i, txIn := range p.UnsignedTx.TxIn {
+		in := AnalyzedInput{
+			Index:    i,
+			Outpoint: txIn.PreviousOutPoint.String(),
+			Signed:   len(p.Inputs[i].PartialSigs) > 0 || len(p.Inputs[i].FinalScriptSig) > 0 || len(p.Inputs[i].FinalScriptWitness) > 0,
+		}
+		prevOut, err := prevOutput(p, i)
+		if err != nil {
+			return nil, err
+		}
+		if prevOut == nil {
+			in.Unknown = true
+			a.FeeKnown = false
+			a.Warnings = append(a.Warnings, fmt.Sprintf("input %d does not carry the output it spends; its amount and owner are unknown", i))
+			a.Inputs = append(a.Inputs, in)
+			continue
+		}
+
+		in.Address = scriptAddress(prevOut.PkScript, chain)
+		in.Amount = prevOut.Value
+		a.InputTotal += prevOut.Value
+		key, scriptType, err := matchScript(prevOut.PkScript, p.Inputs[i].Bip32Derivation, keys, chain)
+		if err != nil {
+			return nil, err
+		}
+		if key != nil {
+			in.Vault, in.ScriptType, in.DerivePath = true, scriptType, formatPath(key.path)
+			a.VaultInputTotal += prevOut.Value
+			if hashType := p.Inputs[i].SighashType; hashType != 0 && hashType != txscript.SigHashAll {
+				a.Warnings = append(a.Warnings, fmt.Sprintf("vault input %d asks for sighash type %#x; the transaction can still be changed after it is signed", i, uint32(hashType)))
+			}
+		}
+		a.Inputs = append(a.Inputs, in)
+	}
+
+	for i, txOut := range p.UnsignedTx.TxOut {
+		out := AnalyzedOutput{Index: i, Address: scriptAddress(txOut.PkScript, chain), Amount: txOut.Value}
+		a.OutputTotal += txOut.Value
+		key, scriptType, err := matchScript(txOut.PkScript, p.Outputs[i].Bip32Derivation, keys, chain)
+		if err != nil {
+			return nil, err
+		}
+		if key != nil {
+			out.Vault, out.ScriptType, out.DerivePath = true, scriptType, formatPath(key.path)
+			out.Change = a.VaultInputTotal > 0
+			a.VaultOutputTotal += txOut.Value
+		} else {
+			a.ExternalTotal += txOut.Value
+		}
+		a.Outputs = append(a.Outputs, out)
+	}
+
+	a.NetAmount = a.VaultInputTotal - a.VaultOutputTotal
+	if a.FeeKnown {
+		a.Fee = a.InputTotal - a.OutputTotal
+		if a.Fee < 0 {
+			return nil, fmt.Errorf("PSBT spends %d but pays out %d", a.InputTotal, a.OutputTotal)
+		}
+	}
+	if a.VaultInputTotal == 0 && a.VaultOutputTotal == 0 {
+		a.Warnings = append(a.Warnings, fmt.Sprintf("no input or output belongs to vault %s on %s", info.Name, chain.Name))
+	}
+	if a.VaultInputTotal > 0 && a.VaultInputTotal < a.InputTotal {
+		a.Warnings = append(a.Warnings, "other wallets fund some inputs; the fee is shared with them")
+	}
+	return a, nil
+}
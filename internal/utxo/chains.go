@@ -0,0 +1,60 @@
+// Package utxo signs and analyses PSBTs for the vault's UTXO chains
+package utxo
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/chaincfg"
+
+	"github.com/rowbotony/vultool/internal/network"
+)
+
+// Chain is a UTXO chain vultool handles PSBTs for
+type Chain struct {
+	Name     string // as vault.DeriveAddressesFromVault names it
+	Ticker   string
+	CoinType uint32
+	Purposes []uint32 // BIP44 purposes the chain's wallets derive with
+	Params   *chaincfg.Params
+	// XpubVersions are the SLIP-132 extended public key version bytes of
+	// each purpose
+	XpubVersions map[uint32][4]byte
+}
+
+// testXpubVersions are the tpub, upub and vpub versions every chain's
+// extended keys use on the test networks
+var testXpubVersions = map[uint32][4]byte{44: {0x04, 0x35, 0x87, 0xcf}, 49: {0x04, 0x4a, 0x52, 0x62}, 84: {0x04, 0x5f, 0x1c, 0xf6}}
+
+// networkChains returns the chains with the parameters of the selected
+// network
+func networkChains() []*Chain {
+	chains := []*Chain{
+		{Name: "Bitcoin", Ticker: "BTC", CoinType: 0, Purposes: []uint32{84, 49, 44}, Params: network.BitcoinParams(),
+			// xpub, ypub, zpub
+			XpubVersions: map[uint32][4]byte{44: {0x04, 0x88, 0xb2, 0x1e}, 49: {0x04, 0x9d, 0x7c, 0xb2}, 84: {0x04, 0xb2, 0x47, 0x46}}},
+		{Name: "Litecoin", Ticker: "LTC", CoinType: 2, Purposes: []uint32{84, 49, 44}, Params: network.LitecoinParams(),
+			// Ltub, Mtub, and zpub as Litecoin wallets use for native SegWit, which SLIP-132 leaves out
+			XpubVersions: map[uint32][4]byte{44: {0x01, 0x9d, 0xa4, 0x62}, 49: {0x01, 0xb2, 0x6e, 0xf6}, 84: {0x04, 0xb2, 0x47, 0x46}}},
+		// Dogecoin has no SegWit
+		{Name: "Dogecoin", Ticker: "DOGE", CoinType: 3, Purposes: []uint32{44}, Params: network.DogecoinParams(),
+			// dgub
+			XpubVersions: map[uint32][4]byte{44: {0x02, 0xfa, 0xca, 0xfd}}},
+	}
+	if !network.IsMainnet() {
+		for _, chain := range chains {
+			chain.XpubVersions = testXpubVersions
+		}
+	}
+	return chains
+}
+
+// LookupChain finds a chain by name or ticker, case-insensitively
+func LookupChain(name string) (*Chain, error) {
+	for _, chain := range networkChains() {
+		if strings.EqualFold(name, chain.Name) || strings.EqualFold(name, chain.Ticker) {
+			return chain, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported chain %q (use btc, ltc or doge)", name)
+}
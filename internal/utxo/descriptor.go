@@ -0,0 +1,166 @@
+package utxo
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/txscript"
+
+	"github.com/rowbotony/vultool/internal/vault"
+)
+
+// ScriptP2TR is a BIP86 key-path-only Taproot output
+const ScriptP2TR = "p2tr"
+
+// descriptorTypes are the single-key descriptors exported for SegWit
+// chains, by BIP purpose
+var descriptorTypes = []struct {
+	purpose    uint32
+	scriptType string
+	function   string // wraps the key expression, %s
+}{
+	{84, ScriptP2WPKH, "wpkh(%s)"},
+	{49, ScriptP2SHP2WPKH, "sh(wpkh(%s))"},
+	{44, ScriptP2PKH, "pkh(%s)"},
+	{86, ScriptP2TR, "tr(%s)"},
+}
+
+// Descriptor is an output descriptor of the receive or change branch of a
+// vault account
+type Descriptor struct {
+	Chain        string `json:"chain"`
+	ScriptType   string `json:"script_type"`
+	Internal     bool   `json:"internal"` // the change branch
+	Descriptor   string `json:"descriptor"`
+	FirstAddress string `json:"first_address"`
+}
+
+// AccountDescriptors returns the receive and change descriptors of account
+// for each address type of the chain, with checksums. The key origin is the
+// path the vault really derives along, non-hardened from its root, so
+// wallets that check origins against a signer see the vault's own keys.
+// Descriptors use the plain xpub (tpub on the test networks) encoding, the
+// only one Bitcoin Core and Litecoin Core accept.
+func AccountDescriptors(info *vault.VaultInfo, chain *Chain, account uint32) ([]Descriptor, error) {
+	if chain.Params.Bech32HRPSegwit == "" {
+		return nil, fmt.Errorf("descriptors are exported for Bitcoin and Litecoin, not %s", chain.Name)
+	}
+	if account >= hdkeychain.HardenedKeyStart {
+		return nil, fmt.Errorf("account %d is out of range", account)
+	}
+	keys, err := newVaultKeys(info)
+	if err != nil {
+		return nil, err
+	}
+	rootKey, err := keys.root.ECPubKey()
+	if err != nil {
+		return nil, err
+	}
+	master := hex.EncodeToString(btcutil.Hash160(rootKey.SerializeCompressed())[:4])
+
+	var descriptors []Descriptor
+	for _, t := range descriptorTypes {
+		path := []uint32{t.purpose, chain.CoinType, account}
+		key, err := keys.extendedKey(path)
+		if err != nil {
+			return nil, err
+		}
+		xpub, err := key.CloneWithVersion(chain.Params.HDPublicKeyID[:])
+		if err != nil {
+			return nil, err
+		}
+		origin := master + strings.TrimPrefix(formatPath(path), "m")
+
+		for branch := uint32(0); branch < 2; branch++ {
+			desc := fmt.Sprintf(t.function, fmt.Sprintf("[%s]%s/%d/*", origin, xpub, branch))
+			checksum, err := DescriptorChecksum(desc)
+			if err != nil {
+				return nil, err
+			}
+			first, err := keys.publicKey(append(path, branch, 0))
+			if err != nil {
+				return nil, err
+			}
+			address, err := keyAddress(first, t.scriptType, chain)
+			if err != nil {
+				return nil, err
+			}
+			descriptors = append(descriptors, Descriptor{
+				Chain:        chain.Name,
+				ScriptType:   t.scriptType,
+				Internal:     branch == 1,
+				Descriptor:   desc + "#" + checksum,
+				FirstAddress: address,
+			})
+		}
+	}
+	return descriptors, nil
+}
+
+// keyAddress encodes the address of a single key for a script type
+func keyAddress(pubKey *btcec.PublicKey, scriptType string, chain *Chain) (string, error) {
+	if scriptType != ScriptP2TR {
+		return scriptAddress(keyScripts(pubKey)[scriptType], chain), nil
+	}
+	outputKey := txscript.ComputeTaprootKeyNoScript(pubKey)
+	addr, err := btcutil.NewAddressTaproot(schnorr.SerializePubKey(outputKey), chain.Params)
+	if err != nil {
+		return "", err
+	}
+	return addr.EncodeAddress(), nil
+}
+
+// descriptorInputCharset and descriptorChecksumCharset are the character
+// sets of BIP380 descriptor checksums
+const (
+	descriptorInputCharset    = "0123456789()[],'/*abcdefgh@:$%{}IJKLMNOPQRSTUVWXYZ&+-.;<=>?!^_|~ijklmnopqrstuvwxyzABCDEFGH`#\"\\ "
+	descriptorChecksumCharset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+)
+
+// DescriptorChecksum computes the BIP380 checksum of a descriptor given
+// without one
+func DescriptorChecksum(desc string) (string, error) {
+	c := uint64(1)
+	class, count := 0, 0
+	for _, ch := range desc {
+		pos := strings.IndexRune(descriptorInputCharset, ch)
+		if pos < 0 {
+			return "", fmt.Errorf("invalid descriptor character %q", ch)
+		}
+		c = descriptorPolymod(c, pos&31)
+		class = class*3 + pos>>5
+		if count++; count == 3 {
+			c = descriptorPolymod(c, class)
+			class, count = 0, 0
+		}
+	}
+	if count > 0 {
+		c = descriptorPolymod(c, class)
+	}
+	for i := 0; i < 8; i++ {
+		c = descriptorPolymod(c, 0)
+	}
+	c ^= 1
+
+	var checksum [8]byte
+	for i := range checksum {
+		checksum[i] = descriptorChecksumCharset[(c>>(5*(7-i)))&31]
+	}
+	return string(checksum[:]), nil
+}
+
+func descriptorPolymod(c uint64, value int) uint64 {
+	c0 := c >> 35
+	c = (c&0x7ffffffff)<<5 ^ uint64(value)
+	for i, generator := range []uint64{0xf5dee51989, 0xa9fdca3312, 0x1bab10e32d, 0x3706b1677a, 0x644d626ffd} {
+		if c0&(1<<i) != 0 {
+			c ^= generator
+		}
+	}
+	return c
+}
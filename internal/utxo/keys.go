@@ -0,0 +1,153 @@
+package utxo
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+
+	"github.com/rowbotony/vultool/internal/vault"
+)
+
+// Script types the vault's single keys spend
+const (
+	ScriptP2WPKH     = "p2wpkh"
+	ScriptP2SHP2WPKH = "p2sh-p2wpkh"
+	ScriptP2PKH      = "p2pkh"
+)
+
+// commonIndexes is how many receive and change addresses per purpose are
+// tried when a PSBT carries no BIP32 derivation for an input
+const commonIndexes = 20
+
+// vaultKey is one derived vault key and the path it was derived along
+type vaultKey struct {
+	path   []uint32
+	pubKey *btcec.PublicKey
+}
+
+// vaultKeys derives the vault's public keys from its root key and chain code
+// Vultisig derives non-hardened from the root, so hardened markers in paths
+// are ignored for derivation but kept for display.
+type vaultKeys struct {
+	root   *hdkeychain.ExtendedKey
+	cache  map[string]*btcec.PublicKey
+	common map[*Chain][]vaultKey
+}
+
+func newVaultKeys(info *vault.VaultInfo) (*vaultKeys, error) {
+	pubKey, err := hex.DecodeString(info.PublicKeyECDSA)
+	if err != nil {
+		return nil, fmt.Errorf("vault %s has an invalid ECDSA public key", info.Name)
+	}
+	chainCode, err := hex.DecodeString(info.HexChainCode)
+	if err != nil || len(chainCode) != 32 {
+		return nil, fmt.Errorf("vault %s has an invalid chain code", info.Name)
+	}
+	if _, err := btcec.ParsePubKey(pubKey); err != nil {
+		return nil, fmt.Errorf("vault %s has an invalid ECDSA public key: %w", info.Name, err)
+	}
+
+	return &vaultKeys{
+		root:   hdkeychain.NewExtendedKey(chaincfg.MainNetParams.HDPublicKeyID[:], pubKey, chainCode, []byte{0, 0, 0, 0}, 0, 0, false),
+		cache:  make(map[string]*btcec.PublicKey),
+		common: make(map[*Chain][]vaultKey),
+	}, nil
+}
+
+// publicKey derives the vault key at path
+func (k *vaultKeys) publicKey(path []uint32) (*btcec.PublicKey, error) {
+	id := formatPath(path)
+	if pubKey, ok := k.cache[id]; ok {
+		return pubKey, nil
+	}
+
+	key, err := k.extendedKey(path)
+	if err != nil {
+		return nil, err
+	}
+	pubKey, err := key.ECPubKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive %s: %w", id, err)
+	}
+	k.cache[id] = pubKey
+	return pubKey, nil
+}
+
+// extendedKey derives the vault's extended public key at path
+func (k *vaultKeys) extendedKey(path []uint32) (*hdkeychain.ExtendedKey, error) {
+	key := k.root
+	for _, index := range path {
+		var err error
+		if key, err = key.Derive(index &^ hdkeychain.HardenedKeyStart); err != nil {
+			return nil, fmt.Errorf("failed to derive %s: %w", formatPath(path), err)
+		}
+	}
+	return key, nil
+}
+
+// commonKeys lists the receive and change keys wallets use on the chain
+func (k *vaultKeys) commonKeys(chain *Chain) ([]vaultKey, error) {
+	if keys, ok := k.common[chain]; ok {
+		return keys, nil
+	}
+	var keys []vaultKey
+	for _, purpose := range chain.Purposes {
+		for change := uint32(0); change < 2; change++ {
+			for index := uint32(0); index < commonIndexes; index++ {
+				path := []uint32{purpose + hdkeychain.HardenedKeyStart, chain.CoinType + hdkeychain.HardenedKeyStart,
+					hdkeychain.HardenedKeyStart, change, index}
+				pubKey, err := k.publicKey(path)
+				if err != nil {
+					return nil, err
+				}
+				keys = append(keys, vaultKey{path: path, pubKey: pubKey})
+			}
+		}
+	}
+	k.common[chain] = keys
+	return keys, nil
+}
+
+// keyScripts returns the output scripts a single key can be paid with
+func keyScripts(pubKey *btcec.PublicKey) map[string][]byte {
+	hash := btcutil.Hash160(pubKey.SerializeCompressed())
+	p2wpkh, _ := txscript.NewScriptBuilder().AddOp(txscript.OP_0).AddData(hash).Script()
+	p2sh, _ := txscript.NewScriptBuilder().AddOp(txscript.OP_HASH160).AddData(btcutil.Hash160(p2wpkh)).AddOp(txscript.OP_EQUAL).Script()
+	p2pkh, _ := txscript.NewScriptBuilder().AddOp(txscript.OP_DUP).AddOp(txscript.OP_HASH160).AddData(hash).
+		AddOp(txscript.OP_EQUALVERIFY).AddOp(txscript.OP_CHECKSIG).Script()
+	return map[string][]byte{ScriptP2WPKH: p2wpkh, ScriptP2SHP2WPKH: p2sh, ScriptP2PKH: p2pkh}
+}
+
+// scriptTypeFor reports how pkScript pays pubKey, or "" when it does not
+func scriptTypeFor(pkScript []byte, pubKey *btcec.PublicKey, chain *Chain) string {
+	for scriptType, script := range keyScripts(pubKey) {
+		if scriptType != ScriptP2PKH && chain.Params.Bech32HRPSegwit == "" {
+			continue
+		}
+		if bytes.Equal(script, pkScript) {
+			return scriptType
+		}
+	}
+	return ""
+}
+
+// formatPath renders a BIP32 path, marking hardened indexes with '
+func formatPath(path []uint32) string {
+	var b strings.Builder
+	b.WriteString("m")
+	for _, index := range path {
+		if index >= hdkeychain.HardenedKeyStart {
+			fmt.Fprintf(&b, "/%d'", index-hdkeychain.HardenedKeyStart)
+		} else {
+			fmt.Fprintf(&b, "/%d", index)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,277 @@
+package utxo
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+
+	"github.com/rowbotony/vultool/internal/signer"
+	"github.com/rowbotony/vultool/internal/vault"
+)
+
+// psbtMagic starts every binary PSBT
+var psbtMagic = []byte{'p', 's', 'b', 't', 0xff}
+
+// SignedInput is a PSBT input the vault signed
+type SignedInput struct {
+	Index      int    `json:"index"`
+	Address    string `json:"address"`
+	ScriptType string `json:"script_type"`
+	DerivePath string `json:"derive_path"`
+	Amount     int64  `json:"amount"`
+}
+
+// SignResult is the outcome of signing a PSBT with the vault
+type SignResult struct {
+	Chain          string        `json:"chain"`
+	Signed         []SignedInput `json:"signed"`
+	Skipped        []int         `json:"skipped,omitempty"` // inputs the vault cannot sign
+	Complete       bool          `json:"complete"`
+	PSBT           string        `json:"psbt"`
+	RawTransaction string        `json:"raw_transaction,omitempty"`
+	TxID           string        `json:"txid,omitempty"`
+}
+
+// ParsePSBT reads a PSBT given as binary, base64 or hex
+func ParsePSBT(data []byte) (*psbt.Packet, error) {
+	if !bytes.HasPrefix(data, psbtMagic) {
+		text := strings.TrimSpace(string(data))
+		if raw, err := hex.DecodeString(text); err == nil {
+			data = raw
+		} else if raw, err := base64.StdEncoding.DecodeString(text); err == nil {
+			data = raw
+		} else {
+			return nil, fmt.Errorf("PSBT is neither binary, base64 nor hex")
+		}
+	}
+	packet, err := psbt.NewFromRawBytes(bytes.NewReader(data), false)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PSBT: %w", err)
+	}
+	return packet, nil
+}
+
+// inputKey is the vault key that spends a PSBT input
+type inputKey struct {
+	vaultKey
+	scriptType string
+	prevOut    *wire.TxOut
+}
+
+// prevOutput returns the output a PSBT input spends, if the PSBT carries it
+func prevOutput(p *psbt.Packet, i int) (*wire.TxOut, error) {
+	input := p.Inputs[i]
+	if input.WitnessUtxo != nil {
+		return input.WitnessUtxo, nil
+	}
+	if input.NonWitnessUtxo == nil {
+		return nil, nil
+	}
+	outPoint := p.UnsignedTx.TxIn[i].PreviousOutPoint
+	if input.NonWitnessUtxo.TxHash() != outPoint.Hash || int(outPoint.Index) >= len(input.NonWitnessUtxo.TxOut) {
+		return nil, fmt.Errorf("input %d: previous transaction does not match the outpoint", i)
+	}
+	return input.NonWitnessUtxo.TxOut[outPoint.Index], nil
+}
+
+// matchInput finds the vault key that spends input i, first through the
+// input's BIP32 derivations and then among the chain's common paths
+func matchInput(p *psbt.Packet, i int, keys *vaultKeys, chain *Chain) (*inputKey, error) {
+	prevOut, err := prevOutput(p, i)
+	if err != nil || prevOut == nil {
+		return nil, err
+	}
+	key, scriptType, err := matchScript(prevOut.PkScript, p.Inputs[i].Bip32Derivation, keys, chain)
+	if err != nil || key == nil {
+		return nil, err
+	}
+	return &inputKey{vaultKey: *key, scriptType: scriptType, prevOut: prevOut}, nil
+}
+
+// matchScript finds the vault key pkScript pays, trying the BIP32
+// derivations the PSBT records for it before the chain's common paths
+func matchScript(pkScript []byte, derivations []*psbt.Bip32Derivation, keys *vaultKeys, chain *Chain) (*vaultKey, string, error) {
+	var candidates []vaultKey
+	for _, derivation := range derivations {
+		pubKey, err := keys.publicKey(derivation.Bip32Path)
+		if err != nil {
+			continue
+		}
+		if bytes.Equal(pubKey.SerializeCompressed(), derivation.PubKey) {
+			candidates = append(candidates, vaultKey{path: derivation.Bip32Path, pubKey: pubKey})
+		}
+	}
+	common, err := keys.commonKeys(chain)
+	if err != nil {
+		return nil, "", err
+	}
+	candidates = append(candidates, common...)
+
+	for _, candidate := range candidates {
+		if scriptType := scriptTypeFor(pkScript, candidate.pubKey, chain); scriptType != "" {
+			return &candidate, scriptType, nil
+		}
+	}
+	return nil, "", nil
+}
+
+// SignPSBT signs every input the vault's P2WPKH, P2SH-P2WPKH or P2PKH keys
+// spend, finalises them and, once every input is final, extracts the
+// transaction. Inputs of other wallets are left for their signers.
+func SignPSBT(p *psbt.Packet, chain *Chain, info *vault.VaultInfo, source signer.Source) (*SignResult, error) {
+	keys, err := newVaultKeys(info)
+	if err != nil {
+		return nil, err
+	}
+
+	// Inputs of other wallets may carry no previous output (BIP174 allows
+	// it), so they get an empty one: the BIP143 midstate only hashes the
+	// outpoints, sequences and outputs, and the cache dereferences every input
+	fetcher := txscript.NewMultiPrevOutFetcher(nil)
+	for i, txIn := range p.UnsignedTx.TxIn {
+		prevOut, err := prevOutput(p, i)
+		if err != nil || prevOut == nil {
+			prevOut = wire.NewTxOut(0, nil)
+		}
+		fetcher.AddPrevOut(txIn.PreviousOutPoint, prevOut)
+	}
+	sigHashes := txscript.NewTxSigHashes(p.UnsignedTx, fetcher)
+	updater, err := psbt.NewUpdater(p)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PSBT: %w", err)
+	}
+
+	result := &SignResult{Chain: chain.Name}
+	for i := range p.Inputs {
+		if len(p.Inputs[i].FinalScriptSig) > 0 || len(p.Inputs[i].FinalScriptWitness) > 0 {
+			continue
+		}
+		key, err := matchInput(p, i, keys, chain)
+		if err != nil {
+			return nil, err
+		}
+		if key == nil {
+			result.Skipped = append(result.Skipped, i)
+			continue
+		}
+
+		if err := signInput(updater, i, key, sigHashes, source); err != nil {
+			return nil, fmt.Errorf("input %d: %w", i, err)
+		}
+		if err := psbt.Finalize(p, i); err != nil {
+			return nil, fmt.Errorf("input %d: failed to finalise: %w", i, err)
+		}
+		result.Signed = append(result.Signed, SignedInput{
+			Index:      i,
+			Address:    scriptAddress(key.prevOut.PkScript, chain),
+			ScriptType: key.scriptType,
+			DerivePath: formatPath(key.path),
+			Amount:     key.prevOut.Value,
+		})
+	}
+	if len(result.Signed) == 0 {
+		return nil, fmt.Errorf("no input of this PSBT belongs to vault %s on %s", info.Name, chain.Name)
+	}
+
+	if result.PSBT, err = p.B64Encode(); err != nil {
+		return nil, fmt.Errorf("failed to encode PSBT: %w", err)
+	}
+	if result.Complete = p.IsComplete(); result.Complete {
+		tx, err := psbt.Extract(p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract transaction: %w", err)
+		}
+		var raw bytes.Buffer
+		if err := tx.Serialize(&raw); err != nil {
+			return nil, fmt.Errorf("failed to encode transaction: %w", err)
+		}
+		result.RawTransaction = hex.EncodeToString(raw.Bytes())
+		result.TxID = tx.TxHash().String()
+	}
+	return result, nil
+}
+
+// signInput computes the input's sighash, signs it with source and adds the
+// partial signature
+func signInput(updater *psbt.Updater, i int, key *inputKey, sigHashes *txscript.TxSigHashes, source signer.Source) error {
+	p := updater.Upsbt
+	hashType := p.Inputs[i].SighashType
+	if hashType == 0 {
+		hashType = txscript.SigHashAll
+	}
+
+	pubKey := key.pubKey.SerializeCompressed()
+	scripts := keyScripts(key.pubKey)
+	var hash, redeemScript []byte
+	var err error
+	switch key.scriptType {
+	case ScriptP2PKH:
+		hash, err = txscript.CalcSignatureHash(key.prevOut.PkScript, hashType, p.UnsignedTx, i)
+	default:
+		// BIP143 signs P2WPKH with the matching P2PKH script code
+		hash, err = txscript.CalcWitnessSigHash(scripts[ScriptP2PKH], sigHashes, hashType, p.UnsignedTx, i, key.prevOut.Value)
+		if key.scriptType == ScriptP2SHP2WPKH {
+			redeemScript = scripts[ScriptP2WPKH]
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to compute sighash: %w", err)
+	}
+
+	path := formatPath(key.path)
+	sig, err := source.SignECDSA(hash, path)
+	if err != nil {
+		return err
+	}
+	if sig.PublicKey != hex.EncodeToString(pubKey) {
+		return fmt.Errorf("the signing key is not the vault key at %s", path)
+	}
+	der, err := lowSDER(sig.R, sig.S, hash, key.pubKey)
+	if err != nil {
+		return err
+	}
+
+	outcome, err := updater.Sign(i, append(der, byte(hashType)), pubKey, redeemScript, nil)
+	if err != nil || outcome != psbt.SignSuccesful {
+		return fmt.Errorf("failed to add signature (outcome %d): %v", outcome, err)
+	}
+	return nil
+}
+
+// lowSDER encodes r and s as a canonical low-S DER signature and checks it
+func lowSDER(rHex, sHex string, hash []byte, pubKey *btcec.PublicKey) ([]byte, error) {
+	var r, s btcec.ModNScalar
+	rBytes, errR := hex.DecodeString(rHex)
+	sBytes, errS := hex.DecodeString(sHex)
+	if errR != nil || errS != nil || r.SetByteSlice(rBytes) || s.SetByteSlice(sBytes) {
+		return nil, fmt.Errorf("invalid signature values")
+	}
+	sig := ecdsa.NewSignature(&r, &s)
+	if !sig.Verify(hash, pubKey) {
+		return nil, fmt.Errorf("signature does not verify")
+	}
+	// Serialize negates a high S, as standardness rules require
+	return sig.Serialize(), nil
+}
+
+// scriptAddress renders an output script as an address on the chain, or as
+// hex when it is not a standard address
+func scriptAddress(pkScript []byte, chain *Chain) string {
+	_, addrs, _, err := txscript.ExtractPkScriptAddrs(pkScript, chain.Params)
+	if err != nil || len(addrs) != 1 {
+		return hex.EncodeToString(pkScript)
+	}
+	if pkh, ok := addrs[0].(*btcutil.AddressPubKey); ok {
+		return pkh.AddressPubKeyHash().EncodeAddress()
+	}
+	return addrs[0].EncodeAddress()
+}
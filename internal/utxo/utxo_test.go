@@ -0,0 +1,389 @@
+package utxo
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+
+	"github.com/rowbotony/vultool/internal/signer"
+	"github.com/rowbotony/vultool/internal/vault"
+)
+
+const testRootKey = "1b8254db800dcef00fd23060c813b0304430377afc65c3e34c7fd65f42f5aeab"
+
+// testVault returns a vault whose root key is testRootKey
+func testVault(t *testing.T) *vault.VaultInfo {
+	t.Helper()
+	keyBytes, _ := hex.DecodeString(testRootKey)
+	_, pub := btcec.PrivKeyFromBytes(keyBytes)
+	return &vault.VaultInfo{
+		Name:           "psbt-test",
+		PublicKeyECDSA: hex.EncodeToString(pub.SerializeCompressed()),
+		HexChainCode:   strings.Repeat("42", 32),
+	}
+}
+
+func testSource(t *testing.T, info *vault.VaultInfo) signer.Source {
+	t.Helper()
+	source, err := signer.NewPrivateKey(testRootKey, info)
+	if err != nil {
+		t.Fatalf("NewPrivateKey failed: %v", err)
+	}
+	return source
+}
+
+func hardenedPath(purpose, coin, change, index uint32) []uint32 {
+	h := uint32(hdkeychain.HardenedKeyStart)
+	return []uint32{purpose + h, coin + h, h, change, index}
+}
+
+// fundingTx pays each script 100000 satoshis
+func fundingTx(scripts ...[]byte) *wire.MsgTx {
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Hash: chainhash.Hash{1}}, nil, nil))
+	for _, script := range scripts {
+		tx.AddTxOut(wire.NewTxOut(100000, script))
+	}
+	return tx
+}
+
+// spendPSBT spends every output of funding to a single destination
+func spendPSBT(t *testing.T, funding *wire.MsgTx) *psbt.Packet {
+	t.Helper()
+	tx := wire.NewMsgTx(2)
+	for i := range funding.TxOut {
+		tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Hash: funding.TxHash(), Index: uint32(i)}, nil, nil))
+	}
+	tx.AddTxOut(wire.NewTxOut(int64(len(funding.TxOut))*100000-2000, []byte{txscript.OP_0, 20,
+		1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20}))
+	p, err := psbt.NewFromUnsignedTx(tx)
+	if err != nil {
+		t.Fatalf("NewFromUnsignedTx failed: %v", err)
+	}
+	return p
+}
+
+// verifyInputs runs the script engine over every input of a signed transaction
+func verifyInputs(t *testing.T, rawHex string, funding *wire.MsgTx) {
+	t.Helper()
+	raw, _ := hex.DecodeString(rawHex)
+	var tx wire.MsgTx
+	if err := tx.Deserialize(bytes.NewReader(raw)); err != nil {
+		t.Fatalf("Signed transaction does not decode: %v", err)
+	}
+	fetcher := txscript.NewMultiPrevOutFetcher(nil)
+	for i, out := range funding.TxOut {
+		fetcher.AddPrevOut(wire.OutPoint{Hash: funding.TxHash(), Index: uint32(i)}, out)
+	}
+	sigHashes := txscript.NewTxSigHashes(&tx, fetcher)
+	for i, txIn := range tx.TxIn {
+		prevOut := fetcher.FetchPrevOutput(txIn.PreviousOutPoint)
+		engine, err := txscript.NewEngine(prevOut.PkScript, &tx, i, txscript.StandardVerifyFlags, nil, sigHashes, prevOut.Value, fetcher)
+		if err != nil {
+			t.Fatalf("input %d: NewEngine failed: %v", i, err)
+		}
+		if err := engine.Execute(); err != nil {
+			t.Errorf("input %d: script fails: %v", i, err)
+		}
+	}
+}
+
+// TestSignPSBT_AllScriptTypes - P2WPKH found by its BIP32 derivation, P2SH-P2WPKH and P2PKH by common paths
+func TestSignPSBT_AllScriptTypes(t *testing.T) {
+	info := testVault(t)
+	keys, err := newVaultKeys(info)
+	if err != nil {
+		t.Fatalf("newVaultKeys failed: %v", err)
+	}
+	chain, _ := LookupChain("btc")
+
+	wpkhPath := hardenedPath(84, 0, 0, 7)
+	wpkhKey, _ := keys.publicKey(wpkhPath)
+	shKey, _ := keys.publicKey(hardenedPath(49, 0, 1, 2))
+	pkhKey, _ := keys.publicKey(hardenedPath(44, 0, 0, 0))
+
+	funding := fundingTx(keyScripts(wpkhKey)[ScriptP2WPKH], keyScripts(shKey)[ScriptP2SHP2WPKH], keyScripts(pkhKey)[ScriptP2PKH])
+	p := spendPSBT(t, funding)
+	p.Inputs[0].WitnessUtxo = funding.TxOut[0]
+	p.Inputs[0].Bip32Derivation = []*psbt.Bip32Derivation{{PubKey: wpkhKey.SerializeCompressed(), Bip32Path: wpkhPath}}
+	p.Inputs[1].WitnessUtxo = funding.TxOut[1]
+	p.Inputs[2].NonWitnessUtxo = funding
+
+	result, err := SignPSBT(p, chain, info, testSource(t, info))
+	if err != nil {
+		t.Fatalf("SignPSBT failed: %v", err)
+	}
+	if !result.Complete || len(result.Signed) != 3 || result.TxID == "" {
+		t.Fatalf("Unexpected result: %+v", result)
+	}
+	want := []string{"m/84'/0'/0'/0/7 p2wpkh", "m/49'/0'/0'/1/2 p2sh-p2wpkh", "m/44'/0'/0'/0/0 p2pkh"}
+	for i, input := range result.Signed {
+		if got := input.DerivePath + " " + input.ScriptType; got != want[i] {
+			t.Errorf("input %d: got %s, want %s", i, got, want[i])
+		}
+	}
+	if !strings.HasPrefix(result.Signed[0].Address, "bc1q") || !strings.HasPrefix(result.Signed[1].Address, "3") {
+		t.Errorf("Unexpected addresses: %+v", result.Signed)
+	}
+	verifyInputs(t, result.RawTransaction, funding)
+}
+
+// TestSignPSBT_ForeignInput - inputs of other wallets are left unsigned and the PSBT stays partial
+func TestSignPSBT_ForeignInput(t *testing.T) {
+	info := testVault(t)
+	keys, _ := newVaultKeys(info)
+	chain, _ := LookupChain("LTC")
+
+	ours, _ := keys.publicKey(hardenedPath(84, 2, 0, 0))
+	_, foreign := btcec.PrivKeyFromBytes(bytes.Repeat([]byte{7}, 32))
+	funding := fundingTx(keyScripts(ours)[ScriptP2WPKH], keyScripts(foreign)[ScriptP2WPKH])
+	p := spendPSBT(t, funding)
+	p.Inputs[0].WitnessUtxo = funding.TxOut[0]
+	p.Inputs[1].WitnessUtxo = funding.TxOut[1]
+
+	result, err := SignPSBT(p, chain, info, testSource(t, info))
+	if err != nil {
+		t.Fatalf("SignPSBT failed: %v", err)
+	}
+	if result.Complete || len(result.Signed) != 1 || len(result.Skipped) != 1 || result.Skipped[0] != 1 || result.RawTransaction != "" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if !strings.HasPrefix(result.Signed[0].Address, "ltc1q") {
+		t.Errorf("Expected a Litecoin address, got %s", result.Signed[0].Address)
+	}
+
+	// The partially signed PSBT round-trips with the vault input final
+	reparsed, err := ParsePSBT([]byte(result.PSBT))
+	if err != nil {
+		t.Fatalf("ParsePSBT failed: %v", err)
+	}
+	if len(reparsed.Inputs[0].FinalScriptWitness) == 0 || len(reparsed.Inputs[1].FinalScriptWitness) != 0 {
+		t.Error("Expected only the vault input to be finalised")
+	}
+
+	// BIP174 lets the other wallet leave out its previous output
+	p = spendPSBT(t, funding)
+	p.Inputs[0].WitnessUtxo = funding.TxOut[0]
+	result, err = SignPSBT(p, chain, info, testSource(t, info))
+	if err != nil {
+		t.Fatalf("SignPSBT failed without the foreign previous output: %v", err)
+	}
+	if len(result.Signed) != 1 || len(result.Skipped) != 1 || result.Skipped[0] != 1 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+}
+
+func TestSignPSBT_Errors(t *testing.T) {
+	info := testVault(t)
+	chain, _ := LookupChain("doge")
+	_, foreign := btcec.PrivKeyFromBytes(bytes.Repeat([]byte{7}, 32))
+	funding := fundingTx(keyScripts(foreign)[ScriptP2PKH])
+	p := spendPSBT(t, funding)
+	p.Inputs[0].NonWitnessUtxo = funding
+
+	if _, err := SignPSBT(p, chain, info, testSource(t, info)); err == nil || !strings.Contains(err.Error(), "no input") {
+		t.Errorf("Expected a PSBT without vault inputs to be rejected, got: %v", err)
+	}
+
+	// A key that is not the vault's cannot sign the vault's inputs
+	keys, _ := newVaultKeys(info)
+	ours, _ := keys.publicKey(hardenedPath(44, 3, 0, 0))
+	funding = fundingTx(keyScripts(ours)[ScriptP2PKH])
+	p = spendPSBT(t, funding)
+	p.Inputs[0].NonWitnessUtxo = funding
+	wrong, _ := signer.NewPrivateKey(strings.Repeat("11", 32), info)
+	if _, err := SignPSBT(p, chain, info, wrong); err == nil || !strings.Contains(err.Error(), "not the vault key") {
+		t.Errorf("Expected a foreign key to be rejected, got: %v", err)
+	}
+
+	if _, err := ParsePSBT([]byte("not a psbt")); err == nil {
+		t.Error("Expected garbage to be rejected")
+	}
+	if _, err := LookupChain("bch"); err == nil {
+		t.Error("Expected Bitcoin Cash to be unsupported")
+	}
+}
+
+// TestVaultKeys_MatchVaultAddresses - derivation agrees with the addresses list-addresses shows
+func TestVaultKeys_MatchVaultAddresses(t *testing.T) {
+	info := testVault(t)
+	keys, _ := newVaultKeys(info)
+	want := map[string][]uint32{"Bitcoin": hardenedPath(84, 0, 0, 0), "Litecoin": hardenedPath(84, 2, 0, 0)}
+
+	for _, addr := range vault.DeriveAddressesFromVault(info) {
+		path, ok := want[addr.Chain]
+		if !ok {
+			continue
+		}
+		chain, _ := LookupChain(addr.Chain)
+		pubKey, _ := keys.publicKey(path)
+		if got := scriptAddress(keyScripts(pubKey)[ScriptP2WPKH], chain); got != addr.Address {
+			t.Errorf("%s: derived %s, vault shows %s", addr.Chain, got, addr.Address)
+		}
+	}
+}
+
+// TestAnalyzePSBT - a vault spend with change, a payment out and a foreign input
+func TestAnalyzePSBT(t *testing.T) {
+	info := testVault(t)
+	keys, _ := newVaultKeys(info)
+	chain, _ := LookupChain("btc")
+
+	ours, _ := keys.publicKey(hardenedPath(84, 0, 0, 3))
+	_, foreign := btcec.PrivKeyFromBytes(bytes.Repeat([]byte{7}, 32))
+	funding := fundingTx(keyScripts(ours)[ScriptP2WPKH], keyScripts(foreign)[ScriptP2WPKH])
+	p := spendPSBT(t, funding)
+	p.Inputs[0].WitnessUtxo = funding.TxOut[0]
+	p.Inputs[1].WitnessUtxo = funding.TxOut[1]
+
+	// Change goes to a path outside the common ones, announced by its derivation
+	changePath := hardenedPath(84, 0, 1, 150)
+	change, _ := keys.publicKey(changePath)
+	p.UnsignedTx.TxOut[0].Value = 150000
+	p.UnsignedTx.AddTxOut(wire.NewTxOut(45000, keyScripts(change)[ScriptP2WPKH]))
+	p.Outputs = append(p.Outputs, psbt.POutput{Bip32Derivation: []*psbt.Bip32Derivation{{PubKey: change.SerializeCompressed(), Bip32Path: changePath}}})
+
+	a, err := AnalyzePSBT(p, chain, info)
+	if err != nil {
+		t.Fatalf("AnalyzePSBT failed: %v", err)
+	}
+	if !a.Inputs[0].Vault || a.Inputs[1].Vault || a.Inputs[0].DerivePath != "m/84'/0'/0'/0/3" {
+		t.Errorf("Unexpected inputs: %+v", a.Inputs)
+	}
+	if a.Outputs[0].Vault || !a.Outputs[1].Vault || !a.Outputs[1].Change || a.Outputs[1].DerivePath != "m/84'/0'/0'/1/150" {
+		t.Errorf("Unexpected outputs: %+v", a.Outputs)
+	}
+	if !a.FeeKnown || a.Fee != 5000 || a.NetAmount != 55000 || a.ExternalTotal != 150000 {
+		t.Errorf("Unexpected totals: fee %d, net %d, external %d", a.Fee, a.NetAmount, a.ExternalTotal)
+	}
+	if len(a.Warnings) != 1 || !strings.Contains(a.Warnings[0], "other wallets") {
+		t.Errorf("Unexpected warnings: %v", a.Warnings)
+	}
+
+	// Without the spent outputs neither ownership nor the fee can be told
+	p.Inputs[1].WitnessUtxo = nil
+	a, err = AnalyzePSBT(p, chain, info)
+	if err != nil {
+		t.Fatalf("AnalyzePSBT failed: %v", err)
+	}
+	if a.FeeKnown || !a.Inputs[1].Unknown || a.NetAmount != 55000 {
+		t.Errorf("Unexpected analysis: %+v", a)
+	}
+}
+
+// TestAccountKeys - a watch-only wallet importing the account keys derives
+// the vault's own addresses
+func TestAccountKeys(t *testing.T) {
+	info := testVault(t)
+	vaultAddresses := make(map[string]string)
+	for _, addr := range vault.DeriveAddressesFromVault(info) {
+		vaultAddresses[addr.Chain] = addr.Address
+	}
+
+	for _, tc := range []struct {
+		chain    string
+		prefixes []string
+	}{
+		{"btc", []string{"zpub", "ypub", "xpub"}},
+		{"ltc", []string{"zpub", "Mtub", "Ltub"}},
+		{"doge", []string{"dgub"}},
+	} {
+		chain, _ := LookupChain(tc.chain)
+		keys, err := AccountKeys(info, chain, 0)
+		if err != nil {
+			t.Fatalf("%s: AccountKeys failed: %v", tc.chain, err)
+		}
+		if len(keys) != len(tc.prefixes) {
+			t.Fatalf("%s: got %d keys", tc.chain, len(keys))
+		}
+		for i, key := range keys {
+			if key.Prefix != tc.prefixes[i] || key.Depth != 3 || key.MasterFingerprint == key.ParentFingerprint {
+				t.Errorf("%s: unexpected key %+v", tc.chain, key)
+			}
+			// Deriving 0/0 from the imported key gives the first address
+			imported, err := hdkeychain.NewKeyFromString(key.ExtendedKey)
+			if err != nil {
+				t.Fatalf("%s: NewKeyFromString failed: %v", key.ExtendedKey, err)
+			}
+			child, _ := imported.Derive(0)
+			child, _ = child.Derive(0)
+			pubKey, _ := child.ECPubKey()
+			if got := scriptAddress(keyScripts(pubKey)[key.ScriptType], chain); got != key.FirstAddress {
+				t.Errorf("%s %s: imported key derives %s, want %s", tc.chain, key.ScriptType, got, key.FirstAddress)
+			}
+		}
+		// The default address type's first address is the one the vault shows
+		if want := vaultAddresses[chain.Name]; keys[0].FirstAddress != want {
+			t.Errorf("%s: first address %s, vault shows %s", tc.chain, keys[0].FirstAddress, want)
+		}
+	}
+
+	btc, _ := LookupChain("btc")
+	keys, _ := AccountKeys(info, btc, 3)
+	if keys[0].Path != "m/84/0/3" || keys[0].StandardPath != "m/84'/0'/3'" {
+		t.Errorf("Unexpected paths %s and %s", keys[0].Path, keys[0].StandardPath)
+	}
+}
+
+// TestAccountDescriptors - checksums match BIP380 and each descriptor's
+// first address is the vault's key at the origin path
+func TestAccountDescriptors(t *testing.T) {
+	if checksum, _ := DescriptorChecksum("raw(deadbeef)"); checksum != "89f8spxm" {
+		t.Errorf("Unexpected checksum %s", checksum)
+	}
+	if _, err := DescriptorChecksum("raw(dé)"); err == nil {
+		t.Error("Expected an invalid character to be rejected")
+	}
+
+	info := testVault(t)
+	btc, _ := LookupChain("btc")
+	descriptors, err := AccountDescriptors(info, btc, 0)
+	if err != nil {
+		t.Fatalf("AccountDescriptors failed: %v", err)
+	}
+	if len(descriptors) != 8 {
+		t.Fatalf("Expected receive and change descriptors of 4 types, got %d", len(descriptors))
+	}
+	keys, _ := AccountKeys(info, btc, 0)
+	prefixes := map[string]string{ScriptP2WPKH: "wpkh([", ScriptP2SHP2WPKH: "sh(wpkh([", ScriptP2PKH: "pkh([", ScriptP2TR: "tr(["}
+	for _, d := range descriptors {
+		body, checksum, _ := strings.Cut(d.Descriptor, "#")
+		if want, _ := DescriptorChecksum(body); checksum != want {
+			t.Errorf("%s: checksum %s, want %s", body, checksum, want)
+		}
+		origin := "[" + keys[0].MasterFingerprint + "/"
+		if !strings.HasPrefix(d.Descriptor, prefixes[d.ScriptType]+origin[1:]) || !strings.Contains(body, "]xpub") {
+			t.Errorf("Unexpected descriptor %s", d.Descriptor)
+		}
+		branch := "/0/*)"
+		if d.Internal {
+			branch = "/1/*)"
+		}
+		if !strings.Contains(body, branch) {
+			t.Errorf("%s: expected branch %s", body, branch)
+		}
+	}
+	if descriptors[0].FirstAddress != keys[0].FirstAddress || !strings.Contains(descriptors[0].Descriptor, "/84/0/0]") {
+		t.Errorf("Unexpected wpkh descriptor %+v", descriptors[0])
+	}
+	if tr := descriptors[6]; tr.ScriptType != ScriptP2TR || !strings.HasPrefix(tr.FirstAddress, "bc1p") {
+		t.Errorf("Unexpected tr descriptor %+v", tr)
+	}
+
+	ltc, _ := LookupChain("ltc")
+	if descriptors, _ := AccountDescriptors(info, ltc, 0); !strings.HasPrefix(descriptors[6].FirstAddress, "ltc1p") {
+		t.Errorf("Unexpected Litecoin tr descriptor %+v", descriptors[6])
+	}
+	doge, _ := LookupChain("doge")
+	if _, err := AccountDescriptors(info, doge, 0); err == nil {
+		t.Error("Expected Dogecoin to be refused")
+	}
+}
@@ -0,0 +1,106 @@
+package utxo
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+
+	"github.com/rowbotony/vultool/internal/vault"
+)
+
+// scriptTypes maps BIP purposes to the address type their keys pay
+var scriptTypes = map[uint32]string{44: ScriptP2PKH, 49: ScriptP2SHP2WPKH, 84: ScriptP2WPKH}
+
+// AccountKey is an account-level extended public key a watch-only wallet
+// imports to see every receive and change address of the account
+type AccountKey struct {
+	Chain      string `json:"chain"`
+	Ticker     string `json:"ticker"`
+	ScriptType string `json:"script_type"`
+	// Path is the path as the vault derives it, every index non-hardened;
+	// StandardPath is the BIP44-style path wallets label it with
+	Path              string `json:"path"`
+	StandardPath      string `json:"standard_path"`
+	Depth             uint8  `json:"depth"`
+	MasterFingerprint string `json:"master_fingerprint"`
+	ParentFingerprint string `json:"parent_fingerprint"`
+	Fingerprint       string `json:"fingerprint"`
+	Prefix            string `json:"prefix"`
+	ExtendedKey       string `json:"extended_key"`
+	FirstAddress      string `json:"first_address"` // receive address 0, to check the import
+}
+
+// AccountKeys returns the vault's extended public keys of account for each
+// purpose of the chain, with the chain's SLIP-132 version bytes.
+// Vultisig derives every index non-hardened, so the keys are children of
+// the vault's root public key and wallets derive the vault's addresses
+// from them with plain /change/index steps.
+func AccountKeys(info *vault.VaultInfo, chain *Chain, account uint32) ([]AccountKey, error) {
+	if account >= hdkeychain.HardenedKeyStart {
+		return nil, fmt.Errorf("account %d is out of range", account)
+	}
+	keys, err := newVaultKeys(info)
+	if err != nil {
+		return nil, err
+	}
+	rootKey, err := keys.root.ECPubKey()
+	if err != nil {
+		return nil, err
+	}
+	master := btcutil.Hash160(rootKey.SerializeCompressed())[:4]
+
+	var accountKeys []AccountKey
+	for _, purpose := range chain.Purposes {
+		path := []uint32{purpose, chain.CoinType, account}
+		key, err := keys.extendedKey(path)
+		if err != nil {
+			return nil, err
+		}
+		version, ok := chain.XpubVersions[purpose]
+		if !ok {
+			return nil, fmt.Errorf("%s has no extended key version for purpose %d", chain.Name, purpose)
+		}
+		versioned, err := key.CloneWithVersion(version[:])
+		if err != nil {
+			return nil, err
+		}
+		extended := versioned.String()
+
+		pubKey, err := key.ECPubKey()
+		if err != nil {
+			return nil, err
+		}
+		first, err := keys.publicKey(append(path, 0, 0))
+		if err != nil {
+			return nil, err
+		}
+		scriptType := scriptTypes[purpose]
+
+		parent := make([]byte, 4)
+		binary.BigEndian.PutUint32(parent, key.ParentFingerprint())
+		accountKeys = append(accountKeys, AccountKey{
+			Chain:      chain.Name,
+			Ticker:     chain.Ticker,
+			ScriptType: scriptType,
+			Path:       formatPath(path),
+			StandardPath: formatPath([]uint32{purpose + hdkeychain.HardenedKeyStart,
+				chain.CoinType + hdkeychain.HardenedKeyStart, account + hdkeychain.HardenedKeyStart}),
+			Depth:             key.Depth(),
+			MasterFingerprint: hex.EncodeToString(master),
+			ParentFingerprint: hex.EncodeToString(parent),
+			Fingerprint:       hex.EncodeToString(btcutil.Hash160(pubKey.SerializeCompressed())[:4]),
+			Prefix:            extended[:4],
+			ExtendedKey:       extended,
+			FirstAddress:      scriptAddress(keyScripts(first)[scriptType], chain),
+		})
+	}
+	return accountKeys, nil
+}
+
+// Chains returns the UTXO chains vultool handles, on the selected network
+func Chains() []*Chain {
+	return networkChains()
+}
@@ -7,14 +7,15 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
 	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/btcutil/base58"
-	"github.com/btcsuite/btcd/btcutil/bech32"
 	"github.com/btcsuite/btcd/btcutil/hdkeychain"
 	btcchaincfg "github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
 	"github.com/decred/dcrd/dcrec/secp256k1/v4"
-	bchchaincfg "github.com/gcash/bchd/chaincfg"
 	"github.com/gcash/bchutil"
+	"github.com/rowbotony/vultool/internal/network"
 	"golang.org/x/crypto/blake2b"
 	"golang.org/x/crypto/ripemd160"
 	"golang.org/x/crypto/sha3"
@@ -117,16 +118,30 @@ func deriveECDSAAddresses(pubKeyHex string, chainCodeHex string) []VaultAddress
 		})
 	}
 
+	// Bitcoin - Taproot (BIP86 P2TR)
+	btcTaprootPath := "m/86'/0'/0'/0/0"
+	btcTaprootPubKey := deriveChildPublicKey(extendedPubKey, btcTaprootPath)
+	if btcTaprootPubKey != nil {
+		btcTaprootAddr := deriveBitcoinTaprootAddress(btcTaprootPubKey)
+		addresses = append(addresses, VaultAddress{
+			Chain:      "Bitcoin-Taproot",
+			Ticker:     "BTC",
+			Address:    btcTaprootAddr,
+			DerivePath: btcTaprootPath,
+		})
+	}
+
 	// Bitcoin Cash - Legacy P2PKH
 	bchPath := "m/44'/145'/0'/0/0"
 	bchPubKey := deriveChildPublicKey(extendedPubKey, bchPath)
 	if bchPubKey != nil {
 		bchAddr := deriveBitcoinCashAddress(bchPubKey)
 		addresses = append(addresses, VaultAddress{
-			Chain:      "Bitcoin-Cash",
-			Ticker:     "BCH",
-			Address:    bchAddr,
-			DerivePath: bchPath,
+			Chain:         "Bitcoin-Cash",
+			Ticker:        "BCH",
+			Address:       bchAddr,
+			LegacyAddress: deriveBitcoinCashLegacyAddress(bchPubKey),
+			DerivePath:    bchPath,
 		})
 	}
 
@@ -216,6 +231,29 @@ func deriveECDSAAddresses(pubKeyHex string, chainCodeHex string) []VaultAddress
 			Ticker:     chain.Ticker,
 			Address:    ethAddr,
 			DerivePath: evmPath,
+			ChainID:    evmChainID(chain.Chain),
+		})
+	}
+
+	// TRON - base58check of the Ethereum-style account ID
+	tronPath := "m/44'/195'/0'/0/0"
+	if tronPubKey := deriveChildPublicKey(extendedPubKey, tronPath); tronPubKey != nil {
+		addresses = append(addresses, VaultAddress{
+			Chain:      "TRON",
+			Ticker:     "TRX",
+			Address:    deriveTronAddress(tronPubKey),
+			DerivePath: tronPath,
+		})
+	}
+
+	// XRP Ledger - classic address in the Ripple base58 alphabet
+	xrpPath := "m/44'/144'/0'/0/0"
+	if xrpPubKey := deriveChildPublicKey(extendedPubKey, xrpPath); xrpPubKey != nil {
+		addresses = append(addresses, VaultAddress{
+			Chain:      "XRP",
+			Ticker:     "XRP",
+			Address:    deriveXRPAddress(xrpPubKey),
+			DerivePath: xrpPath,
 		})
 	}
 
@@ -232,6 +270,20 @@ func deriveECDSAAddresses(pubKeyHex string, chainCodeHex string) []VaultAddress
 		})
 	}
 
+	// Other Cosmos SDK chains - Bech32 with each chain's prefix and coin type
+	for _, chain := range cosmosChains {
+		pubKey := deriveChildPublicKey(extendedPubKey, chain.path())
+		if pubKey == nil {
+			continue
+		}
+		addresses = append(addresses, VaultAddress{
+			Chain:      chain.name,
+			Ticker:     chain.ticker,
+			Address:    deriveCosmosAddress(pubKey, chain.hrp),
+			DerivePath: chain.path(),
+		})
+	}
+
 	return addresses
 }
 
@@ -277,7 +329,7 @@ func deriveBitcoinSegwitAddress(pubKey *secp256k1.PublicKey) string {
 	hash160 := hash160(pubKeyCompressed)
 
 	// Create witness program (version 0 + hash160)
-	addr, err := btcutil.NewAddressWitnessPubKeyHash(hash160, &btcchaincfg.MainNetParams)
+	addr, err := btcutil.NewAddressWitnessPubKeyHash(hash160, network.BitcoinParams())
 	if err != nil {
 		return "error: " + err.Error()
 	}
@@ -285,13 +337,19 @@ func deriveBitcoinSegwitAddress(pubKey *secp256k1.PublicKey) string {
 	return addr.EncodeAddress()
 }
 
+// deriveBitcoinTaprootAddress derives a BIP86 key-path-only P2TR address:
+// the x-only key tweaked per BIP341 with no script tree, encoded as bech32m
+func deriveBitcoinTaprootAddress(pubKey *secp256k1.PublicKey) string {
+	return derivePurposeAddress(pubKey, "86", network.BitcoinParams())
+}
+
 func deriveBitcoinCashAddress(pubKey *secp256k1.PublicKey) string {
 	// Derive proper Bitcoin Cash CashAddr format using bchutil
 	pubKeyCompressed := pubKey.SerializeCompressed()
 	hash160 := hash160(pubKeyCompressed)
 
 	// Use bchutil to create a proper Bitcoin Cash address
-	addr, err := bchutil.NewAddressPubKeyHash(hash160, &bchchaincfg.MainNetParams)
+	addr, err := bchutil.NewAddressPubKeyHash(hash160, network.BitcoinCashParams())
 	if err != nil {
 		return "error: " + err.Error()
 	}
@@ -300,49 +358,66 @@ func deriveBitcoinCashAddress(pubKey *secp256k1.PublicKey) string {
 	return addr.EncodeAddress()
 }
 
+// deriveBitcoinCashLegacyAddress derives the legacy base58 P2PKH form of a
+// Bitcoin Cash address (1...), which older wallets and exchanges still use
+func deriveBitcoinCashLegacyAddress(pubKey *secp256k1.PublicKey) string {
+	return base58.CheckEncode(hash160(pubKey.SerializeCompressed()), network.BitcoinParams().PubKeyHashAddrID)
+}
+
 func deriveLitecoinSegwitAddress(pubKey *secp256k1.PublicKey) string {
 	// Litecoin SegWit uses "ltc" prefix
-	pubKeyCompressed := pubKey.SerializeCompressed()
-	hash160 := hash160(pubKeyCompressed)
-
-	// Create Litecoin parameters (copy of Bitcoin with different bech32 HRP)
-	ltcParams := btcchaincfg.MainNetParams
-	ltcParams.Bech32HRPSegwit = "ltc"
+	return derivePurposeAddress(pubKey, "84", network.LitecoinParams())
+}
 
-	addr, err := btcutil.NewAddressWitnessPubKeyHash(hash160, &ltcParams)
+// derivePurposeAddress encodes a key as the address type its BIP purpose
+// stands for: 44 P2PKH, 49 P2SH-P2WPKH, 86 P2TR and native SegWit P2WPKH
+// for 84 and anything else
+func derivePurposeAddress(pubKey *secp256k1.PublicKey, purpose string, params *btcchaincfg.Params) string {
+	keyHash := hash160(pubKey.SerializeCompressed())
+
+	var addr btcutil.Address
+	var err error
+	switch purpose {
+	case "44":
+		addr, err = btcutil.NewAddressPubKeyHash(keyHash, params)
+	case "49":
+		// The P2SH script is the P2WPKH witness program: OP_0 <20-byte key hash>
+		witnessProgram := append([]byte{0x00, 0x14}, keyHash...)
+		addr, err = btcutil.NewAddressScriptHash(witnessProgram, params)
+	case "86":
+		outputKey := txscript.ComputeTaprootKeyNoScript(pubKey)
+		addr, err = btcutil.NewAddressTaproot(schnorr.SerializePubKey(outputKey), params)
+	default:
+		addr, err = btcutil.NewAddressWitnessPubKeyHash(keyHash, params)
+	}
 	if err != nil {
 		return "error: " + err.Error()
 	}
-
-	// The btcutil library will still use "bc1" prefix, so we need to replace it
-	addrStr := addr.EncodeAddress()
-	if len(addrStr) > 3 && addrStr[:3] == "bc1" {
-		return "ltc1" + addrStr[3:]
-	}
-	return addrStr
+	return addr.EncodeAddress()
 }
 
 func deriveDogecoinAddress(pubKey *secp256k1.PublicKey) string {
-	// Dogecoin uses version byte 0x1E (30) for P2PKH
+	// Dogecoin uses version byte 0x1E (30) for P2PKH on mainnet
 	pubKeyCompressed := pubKey.SerializeCompressed()
 	hash160 := hash160(pubKeyCompressed)
-	return base58.CheckEncode(hash160, 0x1E)
+	return base58.CheckEncode(hash160, network.DogecoinParams().PubKeyHashAddrID)
 }
 
 func deriveDashAddress(pubKey *secp256k1.PublicKey) string {
-	// Dash uses version byte 0x4C (76) for P2PKH
+	// Dash uses version byte 0x4C (76) for P2PKH on mainnet
 	pubKeyCompressed := pubKey.SerializeCompressed()
 	hash160 := hash160(pubKeyCompressed)
-	return base58.CheckEncode(hash160, 0x4C)
+	return base58.CheckEncode(hash160, network.DashParams().PubKeyHashAddrID)
 }
 
 func deriveZcashAddress(pubKey *secp256k1.PublicKey) string {
-	// Zcash transparent addresses use two-byte version 0x1CB8
+	// Zcash transparent addresses use two-byte version 0x1CB8 on mainnet
 	pubKeyCompressed := pubKey.SerializeCompressed()
 	hash160 := hash160(pubKeyCompressed)
 
 	// Prepend the two-byte version
-	versionedPayload := append([]byte{0x1C, 0xB8}, hash160...)
+	version := network.ZcashAddressVersion()
+	versionedPayload := append(version[:], hash160...)
 
 	// Calculate checksum
 	checksum := sha256.Sum256(versionedPayload)
@@ -374,22 +449,41 @@ func deriveEthereumAddress(pubKey *secp256k1.PublicKey) string {
 
 func deriveThorchainAddress(pubKey *secp256k1.PublicKey) string {
 	// THORChain uses Cosmos-style bech32 addresses with "thor" prefix
-	pubKeyCompressed := pubKey.SerializeCompressed()
-	hash160 := hash160(pubKeyCompressed)
+	return deriveCosmosAddress(pubKey, "thor")
+}
 
-	// Convert to bech32 5-bit encoding
-	conv, err := bech32.ConvertBits(hash160, 8, 5, true)
+// DerivePublicKey derives the vault's ECDSA public key at derivePath the same
+// way its addresses are derived: non-hardened from the root key and chain code
+func DerivePublicKey(vaultInfo *VaultInfo, derivePath string) (*secp256k1.PublicKey, error) {
+	pubKeyBytes, err := hex.DecodeString(vaultInfo.PublicKeyECDSA)
 	if err != nil {
-		return "error: " + err.Error()
+		return nil, fmt.Errorf("invalid ECDSA public key: %w", err)
 	}
-
-	// Encode with "thor" human-readable part using proper bech32
-	addr, err := bech32.Encode("thor", conv)
+	masterPubKey, err := secp256k1.ParsePubKey(pubKeyBytes)
 	if err != nil {
-		return "error: " + err.Error()
+		return nil, fmt.Errorf("invalid ECDSA public key: %w", err)
+	}
+	chainCodeBytes, err := hex.DecodeString(vaultInfo.HexChainCode)
+	if err != nil || len(chainCodeBytes) != 32 {
+		return nil, fmt.Errorf("vault is missing a valid chain code")
 	}
 
-	return addr
+	net := &btcchaincfg.MainNetParams
+	extendedPubKey := hdkeychain.NewExtendedKey(net.HDPublicKeyID[:], masterPubKey.SerializeCompressed(),
+		chainCodeBytes, []byte{0x00, 0x00, 0x00, 0x00}, 0, 0, false)
+	pubKey := deriveChildPublicKey(extendedPubKey, derivePath)
+	if pubKey == nil {
+		return nil, fmt.Errorf("failed to derive public key at %s", derivePath)
+	}
+	return pubKey, nil
+}
+
+// evmChainID returns an EVM chain's ID on the selected network
+func evmChainID(chain string) string {
+	if id, ok := network.EVMChainID(chain); ok {
+		return id.String()
+	}
+	return ""
 }
 
 // deriveChildPublicKey derives a child public key using HD derivation path
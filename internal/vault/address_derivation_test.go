@@ -0,0 +1,291 @@
+package vault
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	btcchaincfg "github.com/btcsuite/btcd/chaincfg"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/rowbotony/vultool/internal/network"
+	"github.com/rowbotony/vultool/internal/types"
+)
+
+func testVaultInfo() *VaultInfo {
+	v := newTestVault()
+	return &VaultInfo{
+		Name:           v.Name,
+		PublicKeyECDSA: v.PublicKeyEcdsa,
+		PublicKeyEDDSA: v.PublicKeyEddsa,
+		HexChainCode:   v.HexChainCode,
+	}
+}
+
+// TestDeriveBitcoinTaprootAddress checks the BIP86 test vector for
+// m/86'/0'/0'/0/0 from its internal key
+func TestDeriveBitcoinTaprootAddress(t *testing.T) {
+	internalKey, _ := hex.DecodeString("cc8a4bc64d897bddc5fbc2f670f7a8ba0b386779106cf1223c6fc5d7cd6fc115")
+	pubKey, err := schnorr.ParsePubKey(internalKey)
+	if err != nil {
+		t.Fatalf("Failed to parse internal key: %v", err)
+	}
+
+	want := "bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr"
+	if got := deriveBitcoinTaprootAddress(pubKey); got != want {
+		t.Errorf("taproot address = %s, want %s", got, want)
+	}
+}
+
+func TestDeriveAddressAtPath(t *testing.T) {
+	info := testVaultInfo()
+	listed := make(map[string]VaultAddress)
+	for _, addr := range DeriveAddressesFromVault(info) {
+		listed[addr.Chain] = addr
+	}
+
+	// Every listed address is derived again at its own path
+	for _, tc := range []struct {
+		chain types.SupportedChain
+		name  string
+	}{
+		{types.ChainBitcoin, "Bitcoin"},
+		{types.ChainBitcoinTaproot, "Bitcoin-Taproot"},
+		{types.ChainLitecoin, "Litecoin"},
+		{types.ChainEthereum, "Ethereum"},
+		{types.ChainThorChain, "THORChain"},
+		{types.ChainTron, "TRON"},
+		{types.ChainXRP, "XRP"},
+		{types.ChainSolana, "Solana"},
+	} {
+		want := listed[tc.name]
+		got, err := DeriveAddressAtPath(info, tc.chain, want.DerivePath)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if *got != want {
+			t.Errorf("%s: derived %+v, list-addresses shows %+v", tc.name, *got, want)
+		}
+	}
+
+	// A BIP86 path on the Bitcoin chain is a taproot address too
+	got, err := DeriveAddressAtPath(info, types.ChainBitcoin, "m/86'/0'/0'/0/0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Address != listed["Bitcoin-Taproot"].Address {
+		t.Errorf("m/86'/0'/0'/0/0 = %s, want the taproot address %s", got.Address, listed["Bitcoin-Taproot"].Address)
+	}
+
+	// The vault has one Solana address, at the root EdDSA key
+	if _, err := DeriveAddressAtPath(info, types.ChainSolana, "m/44'/501'/1'/0'"); err == nil {
+		t.Error("expected a second Solana account to be refused")
+	}
+}
+
+// TestDerivePurposeAddress checks each address type against the BIP44,
+// BIP49 and BIP84 test vectors for m/purpose'/0'/0'/0/0
+func TestDerivePurposeAddress(t *testing.T) {
+	for _, tc := range []struct {
+		purpose string
+		pubKey  string
+		want    string
+	}{
+		{"44", "03aaeb52dd7494c361049de67cc680e83ebcbbbdbeb13637d92cd845f70308af5e", "1LqBGSKuX5yYUonjxT5qGfpUsXKYYWeabA"},
+		{"49", "039b3b694b8fc5b5e07fb069c783cac754f5d38c3e08bed1960e31fdb1dda35c24", "37VucYSaXLCAsxYyAPfbSi9eh4iEcbShgf"},
+		{"84", "0330d54fd0dd420a6e5f8d3624f5f3482cae350f79d5f0753bf5beef9c2d91af3c", "bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu"},
+	} {
+		keyBytes, _ := hex.DecodeString(tc.pubKey)
+		pubKey, err := secp256k1.ParsePubKey(keyBytes)
+		if err != nil {
+			t.Fatalf("purpose %s: %v", tc.purpose, err)
+		}
+		if got := derivePurposeAddress(pubKey, tc.purpose, &btcchaincfg.MainNetParams); got != tc.want {
+			t.Errorf("purpose %s: got %s, want %s", tc.purpose, got, tc.want)
+		}
+	}
+}
+
+func TestDeriveAddressAtPath_AddressTypes(t *testing.T) {
+	info := testVaultInfo()
+	for _, tc := range []struct {
+		chain  types.SupportedChain
+		path   string
+		prefix string
+	}{
+		{types.ChainBitcoin, "m/44'/0'/0'/0/0", "1"},
+		{types.ChainBitcoin, "m/49'/0'/0'/0/0", "3"},
+		{types.ChainBitcoin, "m/84'/0'/0'/0/0", "bc1q"},
+		{types.ChainBitcoin, "m/86'/0'/0'/0/0", "bc1p"},
+		{types.ChainLitecoin, "m/44'/2'/0'/0/0", "L"},
+		{types.ChainLitecoin, "m/49'/2'/0'/0/0", "M"},
+		{types.ChainLitecoin, "m/84'/2'/0'/0/0", "ltc1q"},
+	} {
+		addr, err := DeriveAddressAtPath(info, tc.chain, tc.path)
+		if err != nil {
+			t.Fatalf("%s %s: %v", tc.chain, tc.path, err)
+		}
+		if !strings.HasPrefix(addr.Address, tc.prefix) {
+			t.Errorf("%s %s: got %s, want a %s... address", tc.chain, tc.path, addr.Address, tc.prefix)
+		}
+	}
+
+	// Bitcoin Cash gives its legacy address next to the CashAddr
+	bch, err := DeriveAddressAtPath(info, types.ChainBitcoinCash, "m/44'/145'/0'/0/0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubKey, _ := DerivePublicKey(info, "m/44'/145'/0'/0/0")
+	if want := derivePurposeAddress(pubKey, "44", &btcchaincfg.MainNetParams); bch.LegacyAddress != want {
+		t.Errorf("BCH legacy address = %s, want %s", bch.LegacyAddress, want)
+	}
+}
+
+func TestDeriveAddressesFromVault_Network(t *testing.T) {
+	defer network.Set("mainnet")
+	if err := network.Set("testnet"); err != nil {
+		t.Fatal(err)
+	}
+
+	prefixes := map[string]string{
+		"Bitcoin":         "tb1q",
+		"Bitcoin-Taproot": "tb1p",
+		"Litecoin":        "tltc1q",
+		"Dogecoin":        "n",
+		"Zcash":           "tm",
+	}
+	for _, addr := range DeriveAddressesFromVault(testVaultInfo()) {
+		if prefix, ok := prefixes[addr.Chain]; ok && !strings.HasPrefix(addr.Address, prefix) {
+			t.Errorf("%s testnet address %s, want prefix %s", addr.Chain, addr.Address, prefix)
+		}
+		if addr.Chain == "Ethereum" && addr.ChainID != "11155111" {
+			t.Errorf("Ethereum testnet chain ID %s, want Sepolia", addr.ChainID)
+		}
+	}
+}
+
+// TestDeriveCosmosAddress checks the keys of the "abandon ... about" test
+// mnemonic at m/44'/118'/0'/0/0 and m/44'/330'/0'/0/0 against the addresses
+// Keplr and Terra Station give them
+func TestDeriveCosmosAddress(t *testing.T) {
+	for _, tc := range []struct {
+		pubKey string
+		hrp    string
+		want   string
+	}{
+		{"024f4e2ad99c34d60b9ba6283c9431a8418af8673212961f97a77b6377fcd05b62", "cosmos", "cosmos19rl4cm2hmr8afy4kldpxz3fka4jguq0auqdal4"},
+		{"02acb4bc267db7774614bf6011c59929b006c2554386a3090baff0b3fc418ec044", "terra", "terra1amdttz2937a3dytmxmkany53pp6ma6dy4vsllv"},
+	} {
+		pubKeyBytes, _ := hex.DecodeString(tc.pubKey)
+		pubKey, err := secp256k1.ParsePubKey(pubKeyBytes)
+		if err != nil {
+			t.Fatalf("Failed to parse public key: %v", err)
+		}
+		if got := deriveCosmosAddress(pubKey, tc.hrp); got != tc.want {
+			t.Errorf("%s address = %s, want %s", tc.hrp, got, tc.want)
+		}
+	}
+}
+
+func TestDeriveAddressesFromVault_Cosmos(t *testing.T) {
+	info := testVaultInfo()
+	listed := make(map[string]VaultAddress)
+	for _, addr := range DeriveAddressesFromVault(info) {
+		listed[addr.Chain] = addr
+	}
+
+	for _, tc := range []struct {
+		chain  types.SupportedChain
+		name   string
+		prefix string
+		path   string
+	}{
+		{types.ChainCosmos, "Cosmos", "cosmos1", "m/44'/118'/0'/0/0"},
+		{types.ChainOsmosis, "Osmosis", "osmo1", "m/44'/118'/0'/0/0"},
+		{types.ChainKujira, "Kujira", "kujira1", "m/44'/118'/0'/0/0"},
+		{types.ChainDydx, "Dydx", "dydx1", "m/44'/118'/0'/0/0"},
+		{types.ChainNoble, "Noble", "noble1", "m/44'/118'/0'/0/0"},
+		{types.ChainTerra, "Terra", "terra1", "m/44'/330'/0'/0/0"},
+		{types.ChainMayaChain, "MayaChain", "maya1", "m/44'/931'/0'/0/0"},
+	} {
+		addr, ok := listed[tc.name]
+		if !ok {
+			t.Errorf("%s missing from list-addresses", tc.name)
+			continue
+		}
+		if !strings.HasPrefix(addr.Address, tc.prefix) || addr.DerivePath != tc.path {
+			t.Errorf("%s: got %s at %s, want %s... at %s", tc.name, addr.Address, addr.DerivePath, tc.prefix, tc.path)
+		}
+		got, err := DeriveAddressAtPath(info, tc.chain, tc.path)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if *got != addr {
+			t.Errorf("%s: derived %+v, list-addresses shows %+v", tc.name, *got, addr)
+		}
+	}
+
+	// Chains sharing a coin type share the account, only the prefix differs
+	cosmos, osmosis := listed["Cosmos"].Address, listed["Osmosis"].Address
+	if strings.TrimPrefix(cosmos, "cosmos1")[:32] != strings.TrimPrefix(osmosis, "osmo1")[:32] {
+		t.Errorf("Cosmos %s and Osmosis %s should encode the same account", cosmos, osmosis)
+	}
+}
+
+// TestDeriveTronAddress checks the key of the "abandon ... about" test
+// mnemonic at m/44'/195'/0'/0/0 against the address TronLink gives it
+func TestDeriveTronAddress(t *testing.T) {
+	pubKeyBytes, _ := hex.DecodeString("03ff21f8e64d3a3c0198edfbb7afdc79be959432e92e2f8a1984bb436a414b8edc")
+	pubKey, err := secp256k1.ParsePubKey(pubKeyBytes)
+	if err != nil {
+		t.Fatalf("Failed to parse public key: %v", err)
+	}
+
+	want := "TUEZSdKsoDHQMeZwihtdoBiN46zxhGWYdH"
+	if got := deriveTronAddress(pubKey); got != want {
+		t.Errorf("TRON address = %s, want %s", got, want)
+	}
+}
+
+// TestDeriveXRPAddress checks the genesis account and the key of the
+// "abandon ... about" test mnemonic at m/44'/144'/0'/0/0
+func TestDeriveXRPAddress(t *testing.T) {
+	for _, tc := range []struct {
+		pubKey string
+		want   string
+	}{
+		{"0330e7fc9d56bb25d6893ba3f317ae5bcf33b3291bd63db32654a313222f7fd020", "rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh"},
+		{"031d68bc1a142e6766b2bdfb006ccfe135ef2e0e2e94abb5cf5c9ab6104776fbae", "rHsMGQEkVNJmpGWs8XUBoTBiAAbwxZN5v3"},
+	} {
+		pubKeyBytes, _ := hex.DecodeString(tc.pubKey)
+		pubKey, err := secp256k1.ParsePubKey(pubKeyBytes)
+		if err != nil {
+			t.Fatalf("Failed to parse public key: %v", err)
+		}
+		if got := deriveXRPAddress(pubKey); got != tc.want {
+			t.Errorf("XRP address = %s, want %s", got, tc.want)
+		}
+	}
+}
+
+func TestDerivePathAddresses(t *testing.T) {
+	info := testVaultInfo()
+	paths := map[types.SupportedChain][]types.DerivationPath{
+		types.ChainBitcoinTaproot: {{Path: "m/86'/0'/0'/0/0"}, {Path: "m/86'/0'/0'/0/1"}},
+		// Only the first Solana account is the vault's
+		types.ChainSolana: {{Path: "m/44'/501'/0'/0'"}, {Path: "m/44'/501'/1'/0'"}},
+	}
+	addresses, err := DerivePathAddresses(info, paths)
+	if err != nil {
+		t.Fatalf("DerivePathAddresses failed: %v", err)
+	}
+	if len(addresses) != 3 || addresses[0].Chain != "Bitcoin-Taproot" || addresses[2].Chain != "Solana" {
+		t.Errorf("Unexpected addresses: %+v", addresses)
+	}
+
+	// Any other failure is reported rather than leaving the address out
+	paths[types.ChainBitcoin] = []types.DerivationPath{{Path: "m/84'/0'/0'/0/x"}}
+	if _, err := DerivePathAddresses(info, paths); err == nil {
+		t.Error("Expected an invalid path to be reported")
+	}
+}
@@ -0,0 +1,52 @@
+package vault
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcutil/bech32"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// cosmosChain is a Cosmos SDK chain: its accounts are the bech32 encoding of
+// the hash160 of a secp256k1 key, under the chain's own prefix and coin type
+type cosmosChain struct {
+	name     string
+	ticker   string
+	hrp      string
+	coinType uint32
+}
+
+// cosmosChains are the Cosmos SDK chains besides THORChain, in the order
+// list-addresses derives them
+var cosmosChains = []cosmosChain{
+	{"Cosmos", "ATOM", "cosmos", 118},
+	{"Osmosis", "OSMO", "osmo", 118},
+	{"Kujira", "KUJI", "kujira", 118},
+	{"Dydx", "DYDX", "dydx", 118},
+	{"Noble", "USDC", "noble", 118},
+	{"Terra", "LUNA", "terra", 330},
+	{"MayaChain", "CACAO", "maya", 931},
+}
+
+// path returns the chain's first account path
+func (c cosmosChain) path() string {
+	return fmt.Sprintf("m/44'/%d'/0'/0/0", c.coinType)
+}
+
+// deriveCosmosAddress encodes a key as a Cosmos SDK account address under hrp
+func deriveCosmosAddress(pubKey *secp256k1.PublicKey, hrp string) string {
+	conv, err := bech32.ConvertBits(hash160(pubKey.SerializeCompressed()), 8, 5, true)
+	if err != nil {
+		return "error: " + err.Error()
+	}
+	addr, err := bech32.Encode(hrp, conv)
+	if err != nil {
+		return "error: " + err.Error()
+	}
+	return addr
+}
+
+// cosmosAddress adapts deriveCosmosAddress to a chain's prefix
+func cosmosAddress(hrp string) func(*secp256k1.PublicKey) string {
+	return func(pubKey *secp256k1.PublicKey) string { return deriveCosmosAddress(pubKey, hrp) }
+}
@@ -1,15 +1,155 @@
 package vault
 
 import (
-	"github.com/rowbotony/vultool/internal/types"
+	"encoding/hex"
+	"fmt"
+	"sort"
 	"strings"
+
+	"github.com/btcsuite/btcd/btcutil/base58"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/rowbotony/vultool/internal/network"
+	"github.com/rowbotony/vultool/internal/types"
 )
 
-// DerivePathAddresses derives addresses for specified paths
-func DerivePathAddresses(vaultInfo *VaultInfo, paths map[types.SupportedChain][]types.DerivationPath, count int) []VaultAddress {
-	// For now, just return the default addresses from DeriveAddressesFromVault
-	// This function can be enhanced later to support custom paths
-	return DeriveAddressesFromVault(vaultInfo)
+// pathChain is how list-addresses names a chain and encodes its addresses
+type pathChain struct {
+	name    string
+	ticker  string
+	address func(pubKey *secp256k1.PublicKey, derivePath string) string
+	legacy  func(pubKey *secp256k1.PublicKey) string // an older address format, if any
+}
+
+// keyOnly adapts an encoder whose address does not depend on the path
+func keyOnly(encode func(*secp256k1.PublicKey) string) func(*secp256k1.PublicKey, string) string {
+	return func(pubKey *secp256k1.PublicKey, _ string) string { return encode(pubKey) }
+}
+
+// evmAddress encodes keys the same way for every EVM chain
+var evmAddress = keyOnly(deriveEthereumAddress)
+
+// pathChains are the ECDSA chains addresses can be derived for at any path
+var pathChains = map[types.SupportedChain]pathChain{
+	types.ChainBitcoin:        {"Bitcoin", "BTC", bitcoinAddress, nil},
+	types.ChainBitcoinTaproot: {"Bitcoin-Taproot", "BTC", keyOnly(deriveBitcoinTaprootAddress), nil},
+	types.ChainBitcoinCash:    {"Bitcoin-Cash", "BCH", keyOnly(deriveBitcoinCashAddress), deriveBitcoinCashLegacyAddress},
+	types.ChainLitecoin:       {"Litecoin", "LTC", litecoinAddress, nil},
+	types.ChainDogecoin:       {"Dogecoin", "DOGE", keyOnly(deriveDogecoinAddress), nil},
+	types.ChainDash:           {"Dash", "DASH", keyOnly(deriveDashAddress), nil},
+	types.ChainZcash:          {"Zcash", "ZEC", keyOnly(deriveZcashAddress), nil},
+	types.ChainEthereum:       {"Ethereum", "ETH", evmAddress, nil},
+	types.ChainBSC:            {"BSC", "BSC", evmAddress, nil},
+	types.ChainAvalanche:      {"Avalanche", "AVAX", evmAddress, nil},
+	types.ChainPolygon:        {"Polygon", "MATIC", evmAddress, nil},
+	types.ChainCronosChain:    {"CronosChain", "CRO", evmAddress, nil},
+	types.ChainArbitrum:       {"Arbitrum", "ETH", evmAddress, nil},
+	types.ChainOptimism:       {"Optimism", "ETH", evmAddress, nil},
+	types.ChainBase:           {"Base", "ETH", evmAddress, nil},
+	types.ChainBlast:          {"Blast", "ETH", evmAddress, nil},
+	types.ChainZksync:         {"Zksync", "ETH", evmAddress, nil},
+	types.ChainThorChain:      {"THORChain", "RUNE", keyOnly(deriveThorchainAddress), nil},
+	types.ChainMayaChain:      {"MayaChain", "CACAO", keyOnly(cosmosAddress("maya")), nil},
+	types.ChainCosmos:         {"Cosmos", "ATOM", keyOnly(cosmosAddress("cosmos")), nil},
+	types.ChainOsmosis:        {"Osmosis", "OSMO", keyOnly(cosmosAddress("osmo")), nil},
+	types.ChainKujira:         {"Kujira", "KUJI", keyOnly(cosmosAddress("kujira")), nil},
+	types.ChainDydx:           {"Dydx", "DYDX", keyOnly(cosmosAddress("dydx")), nil},
+	types.ChainNoble:          {"Noble", "USDC", keyOnly(cosmosAddress("noble")), nil},
+	types.ChainTerra:          {"Terra", "LUNA", keyOnly(cosmosAddress("terra")), nil},
+	types.ChainTron:           {"TRON", "TRX", keyOnly(deriveTronAddress), nil},
+	types.ChainXRP:            {"XRP", "XRP", keyOnly(deriveXRPAddress), nil},
+}
+
+// eddsaPaths are where the EdDSA chains' addresses are. Vultisig signs with
+// the root EdDSA key itself, so each chain has exactly one address.
+var eddsaPaths = map[types.SupportedChain]string{
+	types.ChainSolana: "m/44'/501'/0'/0'",
+	types.ChainSUI:    "m/44'/784'/0'/0'/0'",
+}
+
+// bitcoinAddress encodes a Bitcoin key as the path's BIP purpose asks
+func bitcoinAddress(pubKey *secp256k1.PublicKey, derivePath string) string {
+	return derivePurposeAddress(pubKey, pathPurpose(derivePath), network.BitcoinParams())
+}
+
+// litecoinAddress encodes a Litecoin key as the path's BIP purpose asks
+func litecoinAddress(pubKey *secp256k1.PublicKey, derivePath string) string {
+	return derivePurposeAddress(pubKey, pathPurpose(derivePath), network.LitecoinParams())
+}
+
+// pathPurpose returns the BIP purpose of a path without its hardened marker
+func pathPurpose(derivePath string) string {
+	parts := strings.Split(derivePath, "/")
+	if len(parts) < 2 {
+		return ""
+	}
+	return strings.TrimRight(parts[1], "'hH")
+}
+
+// DeriveAddressAtPath derives the vault's address for chain at derivePath,
+// non-hardened from the root key the way list-addresses does
+func DeriveAddressAtPath(vaultInfo *VaultInfo, chain types.SupportedChain, derivePath string) (*VaultAddress, error) {
+	if path, ok := eddsaPaths[chain]; ok {
+		return deriveEdDSAAddressAtPath(vaultInfo, chain, path, derivePath)
+	}
+	c, ok := pathChains[chain]
+	if !ok {
+		return nil, fmt.Errorf("unsupported chain: %s", chain)
+	}
+	if vaultInfo.PublicKeyECDSA == "" {
+		return nil, fmt.Errorf("vault has no ECDSA public key")
+	}
+	pubKey, err := DerivePublicKey(vaultInfo, derivePath)
+	if err != nil {
+		return nil, err
+	}
+	address := c.address(pubKey, derivePath)
+	if strings.HasPrefix(address, "error: ") {
+		return nil, fmt.Errorf("failed to encode %s address: %s", c.name, strings.TrimPrefix(address, "error: "))
+	}
+	derived := &VaultAddress{Chain: c.name, Ticker: c.ticker, Address: address, DerivePath: derivePath, ChainID: evmChainID(c.name)}
+	if c.legacy != nil {
+		derived.LegacyAddress = c.legacy(pubKey)
+	}
+	return derived, nil
+}
+
+func deriveEdDSAAddressAtPath(vaultInfo *VaultInfo, chain types.SupportedChain, path, derivePath string) (*VaultAddress, error) {
+	if derivePath != path {
+		return nil, fmt.Errorf("%s addresses use the vault's EdDSA key at %s only", chain, path)
+	}
+	pubKeyBytes, err := hex.DecodeString(vaultInfo.PublicKeyEDDSA)
+	if err != nil || len(pubKeyBytes) == 0 {
+		return nil, fmt.Errorf("vault has no valid EdDSA public key")
+	}
+	if chain == types.ChainSUI {
+		return &VaultAddress{Chain: "SUI", Ticker: "SUI", Address: deriveSUIAddressFromEdDSA(pubKeyBytes), DerivePath: path}, nil
+	}
+	return &VaultAddress{Chain: "Solana", Ticker: "SOL", Address: base58.Encode(pubKeyBytes), DerivePath: path}, nil
+}
+
+// DerivePathAddresses derives the vault's address at each of the given
+// paths. EdDSA paths other than the chain's one address, such as further
+// Solana accounts, are left out; any other path that fails is an error.
+func DerivePathAddresses(vaultInfo *VaultInfo, paths map[types.SupportedChain][]types.DerivationPath) ([]VaultAddress, error) {
+	var addresses []VaultAddress
+	for chain, chainPaths := range paths {
+		for _, path := range chainPaths {
+			if eddsaPath, ok := eddsaPaths[chain]; ok && path.Path != eddsaPath {
+				continue
+			}
+			addr, err := DeriveAddressAtPath(vaultInfo, chain, path.Path)
+			if err != nil {
+				return nil, fmt.Errorf("%s at %s: %w", chain, path.Path, err)
+			}
+			addresses = append(addresses, *addr)
+		}
+	}
+
+	// Group by chain, keeping each chain's paths in the order given
+	sort.SliceStable(addresses, func(i, j int) bool {
+		return addresses[i].Chain < addresses[j].Chain
+	})
+	return addresses, nil
 }
 
 // getDefaultPath returns the default derivation path for a chain
@@ -18,6 +158,8 @@ func getDefaultPath(chain string) string {
 	switch strings.ToLower(chain) {
 	case "bitcoin", "btc":
 		return "m/84'/0'/0'/0/0"
+	case "bitcoin-taproot":
+		return "m/86'/0'/0'/0/0"
 	case "bitcoin-cash", "bch":
 		return "m/44'/145'/0'/0/0"
 	case "litecoin", "ltc":
@@ -28,8 +170,16 @@ func getDefaultPath(chain string) string {
 		return "m/44'/5'/0'/0/0"
 	case "ethereum", "eth":
 		return "m/44'/60'/0'/0/0"
-	case "thorchain", "rune":
+	case "thorchain", "rune", "mayachain", "maya", "cacao":
 		return "m/44'/931'/0'/0/0"
+	case "cosmos", "atom", "osmosis", "osmo", "kujira", "kuji", "dydx", "noble":
+		return "m/44'/118'/0'/0/0"
+	case "terra", "luna":
+		return "m/44'/330'/0'/0/0"
+	case "tron", "trx":
+		return "m/44'/195'/0'/0/0"
+	case "xrp", "ripple":
+		return "m/44'/144'/0'/0/0"
 	case "sui":
 		return "m/44'/784'/0'/0'/0"
 	default:
@@ -0,0 +1,20 @@
+package vault
+
+import (
+	"github.com/btcsuite/btcd/btcutil/base58"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"golang.org/x/crypto/sha3"
+)
+
+// tronAddressVersion prefixes every TRON account ID, making its base58check
+// address start with T
+const tronAddressVersion = 0x41
+
+// deriveTronAddress encodes a key as a TRON address: the Ethereum account ID,
+// the last 20 bytes of the Keccak-256 of the uncompressed key, in base58check
+// under TRON's version byte
+func deriveTronAddress(pubKey *secp256k1.PublicKey) string {
+	hash := sha3.NewLegacyKeccak256()
+	hash.Write(pubKey.SerializeUncompressed()[1:])
+	return base58.CheckEncode(hash.Sum(nil)[12:], tronAddressVersion)
+}
@@ -2,9 +2,13 @@ package vault
 
 // VaultAddress represents a blockchain address derived from vault public keys
 type VaultAddress struct {
-	Chain      string `json:"chain"`
-	Ticker     string `json:"ticker"`
-	Address    string `json:"address"`
-	DerivePath string `json:"derive_path,omitempty"`
-	IsNative   bool   `json:"is_native,omitempty"`
+	Chain   string `json:"chain"`
+	Ticker  string `json:"ticker"`
+	Address string `json:"address"`
+	// LegacyAddress is the same key in a chain's older address format,
+	// such as the base58 form of a Bitcoin Cash CashAddr
+	LegacyAddress string `json:"legacy_address,omitempty"`
+	DerivePath    string `json:"derive_path,omitempty"`
+	ChainID       string `json:"chain_id,omitempty"` // EVM chains, on the selected network
+	IsNative      bool   `json:"is_native,omitempty"`
 }
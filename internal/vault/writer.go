@@ -0,0 +1,140 @@
+package vault
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	v1 "github.com/vultisig/commondata/go/vultisig/vault/v1"
+	"golang.org/x/term"
+	"google.golang.org/protobuf/proto"
+)
+
+// EncodeVault serializes a vault into the base64 .vult container format
+// If password is non-empty the inner vault is encrypted with AES-GCM using the
+// same sha256(password) key derivation the Vultisig apps use
+func EncodeVault(vault *v1.Vault, password string) ([]byte, error) {
+	if vault == nil {
+		return nil, fmt.Errorf("vault is nil")
+	}
+
+	vaultData, err := proto.Marshal(vault)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling vault: %w", err)
+	}
+
+	container := &v1.VaultContainer{
+		Version: 1,
+	}
+
+	if password != "" {
+		hasher := sha256.New()
+		hasher.Write([]byte(password))
+		key := hasher.Sum(nil)
+
+		encrypted, encErr := encryptAES(vaultData, key)
+		if encErr != nil {
+			return nil, fmt.Errorf("error encrypting vault: %w", encErr)
+		}
+		container.Vault = encrypted
+		container.IsEncrypted = true
+	} else {
+		container.Vault = base64.StdEncoding.EncodeToString(vaultData)
+	}
+
+	containerData, err := proto.Marshal(container)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling vault container: %w", err)
+	}
+
+	return []byte(base64.StdEncoding.EncodeToString(containerData)), nil
+}
+
+// WriteVaultFile encodes a vault and writes it to filePath
+// Existing files are never replaced unless force is set
+func WriteVaultFile(filePath string, vault *v1.Vault, password string, force bool) error {
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return fmt.Errorf("error getting absolute path: %w", err)
+	}
+
+	if validateErr := ValidateSafeOutputPath(absPath); validateErr != nil {
+		return fmt.Errorf("unsafe output path: %w", validateErr)
+	}
+
+	if !force {
+		if _, statErr := os.Stat(absPath); statErr == nil {
+			return fmt.Errorf("file %s already exists (use --force to overwrite)", absPath)
+		}
+	}
+
+	content, err := EncodeVault(vault, password)
+	if err != nil {
+		return err
+	}
+
+	// #nosec G306 - vault shares are secret material, keep them owner-only
+	if err := os.WriteFile(absPath, content, 0o600); err != nil {
+		return fmt.Errorf("error writing vault file: %w", err)
+	}
+
+	return nil
+}
+
+// PromptNewPassword asks for a new vault password twice on the terminal
+func PromptNewPassword() (string, error) {
+	fmt.Print("Enter password for the new vault files: ")
+	first, err := term.ReadPassword(int(syscall.Stdin))
+	fmt.Println()
+	if err != nil {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+
+	fmt.Print("Confirm password: ")
+	second, err := term.ReadPassword(int(syscall.Stdin))
+	fmt.Println()
+	if err != nil {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+
+	if string(first) != string(second) {
+		return "", fmt.Errorf("passwords do not match")
+	}
+	if len(first) == 0 {
+		return "", fmt.Errorf("password must not be empty")
+	}
+	return string(first), nil
+}
+
+// ShareFileName returns the conventional file name for one party's share,
+// e.g. "MyVault-part1of3.vult"
+func ShareFileName(vaultName string, index, total int) string {
+	return fmt.Sprintf("%s-part%dof%d.vult", vaultName, index, total)
+}
+
+// encryptAES encrypts data using AES-GCM, prepending the nonce to the ciphertext
+func encryptAES(plaintext []byte, key []byte) (string, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to create AES cipher: %w", err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", fmt.Errorf("failed to create GCM: %w", err)
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	ciphertext := gcm.Seal(nonce, nonce, plaintext, nil)
+	return base64.StdEncoding.EncodeToString(ciphertext), nil
+}
@@ -0,0 +1,94 @@
+package vault
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	v1 "github.com/vultisig/commondata/go/vultisig/vault/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func newTestVault() *v1.Vault {
+	return &v1.Vault{
+		Name:           "writer-test",
+		PublicKeyEcdsa: "02a1633cafcc01ebfb6d78e39f687a1f0995c62fc95f51ead10a02ee0be551b5dc",
+		PublicKeyEddsa: "3b6a27bcceb6a42d62a3a8d02a6f0d73653215771de243a63ac048a18b59da29",
+		Signers:        []string{"party-a", "party-b"},
+		CreatedAt:      timestamppb.Now(),
+		HexChainCode:   "873dff81c02f525623fd1fe5167eac3a55a049de3d314bb42ee227ffed37d508",
+		KeyShares: []*v1.Vault_KeyShare{
+			{PublicKey: "02a1633cafcc01ebfb6d78e39f687a1f0995c62fc95f51ead10a02ee0be551b5dc", Keyshare: "{}"},
+			{PublicKey: "3b6a27bcceb6a42d62a3a8d02a6f0d73653215771de243a63ac048a18b59da29", Keyshare: "{}"},
+		},
+		LocalPartyId: "party-a",
+	}
+}
+
+func TestWriteVaultFile_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ShareFileName("writer-test", 1, 2))
+
+	if err := WriteVaultFile(path, newTestVault(), "", false); err != nil {
+		t.Fatalf("Failed to write vault: %v", err)
+	}
+
+	vaultInfo, err := ParseVaultFile(path)
+	if err != nil {
+		t.Fatalf("Failed to parse written vault: %v", err)
+	}
+	if vaultInfo.Name != "writer-test" {
+		t.Errorf("Expected name writer-test, got %s", vaultInfo.Name)
+	}
+	if vaultInfo.IsEncrypted {
+		t.Error("Vault should not be encrypted")
+	}
+	if len(vaultInfo.KeyShares) != 2 || vaultInfo.KeyShares[1].KeyType != "EDDSA" {
+		t.Errorf("Unexpected key shares: %+v", vaultInfo.KeyShares)
+	}
+	if issues := ValidateVault(vaultInfo); len(issues) > 0 {
+		t.Errorf("Written vault failed validation: %v", issues)
+	}
+}
+
+func TestWriteVaultFile_Encrypted(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "encrypted.vult")
+
+	if err := WriteVaultFile(path, newTestVault(), "correct horse", false); err != nil {
+		t.Fatalf("Failed to write encrypted vault: %v", err)
+	}
+
+	vaultInfo, err := ParseVaultFileWithPassword(path, "correct horse")
+	if err != nil {
+		t.Fatalf("Failed to parse encrypted vault: %v", err)
+	}
+	if !vaultInfo.IsEncrypted {
+		t.Error("Vault should be encrypted")
+	}
+	if vaultInfo.LocalPartyKey != "party-a" {
+		t.Errorf("Expected local party party-a, got %s", vaultInfo.LocalPartyKey)
+	}
+
+	if _, err := ParseVaultFileWithPassword(path, "wrong"); err == nil {
+		t.Error("Expected error with wrong password")
+	}
+}
+
+func TestWriteVaultFile_NoOverwrite(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "existing.vult")
+
+	if err := WriteVaultFile(path, newTestVault(), "", false); err != nil {
+		t.Fatalf("Failed to write vault: %v", err)
+	}
+
+	err := WriteVaultFile(path, newTestVault(), "", false)
+	if err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Expected 'already exists' error, got: %v", err)
+	}
+
+	if err := WriteVaultFile(path, newTestVault(), "", true); err != nil {
+		t.Errorf("Expected overwrite with force to succeed, got: %v", err)
+	}
+}
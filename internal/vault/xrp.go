@@ -0,0 +1,34 @@
+package vault
+
+import (
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil/base58"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+const (
+	// bitcoinAlphabet and rippleAlphabet are the base58 digits in value
+	// order; the XRP Ledger's base58 only orders them differently
+	bitcoinAlphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+	rippleAlphabet  = "rpshnaf39wBUDNEGHJKLM4PQRST7VWXYZ2bcdeCg65jkm8oFqi1tuvAxyz"
+
+	// xrpAccountIDVersion prefixes every classic address, making it start
+	// with r
+	xrpAccountIDVersion = 0x00
+)
+
+var toRippleAlphabet = func() *strings.Replacer {
+	var pairs []string
+	for i := range bitcoinAlphabet {
+		pairs = append(pairs, bitcoinAlphabet[i:i+1], rippleAlphabet[i:i+1])
+	}
+	return strings.NewReplacer(pairs...)
+}()
+
+// deriveXRPAddress encodes a key as an XRP Ledger classic address: the
+// account ID, RIPEMD-160 of SHA-256 of the compressed key, in base58check
+// with the Ripple alphabet
+func deriveXRPAddress(pubKey *secp256k1.PublicKey) string {
+	return toRippleAlphabet.Replace(base58.CheckEncode(hash160(pubKey.SerializeCompressed()), xrpAccountIDVersion))
+}